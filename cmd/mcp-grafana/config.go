@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the format of the YAML file passed with --config. Every
+// setting is optional; flags and environment variables take precedence over
+// the values in the file.
+type fileConfig struct {
+	Transport    string   `yaml:"transport"`
+	Address      string   `yaml:"address"`
+	LogLevel     string   `yaml:"logLevel"`
+	EnableTools  []string `yaml:"enableTools"`
+	DisableTools []string `yaml:"disableTools"`
+	ReadOnly     bool     `yaml:"readOnly"`
+	// AllowInsecureTLSHeader lets requests skip verifying Grafana's
+	// certificate with the X-Grafana-TLS-Skip-Verify header.
+	AllowInsecureTLSHeader bool `yaml:"allowInsecureTLSHeader"`
+	// MaxResponseBytes is a pointer so that 0, which disables truncation,
+	// can be told apart from unset.
+	MaxResponseBytes *int `yaml:"maxResponseBytes"`
+	// MaxConcurrentRequests is the number of requests tools fanning out to
+	// many requests make at once.
+	MaxConcurrentRequests int `yaml:"maxConcurrentRequests"`
+	// MaxConcurrentToolCalls is the number of tool calls the server runs at
+	// once, or 0 for no limit.
+	MaxConcurrentToolCalls int `yaml:"maxConcurrentToolCalls"`
+
+	Grafana struct {
+		URL      string `yaml:"url"`
+		APIKey   string `yaml:"apiKey"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+		TenantID string `yaml:"tenantId"`
+		OrgID    int64  `yaml:"orgId"`
+		Timeout  string `yaml:"timeout"`
+		// DatasourceCacheTTL is how long datasource lookups are cached,
+		// e.g. "30s". "0s" disables the cache.
+		DatasourceCacheTTL string `yaml:"datasourceCacheTTL"`
+		// DefaultTimeRange is how far back tools look when the start of a
+		// time range isn't given, e.g. "1h".
+		DefaultTimeRange string `yaml:"defaultTimeRange"`
+		// ProxyHeaders are extra headers sent with every request to a
+		// datasource, e.g. for header-based routing or quotas.
+		ProxyHeaders      map[string]string `yaml:"proxyHeaders"`
+		ProxyHeaderPrefix string            `yaml:"proxyHeaderPrefix"`
+		// PromDirectURL lists the UIDs of the Prometheus datasources to
+		// query at their own URL rather than through Grafana, or "*" for
+		// all of them.
+		PromDirectURL []string `yaml:"promDirectURL"`
+		TLS           struct {
+			CAFile     string `yaml:"caFile"`
+			CertFile   string `yaml:"certFile"`
+			KeyFile    string `yaml:"keyFile"`
+			SkipVerify bool   `yaml:"skipVerify"`
+		} `yaml:"tls"`
+	} `yaml:"grafana"`
+}
+
+// loadConfig reads and parses the config file at path.
+func loadConfig(path string) (*fileConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	defer f.Close()
+
+	var cfg fileConfig
+	dec := yaml.NewDecoder(f)
+	// Catch typos in setting names rather than silently ignoring them.
+	dec.KnownFields(true)
+	// An empty file is valid, and configures nothing.
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// applyFlags sets each flag which wasn't given on the command line to its
+// value from the config file, if there is one.
+func (c *fileConfig) applyFlags(fs *flag.FlagSet) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	// -t is shorthand for --transport.
+	if set["t"] {
+		set["transport"] = true
+	}
+
+	values := map[string]string{
+		"transport":     c.Transport,
+		"sse-address":   c.Address,
+		"log-level":     c.LogLevel,
+		"enable-tools":  strings.Join(c.EnableTools, ","),
+		"disable-tools": strings.Join(c.DisableTools, ","),
+	}
+	if c.ReadOnly {
+		values["read-only"] = "true"
+	}
+	if c.AllowInsecureTLSHeader {
+		values["allow-insecure-tls-header"] = "true"
+	}
+	for name, value := range values {
+		if value == "" || set[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("setting %s from config file: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyEnv sets each environment variable which isn't already set to its
+// value from the config file, if there is one.
+func (c *fileConfig) applyEnv() error {
+	values := map[string]string{
+		"GRAFANA_URL":                  c.Grafana.URL,
+		"GRAFANA_API_KEY":              c.Grafana.APIKey,
+		"GRAFANA_USERNAME":             c.Grafana.Username,
+		"GRAFANA_PASSWORD":             c.Grafana.Password,
+		"GRAFANA_TENANT_ID":            c.Grafana.TenantID,
+		"GRAFANA_TIMEOUT":              c.Grafana.Timeout,
+		"GRAFANA_DATASOURCE_CACHE_TTL": c.Grafana.DatasourceCacheTTL,
+		"GRAFANA_DEFAULT_TIME_RANGE":   c.Grafana.DefaultTimeRange,
+		"GRAFANA_PROXY_HEADER_PREFIX":  c.Grafana.ProxyHeaderPrefix,
+		"GRAFANA_PROM_DIRECT_URL":      strings.Join(c.Grafana.PromDirectURL, ","),
+		"GRAFANA_TLS_CA_FILE":          c.Grafana.TLS.CAFile,
+		"GRAFANA_TLS_CERT_FILE":        c.Grafana.TLS.CertFile,
+		"GRAFANA_TLS_KEY_FILE":         c.Grafana.TLS.KeyFile,
+	}
+	if c.MaxResponseBytes != nil {
+		values["GRAFANA_MAX_RESPONSE_BYTES"] = strconv.Itoa(*c.MaxResponseBytes)
+	}
+	if c.MaxConcurrentRequests != 0 {
+		values["GRAFANA_MAX_CONCURRENT_REQUESTS"] = strconv.Itoa(c.MaxConcurrentRequests)
+	}
+	if c.MaxConcurrentToolCalls != 0 {
+		values["GRAFANA_MAX_CONCURRENT_TOOL_CALLS"] = strconv.Itoa(c.MaxConcurrentToolCalls)
+	}
+	if c.Grafana.OrgID != 0 {
+		values["GRAFANA_ORG_ID"] = strconv.FormatInt(c.Grafana.OrgID, 10)
+	}
+	if len(c.Grafana.ProxyHeaders) > 0 {
+		pairs := make([]string, 0, len(c.Grafana.ProxyHeaders))
+		for name, value := range c.Grafana.ProxyHeaders {
+			pairs = append(pairs, name+"="+value)
+		}
+		sort.Strings(pairs)
+		values["GRAFANA_PROXY_HEADERS"] = strings.Join(pairs, ",")
+	}
+	if c.Grafana.TLS.SkipVerify {
+		values["GRAFANA_TLS_SKIP_VERIFY"] = strconv.FormatBool(true)
+	}
+	for name, value := range values {
+		if value == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("setting %s from config file: %w", name, err)
+		}
+	}
+	return nil
+}
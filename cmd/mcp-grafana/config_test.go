@@ -0,0 +1,126 @@
+//go:build unit
+// +build unit
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("full config", func(t *testing.T) {
+		cfg, err := loadConfig(writeConfig(t, `
+transport: sse
+address: localhost:9000
+logLevel: debug
+enableTools: [prometheus, loki]
+readOnly: true
+grafana:
+  url: https://grafana.example.com
+  tenantId: tenant-1
+  tls:
+    skipVerify: true
+`))
+		require.NoError(t, err)
+		assert.Equal(t, "sse", cfg.Transport)
+		assert.Equal(t, "localhost:9000", cfg.Address)
+		assert.Equal(t, []string{"prometheus", "loki"}, cfg.EnableTools)
+		assert.True(t, cfg.ReadOnly)
+		assert.Equal(t, "https://grafana.example.com", cfg.Grafana.URL)
+		assert.True(t, cfg.Grafana.TLS.SkipVerify)
+	})
+
+	t.Run("empty config", func(t *testing.T) {
+		cfg, err := loadConfig(writeConfig(t, ""))
+		require.NoError(t, err)
+		assert.Equal(t, &fileConfig{}, cfg)
+	})
+
+	t.Run("unknown setting", func(t *testing.T) {
+		_, err := loadConfig(writeConfig(t, "trasport: sse\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+	})
+}
+
+func TestFileConfigApplyFlags(t *testing.T) {
+	newFlagSet := func() (*flag.FlagSet, *string, *string, *bool) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var transport string
+		fs.StringVar(&transport, "t", "stdio", "")
+		fs.StringVar(&transport, "transport", "stdio", "")
+		logLevel := fs.String("log-level", "info", "")
+		fs.String("sse-address", "localhost:8000", "")
+		fs.String("enable-tools", "", "")
+		fs.String("disable-tools", "", "")
+		readOnly := fs.Bool("read-only", false, "")
+		return fs, &transport, logLevel, readOnly
+	}
+	cfg := &fileConfig{Transport: "sse", LogLevel: "debug", ReadOnly: true}
+
+	t.Run("file values are used", func(t *testing.T) {
+		fs, transport, logLevel, readOnly := newFlagSet()
+		require.NoError(t, fs.Parse(nil))
+		require.NoError(t, cfg.applyFlags(fs))
+		assert.Equal(t, "sse", *transport)
+		assert.Equal(t, "debug", *logLevel)
+		assert.True(t, *readOnly)
+	})
+
+	t.Run("flags override file values", func(t *testing.T) {
+		fs, transport, logLevel, _ := newFlagSet()
+		require.NoError(t, fs.Parse([]string{"-t", "stdio", "--log-level", "warn"}))
+		require.NoError(t, cfg.applyFlags(fs))
+		assert.Equal(t, "stdio", *transport)
+		assert.Equal(t, "warn", *logLevel)
+	})
+}
+
+func TestFileConfigApplyEnv(t *testing.T) {
+	cfg := &fileConfig{}
+	cfg.Grafana.URL = "https://from-file.example.com"
+	cfg.Grafana.APIKey = "from-file"
+
+	t.Setenv("GRAFANA_URL", "")
+	os.Unsetenv("GRAFANA_URL")
+	t.Setenv("GRAFANA_API_KEY", "from-env")
+	require.NoError(t, cfg.applyEnv())
+	assert.Equal(t, "https://from-file.example.com", os.Getenv("GRAFANA_URL"))
+	assert.Equal(t, "from-env", os.Getenv("GRAFANA_API_KEY"))
+}
+
+func TestFileConfigApplyEnvProxyHeaders(t *testing.T) {
+	cfg := &fileConfig{}
+	cfg.Grafana.ProxyHeaders = map[string]string{"X-Route": "eu", "X-Query-Tags": "source=mcp"}
+
+	t.Setenv("GRAFANA_PROXY_HEADERS", "")
+	os.Unsetenv("GRAFANA_PROXY_HEADERS")
+	require.NoError(t, cfg.applyEnv())
+	assert.Equal(t, "X-Query-Tags=source=mcp,X-Route=eu", os.Getenv("GRAFANA_PROXY_HEADERS"))
+}
+
+func TestFileConfigApplyEnvPromDirectURL(t *testing.T) {
+	cfg := &fileConfig{}
+	cfg.Grafana.PromDirectURL = []string{"prometheus", "mimir"}
+
+	t.Setenv("GRAFANA_PROM_DIRECT_URL", "")
+	os.Unsetenv("GRAFANA_PROM_DIRECT_URL")
+	require.NoError(t, cfg.applyEnv())
+	assert.Equal(t, "prometheus,mimir", os.Getenv("GRAFANA_PROM_DIRECT_URL"))
+}
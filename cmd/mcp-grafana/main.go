@@ -2,79 +2,241 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
 	mcpgrafana "github.com/grafana/mcp-grafana"
 	"github.com/grafana/mcp-grafana/tools"
 )
 
-func newServer() *server.MCPServer {
+// shutdownGracePeriod is how long in-flight requests are given to finish when
+// the server is shutting down.
+const shutdownGracePeriod = 10 * time.Second
+
+func newServer(filter mcpgrafana.ToolFilter) *server.MCPServer {
 	s := server.NewMCPServer(
 		"mcp-grafana",
 		"0.1.0",
 		// server.WithLogging(),
 	)
-	tools.AddSearchTools(s)
-	tools.AddDatasourceTools(s)
-	tools.AddIncidentTools(s)
-	tools.AddPrometheusTools(s)
-	tools.AddLokiTools(s)
-	tools.AddAlertingTools(s)
-	tools.AddDashboardTools(s)
-	tools.AddOnCallTools(s)
+	tools.AddSearchTools(s, filter)
+	tools.AddDatasourceTools(s, filter)
+	tools.AddIncidentTools(s, filter)
+	tools.AddPrometheusTools(s, filter)
+	tools.AddLokiTools(s, filter)
+	tools.AddTempoTools(s, filter)
+	tools.AddPyroscopeTools(s, filter)
+	tools.AddAlertingTools(s, filter)
+	tools.AddDashboardTools(s, filter)
+	tools.AddFolderTools(s, filter)
+	tools.AddOnCallTools(s, filter)
+	tools.AddAdminTools(s, filter)
+	tools.AddAnnotationTools(s, filter)
+	tools.AddPlaylistTools(s, filter)
 	return s
 }
 
-func run(transport, addr string, logLevel slog.Level) error {
+// stdioContextFunc returns the context function for the stdio transport,
+// which marks requests as read-only if readOnly is true.
+func stdioContextFunc(readOnly bool) server.StdioContextFunc {
+	return mcpgrafana.ComposeStdioContextFuncs(
+		func(ctx context.Context) context.Context {
+			return mcpgrafana.WithReadOnly(ctx, readOnly)
+		},
+		mcpgrafana.ComposedStdioContextFunc,
+	)
+}
+
+// sseContextFunc returns the context function for the SSE and streamable HTTP
+// transports, which marks requests as read-only if readOnly is true, and lets
+// them skip verifying Grafana's certificate with
+// mcpgrafana.TLSSkipVerifyHeader if allowInsecureTLSHeader is true.
+func sseContextFunc(readOnly, allowInsecureTLSHeader bool) server.SSEContextFunc {
+	return mcpgrafana.ComposeSSEContextFuncs(
+		func(ctx context.Context, _ *http.Request) context.Context {
+			ctx = mcpgrafana.WithReadOnly(ctx, readOnly)
+			return mcpgrafana.WithAllowInsecureTLSHeader(ctx, allowInsecureTLSHeader)
+		},
+		mcpgrafana.ComposedSSEContextFunc,
+	)
+}
+
+func run(transport, addr string, logLevel slog.Level, filter mcpgrafana.ToolFilter, readOnly, allowInsecureTLSHeader bool) error {
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
-	s := newServer()
+	shutdownTracing, err := mcpgrafana.InitTracing(context.Background())
+	if err != nil {
+		return fmt.Errorf("initializing tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to shut down tracing", "error", err)
+		}
+	}()
+	s := newServer(filter)
+
+	// Stop the server on SIGINT or SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	switch transport {
 	case "stdio":
 		srv := server.NewStdioServer(s)
-		srv.SetContextFunc(mcpgrafana.ComposedStdioContextFunc)
+		srv.SetContextFunc(stdioContextFunc(readOnly))
 		slog.Info("Starting Grafana MCP server using stdio transport")
-		return srv.Listen(context.Background(), os.Stdin, os.Stdout)
+		if err := srv.Listen(ctx, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+		slog.Info("Grafana MCP server stopped")
 	case "sse":
+		httpSrv := &http.Server{Addr: addr}
 		srv := server.NewSSEServer(s,
-			server.WithSSEContextFunc(mcpgrafana.ComposedSSEContextFunc),
+			server.WithSSEContextFunc(sseContextFunc(readOnly, allowInsecureTLSHeader)),
+			server.WithHTTPServer(httpSrv),
 		)
+		httpSrv.Handler = srv
 		slog.Info("Starting Grafana MCP server using SSE transport", "address", addr)
-		if err := srv.Start(addr); err != nil {
-			return fmt.Errorf("Server error: %v", err)
-		}
+		return serveHTTP(ctx, httpSrv, srv.Shutdown)
+	case "streamable-http":
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", &streamableHTTPHandler{
+			server:      s,
+			contextFunc: sseContextFunc(readOnly, allowInsecureTLSHeader),
+		})
+		httpSrv := &http.Server{Addr: addr, Handler: mux}
+		slog.Info("Starting Grafana MCP server using streamable HTTP transport", "address", addr, "endpoint", "/mcp")
+		return serveHTTP(ctx, httpSrv, httpSrv.Shutdown)
 	default:
 		return fmt.Errorf(
-			"Invalid transport type: %s. Must be 'stdio' or 'sse'",
+			"Invalid transport type: %s. Must be 'stdio', 'sse' or 'streamable-http'",
 			transport,
 		)
 	}
 	return nil
 }
 
+// serveHTTP serves HTTP requests with srv until ctx is cancelled, and then
+// calls shutdown to stop it. In-flight requests are given shutdownGracePeriod
+// to finish, after which their contexts are cancelled.
+func serveHTTP(ctx context.Context, srv *http.Server, shutdown func(context.Context) error) error {
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	srv.BaseContext = func(net.Listener) context.Context { return baseCtx }
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("Server error: %v", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down Grafana MCP server", "grace_period", shutdownGracePeriod)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		slog.Warn("In-flight requests did not finish in time, cancelling them", "error", err)
+		cancelBase()
+		_ = srv.Close()
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("Server error: %v", err)
+	}
+	slog.Info("Grafana MCP server stopped")
+	return nil
+}
+
 func main() {
 	var transport string
-	flag.StringVar(&transport, "t", "stdio", "Transport type (stdio or sse)")
+	flag.StringVar(&transport, "t", "stdio", "Transport type (stdio, sse or streamable-http)")
 	flag.StringVar(
 		&transport,
 		"transport",
 		"stdio",
-		"Transport type (stdio or sse)",
+		"Transport type (stdio, sse or streamable-http)",
 	)
-	addr := flag.String("sse-address", "localhost:8000", "The host and port to start the sse server on")
+	addr := flag.String("sse-address", "localhost:8000", "The host and port to start the sse or streamable-http server on")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	enableTools := flag.String("enable-tools", "", "Comma-separated list of tools or tool categories to enable. If set, all other tools are disabled")
+	disableTools := flag.String("disable-tools", "", "Comma-separated list of tools or tool categories to disable")
+	readOnly := flag.Bool("read-only", false, "Only register tools which don't create, update or delete resources, and only query datasources which can't change data")
+	allowInsecureTLSHeader := flag.Bool("allow-insecure-tls-header", false, "Let SSE and streamable HTTP requests skip verifying Grafana's certificate by setting the X-Grafana-TLS-Skip-Verify header to true")
+	configPath := flag.String("config", "", "Path to a YAML config file. Flags and environment variables override its values")
+	listTools := flag.Bool("list-tools", false, "Print the name, description and input schema of each enabled tool as JSON, then exit")
 	flag.Parse()
 
-	if err := run(transport, *addr, parseLevel(*logLevel)); err != nil {
+	if *configPath != "" {
+		cfg, err := loadConfig(*configPath)
+		if err != nil {
+			panic(err)
+		}
+		if err := cfg.applyFlags(flag.CommandLine); err != nil {
+			panic(err)
+		}
+		if err := cfg.applyEnv(); err != nil {
+			panic(err)
+		}
+	}
+
+	filter := mcpgrafana.NewToolFilter(splitList(*enableTools), splitList(*disableTools))
+	if *readOnly {
+		filter = filter.ReadOnly()
+	}
+	if *listTools {
+		if err := printTools(os.Stdout, newServer(filter)); err != nil {
+			panic(err)
+		}
+		return
+	}
+	if err := run(transport, *addr, parseLevel(*logLevel), filter, *readOnly, *allowInsecureTLSHeader); err != nil {
 		panic(err)
 	}
 }
 
+// printTools writes the tools registered with the server to w as a JSON
+// array, in the same form as a tools/list response.
+func printTools(w io.Writer, s *server.MCPServer) error {
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		return fmt.Errorf("listing tools: unexpected response %v", response)
+	}
+	result, ok := resp.Result.(mcp.ListToolsResult)
+	if !ok {
+		return fmt.Errorf("listing tools: unexpected result %v", resp.Result)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result.Tools)
+}
+
+// splitList splits a comma-separated list, ignoring empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func parseLevel(level string) slog.Level {
 	var l slog.Level
 	if err := l.UnmarshalText([]byte(level)); err != nil {
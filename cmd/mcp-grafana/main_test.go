@@ -0,0 +1,48 @@
+//go:build unit
+// +build unit
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintTools(t *testing.T) {
+	listTools := func(t *testing.T, filter mcpgrafana.ToolFilter) map[string]map[string]any {
+		var buf bytes.Buffer
+		require.NoError(t, printTools(&buf, newServer(filter)))
+		var tools []map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &tools))
+		byName := map[string]map[string]any{}
+		for _, tool := range tools {
+			byName[tool["name"].(string)] = tool
+		}
+		return byName
+	}
+
+	t.Run("all tools", func(t *testing.T) {
+		tools := listTools(t, nil)
+		require.Contains(t, tools, "query_prometheus")
+		assert.NotEmpty(t, tools["query_prometheus"]["description"])
+		assert.NotEmpty(t, tools["query_prometheus"]["inputSchema"])
+		assert.Contains(t, tools, "post_dashboard")
+	})
+
+	t.Run("filtered tools", func(t *testing.T) {
+		tools := listTools(t, mcpgrafana.NewToolFilter([]string{"prometheus"}, nil))
+		assert.Contains(t, tools, "query_prometheus")
+		assert.NotContains(t, tools, "query_loki_logs")
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		tools := listTools(t, mcpgrafana.ToolFilter(nil).ReadOnly())
+		assert.Contains(t, tools, "get_dashboard_by_uid")
+		assert.NotContains(t, tools, "post_dashboard")
+	})
+}
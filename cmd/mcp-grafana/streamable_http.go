@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxStreamableHTTPRequestSize is the largest request body accepted by the
+// streamable HTTP transport.
+const maxStreamableHTTPRequestSize = 10 * 1024 * 1024
+
+// streamableHTTPHandler serves the MCP Streamable HTTP transport in its
+// stateless form: each POST contains one JSON-RPC message or a batch of them,
+// and the responses are returned directly as JSON. The server never initiates
+// messages, so GET requests for a server-sent event stream aren't supported.
+//
+// The version of mcp-go we use doesn't include a streamable HTTP server yet;
+// this can be replaced by it once we upgrade.
+type streamableHTTPHandler struct {
+	server      *server.MCPServer
+	contextFunc server.SSEContextFunc
+}
+
+func (h *streamableHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxStreamableHTTPRequestSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if h.contextFunc != nil {
+		ctx = h.contextFunc(ctx, r)
+	}
+
+	// A request is either a single message or a batch of messages.
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+	var messages []json.RawMessage
+	if batch {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			writeJSON(w, http.StatusBadRequest, mcp.JSONRPCError{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				Error: struct {
+					Code    int         `json:"code"`
+					Message string      `json:"message"`
+					Data    interface{} `json:"data,omitempty"`
+				}{Code: mcp.PARSE_ERROR, Message: "Failed to parse batch"},
+			})
+			return
+		}
+	} else {
+		messages = []json.RawMessage{body}
+	}
+
+	responses := []mcp.JSONRPCMessage{}
+	for _, message := range messages {
+		if response := h.server.HandleMessage(ctx, message); response != nil {
+			responses = append(responses, response)
+		}
+	}
+
+	switch {
+	case len(responses) == 0:
+		// Only notifications or responses were sent.
+		w.WriteHeader(http.StatusAccepted)
+	case batch:
+		writeJSON(w, http.StatusOK, responses)
+	default:
+		writeJSON(w, http.StatusOK, responses[0])
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build unit
+// +build unit
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamableHTTPHandler(t *testing.T) {
+	h := &streamableHTTPHandler{server: newServer(nil)}
+
+	t.Run("ping", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, rec.Body.String())
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"ping","params":{"padding":"` + strings.Repeat("x", maxStreamableHTTPRequestSize) + `"}}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
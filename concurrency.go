@@ -0,0 +1,63 @@
+package mcpgrafana
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/sync/semaphore"
+)
+
+// toolCallQueueTimeout is how long a tool call waits for a slot when the
+// limit on concurrent tool calls is reached, before it is rejected.
+var toolCallQueueTimeout = 10 * time.Second
+
+// toolCallSemaphores holds a semaphore for each configured limit on
+// concurrent tool calls, shared by all tools.
+var toolCallSemaphores sync.Map // map[int]*semaphore.Weighted
+
+func toolCallSemaphore(n int) *semaphore.Weighted {
+	if sem, ok := toolCallSemaphores.Load(n); ok {
+		return sem.(*semaphore.Weighted)
+	}
+	sem, _ := toolCallSemaphores.LoadOrStore(n, semaphore.NewWeighted(int64(n)))
+	return sem.(*semaphore.Weighted)
+}
+
+// limitedToolHandler wraps a tool handler so that at most the number of tool
+// calls in the context's limit run at once across the server. Calls beyond
+// the limit are queued for up to toolCallQueueTimeout, then rejected with a
+// "server busy" error result, so that the model can retry later. This bounds
+// the total work in flight, unlike the limit on concurrent requests, which
+// only applies to requests made by a single fanning-out tool call. Without a
+// limit the handler is called directly.
+func limitedToolHandler(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		n := MaxConcurrentToolCallsFromContext(ctx)
+		if n <= 0 {
+			return handler(ctx, request)
+		}
+		sem := toolCallSemaphore(n)
+		if !sem.TryAcquire(1) {
+			queueCtx, cancel := context.WithTimeout(ctx, toolCallQueueTimeout)
+			err := sem.Acquire(queueCtx, 1)
+			cancel()
+			if err != nil {
+				// The caller gave up, so there's no one to tell the
+				// server is busy.
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{mcp.NewTextContent(fmt.Sprintf("server busy: %d tool calls are already running, try again later", n))},
+					IsError: true,
+				}, nil
+			}
+		}
+		defer sem.Release(1)
+		return handler(ctx, request)
+	}
+}
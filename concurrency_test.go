@@ -0,0 +1,61 @@
+//go:build unit
+// +build unit
+
+package mcpgrafana
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitedToolHandler(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		var calls atomic.Int32
+		handler := limitedToolHandler(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls.Add(1)
+			return mcp.NewToolResultText("ok"), nil
+		})
+		result, err := handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("busy", func(t *testing.T) {
+		defer func(d time.Duration) { toolCallQueueTimeout = d }(toolCallQueueTimeout)
+		toolCallQueueTimeout = 50 * time.Millisecond
+
+		ctx := WithMaxConcurrentToolCalls(context.Background(), 2)
+		release := make(chan struct{})
+		started := make(chan struct{}, 2)
+		handler := limitedToolHandler(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			started <- struct{}{}
+			<-release
+			return mcp.NewToolResultText("ok"), nil
+		})
+		for range 2 {
+			go func() { _, _ = handler(ctx, mcp.CallToolRequest{}) }()
+		}
+		for range 2 {
+			<-started
+		}
+
+		result, err := handler(ctx, mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "server busy: 2 tool calls are already running, try again later", result.Content[0].(mcp.TextContent).Text)
+
+		// Once a call finishes, queued calls run.
+		toolCallQueueTimeout = 5 * time.Second
+		close(release)
+		result, err = handler(ctx, mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+	})
+}
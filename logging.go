@@ -0,0 +1,102 @@
+package mcpgrafana
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sensitiveArgumentNames are substrings of argument names whose values are
+// redacted before tool arguments are logged.
+var sensitiveArgumentNames = []string{"password", "secret", "token", "apikey", "api_key", "authorization", "credential"}
+
+type requestIDKey struct{}
+
+// WithRequestID adds the ID of the current tool call to the context.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext extracts the ID of the current tool call from the
+// context, returning an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	// crypto/rand.Read never returns an error.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sanitizeArguments returns a copy of the tool arguments with the values of
+// any sensitive-looking keys redacted, so that they're safe to log.
+func sanitizeArguments(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	sanitized := make(map[string]any, len(args))
+	for k, v := range args {
+		sanitized[k] = sanitizeArgument(k, v)
+	}
+	return sanitized
+}
+
+func sanitizeArgument(key string, value any) any {
+	lower := strings.ToLower(key)
+	for _, name := range sensitiveArgumentNames {
+		if strings.Contains(lower, name) {
+			return "REDACTED"
+		}
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		return sanitizeArguments(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = sanitizeArgument("", item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// loggedToolHandler wraps a tool handler so that each call is logged at debug
+// level with a unique request ID. The request ID is added to the context and
+// to any error returned, so that errors seen by users can be matched up with
+// the logs.
+func loggedToolHandler(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		requestID := newRequestID()
+		ctx = WithRequestID(ctx, requestID)
+		logger := slog.With("tool", name, "request_id", requestID)
+		logger.Debug("Calling tool", "arguments", sanitizeArguments(request.Params.Arguments))
+
+		start := time.Now()
+		result, err := handler(ctx, request)
+		duration := time.Since(start)
+		switch {
+		case err != nil:
+			logger.Debug("Tool call failed", "duration", duration, "error", err)
+			return nil, fmt.Errorf("%w (request ID: %s)", err, requestID)
+		case result != nil && result.IsError:
+			logger.Debug("Tool call returned an error result", "duration", duration)
+		default:
+			logger.Debug("Tool call succeeded", "duration", duration)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,82 @@
+//go:build unit
+// +build unit
+
+package mcpgrafana
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeArguments(t *testing.T) {
+	args := map[string]any{
+		"query":    "up",
+		"password": "hunter2",
+		"nested": map[string]any{
+			"apiKey": "secret-key",
+			"limit":  10,
+		},
+		"items": []any{
+			map[string]any{"Authorization": "Bearer token"},
+		},
+	}
+	assert.Equal(t, map[string]any{
+		"query":    "up",
+		"password": "REDACTED",
+		"nested": map[string]any{
+			"apiKey": "REDACTED",
+			"limit":  10,
+		},
+		"items": []any{
+			map[string]any{"Authorization": "REDACTED"},
+		},
+	}, sanitizeArguments(args))
+	// The original arguments aren't modified.
+	assert.Equal(t, "hunter2", args["password"])
+}
+
+func TestLoggedToolHandler(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var requestID string
+	handler := loggedToolHandler("test_tool", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		requestID = RequestIDFromContext(ctx)
+		if request.Params.Arguments["name"] == "error" {
+			return nil, assert.AnError
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	t.Run("successful call", func(t *testing.T) {
+		logs.Reset()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"name": "ok", "token": "my-secret-token"}
+		_, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		require.NotEmpty(t, requestID)
+		assert.Contains(t, logs.String(), "request_id="+requestID)
+		assert.Contains(t, logs.String(), "tool=test_tool")
+		assert.Contains(t, logs.String(), "Tool call succeeded")
+		assert.NotContains(t, logs.String(), "my-secret-token")
+	})
+
+	t.Run("failed call", func(t *testing.T) {
+		logs.Reset()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"name": "error"}
+		_, err := handler(context.Background(), request)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Contains(t, err.Error(), requestID)
+		assert.Contains(t, logs.String(), "Tool call failed")
+	})
+}
@@ -2,57 +2,465 @@ package mcpgrafana
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/incident-go"
 	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 const (
 	defaultGrafanaHost = "localhost:3000"
 	defaultGrafanaURL  = "http://" + defaultGrafanaHost
 
-	grafanaURLEnvVar = "GRAFANA_URL"
-	grafanaAPIEnvVar = "GRAFANA_API_KEY"
+	// DefaultTimeout is the default timeout for requests made to Grafana and
+	// the datasources behind it.
+	DefaultTimeout = 30 * time.Second
 
-	grafanaURLHeader    = "X-Grafana-URL"
-	grafanaAPIKeyHeader = "X-Grafana-API-Key"
+	// DefaultMaxResponseBytes is the default size above which tool results
+	// are truncated, so that they don't overflow the client's context window.
+	DefaultMaxResponseBytes = 100 * 1024
+
+	// DefaultDatasourceCacheTTL is the default time for which datasource
+	// lookups are cached.
+	DefaultDatasourceCacheTTL = 30 * time.Second
+
+	// DefaultTimeRange is the default lookback used by tools when the start
+	// of a time range isn't given.
+	DefaultTimeRange = time.Hour
+
+	// DefaultMaxConcurrentRequests is the default number of requests which
+	// tools fanning out to many requests, such as fetching several
+	// dashboards, make at once.
+	DefaultMaxConcurrentRequests = 8
+
+	// DefaultProxyHeaderPrefix is the default prefix of request headers which
+	// are passed on to datasources, without the prefix.
+	DefaultProxyHeaderPrefix = "X-Grafana-Proxy-"
+
+	// PromDirectURLAll, given in GRAFANA_PROM_DIRECT_URL, queries every
+	// Prometheus datasource at its own URL rather than through Grafana.
+	PromDirectURLAll = "*"
+
+	grafanaURLEnvVar      = "GRAFANA_URL"
+	grafanaAPIEnvVar      = "GRAFANA_API_KEY"
+	grafanaUsernameEnvVar = "GRAFANA_USERNAME"
+	grafanaPasswordEnvVar = "GRAFANA_PASSWORD"
+	grafanaTenantIDEnvVar = "GRAFANA_TENANT_ID"
+	grafanaTimeoutEnvVar  = "GRAFANA_TIMEOUT"
+	grafanaOrgIDEnvVar    = "GRAFANA_ORG_ID"
+
+	maxResponseBytesEnvVar       = "GRAFANA_MAX_RESPONSE_BYTES"
+	datasourceCacheTTLEnvVar     = "GRAFANA_DATASOURCE_CACHE_TTL"
+	defaultTimeRangeEnvVar       = "GRAFANA_DEFAULT_TIME_RANGE"
+	proxyHeadersEnvVar           = "GRAFANA_PROXY_HEADERS"
+	proxyHeaderPrefixEnvVar      = "GRAFANA_PROXY_HEADER_PREFIX"
+	maxConcurrentRequestsEnvVar  = "GRAFANA_MAX_CONCURRENT_REQUESTS"
+	promDirectURLEnvVar          = "GRAFANA_PROM_DIRECT_URL"
+	maxConcurrentToolCallsEnvVar = "GRAFANA_MAX_CONCURRENT_TOOL_CALLS"
+
+	grafanaTLSCAFileEnvVar     = "GRAFANA_TLS_CA_FILE"
+	grafanaTLSCertFileEnvVar   = "GRAFANA_TLS_CERT_FILE"
+	grafanaTLSKeyFileEnvVar    = "GRAFANA_TLS_KEY_FILE"
+	grafanaTLSSkipVerifyEnvVar = "GRAFANA_TLS_SKIP_VERIFY"
+
+	grafanaURLHeader      = "X-Grafana-URL"
+	grafanaAPIKeyHeader   = "X-Grafana-API-Key"
+	grafanaUsernameHeader = "X-Grafana-Username"
+	grafanaPasswordHeader = "X-Grafana-Password"
+	grafanaTenantIDHeader = "X-Scope-OrgID"
+	grafanaOrgIDHeader    = "X-Grafana-Org-Id"
+
+	// TLSSkipVerifyHeader is the request header which, set to "true", skips
+	// verifying Grafana's certificate for that request. It is ignored unless
+	// the server allows it with WithAllowInsecureTLSHeader.
+	TLSSkipVerifyHeader = "X-Grafana-TLS-Skip-Verify"
 )
 
+// urlAndAPIKeyFromEnv returns the Grafana URL and API key from the
+// environment. The URL defaults to defaultGrafanaURL and never has a trailing
+// slash.
 func urlAndAPIKeyFromEnv() (string, string) {
 	u := strings.TrimRight(os.Getenv(grafanaURLEnvVar), "/")
+	if u == "" {
+		u = defaultGrafanaURL
+	}
 	apiKey := os.Getenv(grafanaAPIEnvVar)
 	return u, apiKey
 }
 
+// urlAndAPIKeyFromHeaders returns the Grafana URL and API key from the request
+// headers, falling back to the environment for either if its header isn't
+// set.
 func urlAndAPIKeyFromHeaders(req *http.Request) (string, string) {
-	u := req.Header.Get(grafanaURLHeader)
-	apiKey := req.Header.Get(grafanaAPIKeyHeader)
+	u, apiKey := urlAndAPIKeyFromEnv()
+	if h := strings.TrimRight(req.Header.Get(grafanaURLHeader), "/"); h != "" {
+		u = h
+	}
+	if h := req.Header.Get(grafanaAPIKeyHeader); h != "" {
+		apiKey = h
+	}
 	return u, apiKey
 }
 
+// incidentAPIURL returns the URL of the Grafana Incident API for a Grafana URL.
+func incidentAPIURL(grafanaURL string) string {
+	return fmt.Sprintf("%s/api/plugins/grafana-incident-app/resources/api/v1/", strings.TrimRight(grafanaURL, "/"))
+}
+
+// basicAuthFromEnv returns the basic auth credentials from the environment,
+// or nil if no username is set.
+func basicAuthFromEnv() *url.Userinfo {
+	username := os.Getenv(grafanaUsernameEnvVar)
+	if username == "" {
+		return nil
+	}
+	return url.UserPassword(username, os.Getenv(grafanaPasswordEnvVar))
+}
+
+// basicAuthFromHeaders returns the basic auth credentials from the request
+// headers, falling back to the environment if no username header is set.
+func basicAuthFromHeaders(req *http.Request) *url.Userinfo {
+	username := req.Header.Get(grafanaUsernameHeader)
+	if username == "" {
+		return basicAuthFromEnv()
+	}
+	return url.UserPassword(username, req.Header.Get(grafanaPasswordHeader))
+}
+
+// tenantIDFromHeaders returns the tenant ID from the request headers, falling
+// back to the environment if the header isn't set.
+func tenantIDFromHeaders(req *http.Request) string {
+	if tenantID := req.Header.Get(grafanaTenantIDHeader); tenantID != "" {
+		return tenantID
+	}
+	return os.Getenv(grafanaTenantIDEnvVar)
+}
+
+// timeoutFromEnv returns the request timeout from the environment, or
+// DefaultTimeout if it is unset or invalid.
+func timeoutFromEnv() time.Duration {
+	v := os.Getenv(grafanaTimeoutEnvVar)
+	if v == "" {
+		return DefaultTimeout
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil || timeout <= 0 {
+		slog.Warn("Invalid timeout, using the default", "env_var", grafanaTimeoutEnvVar, "value", v, "default", DefaultTimeout)
+		return DefaultTimeout
+	}
+	return timeout
+}
+
+// tlsConfigFromEnv builds the TLS configuration for connections to Grafana
+// from the environment. It returns nil if no TLS options are set, in which
+// case the system roots are used and certificates are verified as usual.
+func tlsConfigFromEnv() (*tls.Config, error) {
+	caFile := os.Getenv(grafanaTLSCAFileEnvVar)
+	certFile := os.Getenv(grafanaTLSCertFileEnvVar)
+	keyFile := os.Getenv(grafanaTLSKeyFileEnvVar)
+	skipVerify := false
+	if v := os.Getenv(grafanaTLSSkipVerifyEnvVar); v != "" {
+		var err error
+		if skipVerify, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", grafanaTLSSkipVerifyEnvVar, v, err)
+		}
+	}
+	if caFile == "" && certFile == "" && keyFile == "" && !skipVerify {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: skipVerify}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", grafanaTLSCAFileEnvVar, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s %s", grafanaTLSCAFileEnvVar, caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("%s and %s must be set together", grafanaTLSCertFileEnvVar, grafanaTLSKeyFileEnvVar)
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// grafanaTLSConfig loads the TLS configuration from the environment once, so
+// that the files aren't read again for every request.
+var grafanaTLSConfig = sync.OnceValues(tlsConfigFromEnv)
+
+// grafanaInsecureTLSConfig is grafanaTLSConfig without certificate
+// verification, for requests with TLSSkipVerifyHeader. It is only created
+// once so that transports, which are cached by TLS configuration, are reused.
+var grafanaInsecureTLSConfig = sync.OnceValues(func() (*tls.Config, error) {
+	cfg, err := grafanaTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	cfg = cfg.Clone()
+	cfg.InsecureSkipVerify = true
+	return cfg, nil
+})
+
+// tlsConfigFromHeaders returns the TLS configuration for a request: the one
+// from the environment, without certificate verification if the request sets
+// TLSSkipVerifyHeader to true and the server allows it.
+func tlsConfigFromHeaders(ctx context.Context, req *http.Request) (*tls.Config, error) {
+	skipVerify, _ := strconv.ParseBool(req.Header.Get(TLSSkipVerifyHeader))
+	if !skipVerify {
+		return grafanaTLSConfig()
+	}
+	if !AllowInsecureTLSHeaderFromContext(ctx) {
+		slog.Warn("Ignoring header since the server doesn't allow it, start it with --allow-insecure-tls-header to do so", "header", TLSSkipVerifyHeader)
+		return grafanaTLSConfig()
+	}
+	return grafanaInsecureTLSConfig()
+}
+
+// maxResponseBytesFromEnv returns the response size budget from the
+// environment, or DefaultMaxResponseBytes if it is unset or invalid. A value
+// of 0 disables truncation.
+func maxResponseBytesFromEnv() int {
+	v := os.Getenv(maxResponseBytesEnvVar)
+	if v == "" {
+		return DefaultMaxResponseBytes
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		slog.Warn("Invalid response size budget, using the default", "env_var", maxResponseBytesEnvVar, "value", v, "default", DefaultMaxResponseBytes)
+		return DefaultMaxResponseBytes
+	}
+	return n
+}
+
+// datasourceCacheTTLFromEnv returns the time for which datasource lookups are
+// cached from the environment, or DefaultDatasourceCacheTTL if it is unset or
+// invalid. A value of 0 disables the cache.
+func datasourceCacheTTLFromEnv() time.Duration {
+	v := os.Getenv(datasourceCacheTTLEnvVar)
+	if v == "" {
+		return DefaultDatasourceCacheTTL
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl < 0 {
+		slog.Warn("Invalid datasource cache TTL, using the default", "env_var", datasourceCacheTTLEnvVar, "value", v, "default", DefaultDatasourceCacheTTL)
+		return DefaultDatasourceCacheTTL
+	}
+	return ttl
+}
+
+// defaultTimeRangeFromEnv returns the default lookback for tools from the
+// environment, or DefaultTimeRange if it is unset or invalid.
+func defaultTimeRangeFromEnv() time.Duration {
+	v := os.Getenv(defaultTimeRangeEnvVar)
+	if v == "" {
+		return DefaultTimeRange
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		slog.Warn("Invalid default time range, using the default", "env_var", defaultTimeRangeEnvVar, "value", v, "default", DefaultTimeRange)
+		return DefaultTimeRange
+	}
+	return d
+}
+
+// maxConcurrentRequestsFromEnv returns the number of requests fan-out tools
+// make at once from the environment, or DefaultMaxConcurrentRequests if it is
+// unset or invalid.
+func maxConcurrentRequestsFromEnv() int {
+	v := os.Getenv(maxConcurrentRequestsEnvVar)
+	if v == "" {
+		return DefaultMaxConcurrentRequests
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		slog.Warn("Invalid maximum number of concurrent requests, using the default", "env_var", maxConcurrentRequestsEnvVar, "value", v, "default", DefaultMaxConcurrentRequests)
+		return DefaultMaxConcurrentRequests
+	}
+	return n
+}
+
+// maxConcurrentToolCallsFromEnv returns the limit on tool calls in flight at
+// once from the environment, or 0, meaning no limit, if it is unset or
+// invalid.
+func maxConcurrentToolCallsFromEnv() int {
+	v := os.Getenv(maxConcurrentToolCallsEnvVar)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		slog.Warn("Invalid maximum number of concurrent tool calls, not limiting them", "env_var", maxConcurrentToolCallsEnvVar, "value", v)
+		return 0
+	}
+	return n
+}
+
+// parseProxyHeaders parses a comma-separated list of Name=value pairs.
+// Invalid pairs are logged and skipped.
+func parseProxyHeaders(s string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			slog.Warn("Ignoring invalid proxy header, expected Name=value", "env_var", proxyHeadersEnvVar, "value", pair)
+			continue
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// proxyHeadersFromEnv returns the extra headers to send to datasources from
+// the environment, or an empty map if there are none.
+func proxyHeadersFromEnv() map[string]string {
+	return parseProxyHeaders(os.Getenv(proxyHeadersEnvVar))
+}
+
+// proxyHeadersFromHeaders returns the extra headers to send to datasources
+// from the environment, overridden by any request headers with the configured
+// prefix, which is removed. For example, with the default prefix, the
+// X-Grafana-Proxy-X-Query-Tags header is sent to datasources as X-Query-Tags.
+func proxyHeadersFromHeaders(req *http.Request) map[string]string {
+	headers := proxyHeadersFromEnv()
+	prefix := os.Getenv(proxyHeaderPrefixEnvVar)
+	if prefix == "" {
+		prefix = DefaultProxyHeaderPrefix
+	}
+	for name, values := range req.Header {
+		if len(name) <= len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) {
+			continue
+		}
+		headers[http.CanonicalHeaderKey(name[len(prefix):])] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// promDirectURLFromEnv returns the UIDs of the Prometheus datasources to
+// query at their own URL from the environment, which may include
+// PromDirectURLAll, or nil if there are none.
+func promDirectURLFromEnv() []string {
+	var uids []string
+	for _, uid := range strings.Split(os.Getenv(promDirectURLEnvVar), ",") {
+		if uid = strings.TrimSpace(uid); uid != "" {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// parseOrgID parses a Grafana org ID, returning 0 (the default org) if it is
+// empty.
+func parseOrgID(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	orgID, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || orgID < 0 {
+		return 0, fmt.Errorf("invalid org ID %q: must be a positive integer", s)
+	}
+	return orgID, nil
+}
+
+// orgIDFromEnv returns the Grafana org ID from the environment, or 0 if it
+// isn't set.
+func orgIDFromEnv() (int64, error) {
+	return parseOrgID(os.Getenv(grafanaOrgIDEnvVar))
+}
+
+// orgIDFromHeaders returns the Grafana org ID from the request headers,
+// falling back to the environment if the header isn't set.
+func orgIDFromHeaders(req *http.Request) (int64, error) {
+	if h := req.Header.Get(grafanaOrgIDHeader); h != "" {
+		return parseOrgID(h)
+	}
+	return orgIDFromEnv()
+}
+
 type grafanaURLKey struct{}
 type grafanaAPIKeyKey struct{}
+type grafanaBasicAuthKey struct{}
+type grafanaTenantIDKey struct{}
+type grafanaTimeoutKey struct{}
+type grafanaTLSConfigKey struct{}
+type grafanaOrgIDKey struct{}
+type maxResponseBytesKey struct{}
+type datasourceCacheTTLKey struct{}
+type defaultTimeRangeKey struct{}
+type proxyHeadersKey struct{}
+type maxConcurrentRequestsKey struct{}
+type promDirectURLKey struct{}
+type maxConcurrentToolCallsKey struct{}
+type allowInsecureTLSHeaderKey struct{}
+type readOnlyKey struct{}
 
 // ExtractGrafanaInfoFromEnv is a StdioContextFunc that extracts Grafana configuration
 // from environment variables and injects a configured client into the context.
 var ExtractGrafanaInfoFromEnv server.StdioContextFunc = func(ctx context.Context) context.Context {
 	u, apiKey := urlAndAPIKeyFromEnv()
-	if u == "" {
-		u = defaultGrafanaURL
-	}
 	parsedURL, err := url.Parse(u)
 	if err != nil {
 		panic(fmt.Errorf("invalid Grafana URL %s: %w", u, err))
 	}
-	slog.Info("Using Grafana configuration", "url", parsedURL.Redacted(), "api_key_set", apiKey != "")
+	basicAuth := basicAuthFromEnv()
+	tenantID := os.Getenv(grafanaTenantIDEnvVar)
+	timeout := timeoutFromEnv()
+	slog.Info("Using Grafana configuration", "url", parsedURL.Redacted(), "api_key_set", apiKey != "", "basic_auth_set", basicAuth != nil, "tenant_id", tenantID, "timeout", timeout)
+	ctx = WithGrafanaBasicAuth(ctx, basicAuth)
+	ctx = WithGrafanaTenantID(ctx, tenantID)
+	ctx = WithGrafanaTimeout(ctx, timeout)
+	tlsConfig, err := grafanaTLSConfig()
+	if err != nil {
+		panic(fmt.Errorf("invalid TLS configuration: %w", err))
+	}
+	ctx = WithGrafanaTLSConfig(ctx, tlsConfig)
+	orgID, err := orgIDFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	ctx = WithGrafanaOrgID(ctx, orgID)
+	ctx = WithMaxResponseBytes(ctx, maxResponseBytesFromEnv())
+	ctx = WithDatasourceCacheTTL(ctx, datasourceCacheTTLFromEnv())
+	ctx = WithDefaultTimeRange(ctx, defaultTimeRangeFromEnv())
+	ctx = WithMaxConcurrentRequests(ctx, maxConcurrentRequestsFromEnv())
+	ctx = WithProxyHeaders(ctx, proxyHeadersFromEnv())
+	ctx = WithPromDirectURL(ctx, promDirectURLFromEnv())
+	ctx = WithMaxConcurrentToolCalls(ctx, maxConcurrentToolCallsFromEnv())
 	return WithGrafanaURL(WithGrafanaAPIKey(ctx, apiKey), u)
 }
 
@@ -60,16 +468,26 @@ var ExtractGrafanaInfoFromEnv server.StdioContextFunc = func(ctx context.Context
 // from request headers and injects a configured client into the context.
 var ExtractGrafanaInfoFromHeaders server.SSEContextFunc = func(ctx context.Context, req *http.Request) context.Context {
 	u, apiKey := urlAndAPIKeyFromHeaders(req)
-	uEnv, apiKeyEnv := urlAndAPIKeyFromEnv()
-	if u == "" {
-		u = uEnv
-	}
-	if u == "" {
-		u = defaultGrafanaURL
+	ctx = WithGrafanaBasicAuth(ctx, basicAuthFromHeaders(req))
+	ctx = WithGrafanaTenantID(ctx, tenantIDFromHeaders(req))
+	ctx = WithGrafanaTimeout(ctx, timeoutFromEnv())
+	if tlsConfig, err := tlsConfigFromHeaders(ctx, req); err != nil {
+		slog.Error("Invalid TLS configuration", "error", err)
+	} else {
+		ctx = WithGrafanaTLSConfig(ctx, tlsConfig)
 	}
-	if apiKey == "" {
-		apiKey = apiKeyEnv
+	if orgID, err := orgIDFromHeaders(req); err != nil {
+		slog.Warn("Ignoring invalid org ID, using the default org", "error", err)
+	} else {
+		ctx = WithGrafanaOrgID(ctx, orgID)
 	}
+	ctx = WithMaxResponseBytes(ctx, maxResponseBytesFromEnv())
+	ctx = WithDatasourceCacheTTL(ctx, datasourceCacheTTLFromEnv())
+	ctx = WithDefaultTimeRange(ctx, defaultTimeRangeFromEnv())
+	ctx = WithMaxConcurrentRequests(ctx, maxConcurrentRequestsFromEnv())
+	ctx = WithProxyHeaders(ctx, proxyHeadersFromHeaders(req))
+	ctx = WithPromDirectURL(ctx, promDirectURLFromEnv())
+	ctx = WithMaxConcurrentToolCalls(ctx, maxConcurrentToolCallsFromEnv())
 	return WithGrafanaURL(WithGrafanaAPIKey(ctx, apiKey), u)
 }
 
@@ -83,6 +501,109 @@ func WithGrafanaAPIKey(ctx context.Context, apiKey string) context.Context {
 	return context.WithValue(ctx, grafanaAPIKeyKey{}, apiKey)
 }
 
+// WithGrafanaBasicAuth adds Grafana basic auth credentials to the context.
+//
+// The credentials are only used if no API key is set.
+func WithGrafanaBasicAuth(ctx context.Context, basicAuth *url.Userinfo) context.Context {
+	return context.WithValue(ctx, grafanaBasicAuthKey{}, basicAuth)
+}
+
+// WithGrafanaTenantID adds the tenant ID to the context. It is sent as the
+// X-Scope-OrgID header on requests to Loki and Prometheus, which multi-tenant
+// backends such as Mimir require.
+func WithGrafanaTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, grafanaTenantIDKey{}, tenantID)
+}
+
+// WithGrafanaTimeout adds the timeout for requests to Grafana to the context.
+func WithGrafanaTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, grafanaTimeoutKey{}, timeout)
+}
+
+// WithGrafanaTLSConfig adds the TLS configuration for connections to Grafana
+// to the context.
+func WithGrafanaTLSConfig(ctx context.Context, tlsConfig *tls.Config) context.Context {
+	return context.WithValue(ctx, grafanaTLSConfigKey{}, tlsConfig)
+}
+
+// WithAllowInsecureTLSHeader sets whether requests may skip verifying
+// Grafana's certificate with TLSSkipVerifyHeader. It must be added to the
+// context before the SSE context functions run, and is false by default so
+// that clients can't weaken TLS unless the server's operator allows it.
+func WithAllowInsecureTLSHeader(ctx context.Context, allow bool) context.Context {
+	return context.WithValue(ctx, allowInsecureTLSHeaderKey{}, allow)
+}
+
+// AllowInsecureTLSHeaderFromContext returns whether requests may skip
+// verifying Grafana's certificate with TLSSkipVerifyHeader.
+func AllowInsecureTLSHeaderFromContext(ctx context.Context) bool {
+	allow, _ := ctx.Value(allowInsecureTLSHeaderKey{}).(bool)
+	return allow
+}
+
+// WithReadOnly sets whether the server is running in read-only mode, in which
+// tools which only read data still refuse to query datasources that could be
+// used to change it.
+func WithReadOnly(ctx context.Context, readOnly bool) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, readOnly)
+}
+
+// ReadOnlyFromContext returns whether the server is running in read-only
+// mode.
+func ReadOnlyFromContext(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// WithGrafanaOrgID adds the ID of the Grafana org to use to the context. An
+// org ID of 0 means the default org for the credentials.
+func WithGrafanaOrgID(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, grafanaOrgIDKey{}, orgID)
+}
+
+// WithMaxResponseBytes adds the size above which tool results are truncated
+// to the context. A value of 0 disables truncation.
+func WithMaxResponseBytes(ctx context.Context, maxBytes int) context.Context {
+	return context.WithValue(ctx, maxResponseBytesKey{}, maxBytes)
+}
+
+// WithDatasourceCacheTTL adds the time for which datasource lookups are cached
+// to the context. A value of 0 disables the cache.
+func WithDatasourceCacheTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, datasourceCacheTTLKey{}, ttl)
+}
+
+// WithDefaultTimeRange adds the default lookback used by tools when the start
+// of a time range isn't given to the context.
+func WithDefaultTimeRange(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, defaultTimeRangeKey{}, d)
+}
+
+// WithMaxConcurrentRequests adds the number of requests which tools fanning
+// out to many requests make at once to the context.
+func WithMaxConcurrentRequests(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, maxConcurrentRequestsKey{}, n)
+}
+
+// WithProxyHeaders adds extra headers to send with every request to a
+// datasource through Grafana's datasource proxy to the context.
+func WithProxyHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, proxyHeadersKey{}, headers)
+}
+
+// WithMaxConcurrentToolCalls adds the limit on tool calls in flight at once
+// across the server to the context. 0 means no limit.
+func WithMaxConcurrentToolCalls(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, maxConcurrentToolCallsKey{}, n)
+}
+
+// WithPromDirectURL adds the UIDs of the Prometheus datasources to query at
+// their own URL, rather than through Grafana's datasource proxy, to the
+// context. PromDirectURLAll matches every datasource.
+func WithPromDirectURL(ctx context.Context, uids []string) context.Context {
+	return context.WithValue(ctx, promDirectURLKey{}, uids)
+}
+
 // GrafanaURLFromContext extracts the Grafana URL from the context.
 func GrafanaURLFromContext(ctx context.Context) string {
 	if u, ok := ctx.Value(grafanaURLKey{}).(string); ok {
@@ -99,61 +620,207 @@ func GrafanaAPIKeyFromContext(ctx context.Context) string {
 	return ""
 }
 
+// GrafanaBasicAuthFromContext extracts the Grafana basic auth credentials
+// from the context, returning nil if there are none.
+func GrafanaBasicAuthFromContext(ctx context.Context) *url.Userinfo {
+	if b, ok := ctx.Value(grafanaBasicAuthKey{}).(*url.Userinfo); ok {
+		return b
+	}
+	return nil
+}
+
+// GrafanaTenantIDFromContext extracts the tenant ID from the context,
+// returning an empty string if there is none.
+func GrafanaTenantIDFromContext(ctx context.Context) string {
+	if t, ok := ctx.Value(grafanaTenantIDKey{}).(string); ok {
+		return t
+	}
+	return ""
+}
+
+// GrafanaTimeoutFromContext extracts the timeout for requests to Grafana from
+// the context, returning DefaultTimeout if there is none.
+func GrafanaTimeoutFromContext(ctx context.Context) time.Duration {
+	if t, ok := ctx.Value(grafanaTimeoutKey{}).(time.Duration); ok && t > 0 {
+		return t
+	}
+	return DefaultTimeout
+}
+
+// GrafanaTLSConfigFromContext extracts the TLS configuration for connections
+// to Grafana from the context, returning nil if the defaults should be used.
+func GrafanaTLSConfigFromContext(ctx context.Context) *tls.Config {
+	if c, ok := ctx.Value(grafanaTLSConfigKey{}).(*tls.Config); ok {
+		return c
+	}
+	return nil
+}
+
+// GrafanaOrgIDFromContext extracts the ID of the Grafana org to use from the
+// context, returning 0 (the default org) if there is none.
+func GrafanaOrgIDFromContext(ctx context.Context) int64 {
+	if o, ok := ctx.Value(grafanaOrgIDKey{}).(int64); ok {
+		return o
+	}
+	return 0
+}
+
+// MaxResponseBytesFromContext extracts the size above which tool results are
+// truncated from the context, returning DefaultMaxResponseBytes if there is
+// none.
+func MaxResponseBytesFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(maxResponseBytesKey{}).(int); ok {
+		return n
+	}
+	return DefaultMaxResponseBytes
+}
+
+// DatasourceCacheTTLFromContext extracts the time for which datasource lookups
+// are cached from the context, returning DefaultDatasourceCacheTTL if there is
+// none.
+func DatasourceCacheTTLFromContext(ctx context.Context) time.Duration {
+	if ttl, ok := ctx.Value(datasourceCacheTTLKey{}).(time.Duration); ok {
+		return ttl
+	}
+	return DefaultDatasourceCacheTTL
+}
+
+// DefaultTimeRangeFromContext extracts the default lookback used by tools
+// when the start of a time range isn't given from the context, returning
+// DefaultTimeRange if there is none.
+func DefaultTimeRangeFromContext(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(defaultTimeRangeKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+	return DefaultTimeRange
+}
+
+// MaxConcurrentRequestsFromContext extracts the number of requests which
+// tools fanning out to many requests make at once from the context, returning
+// DefaultMaxConcurrentRequests if there is none.
+func MaxConcurrentRequestsFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(maxConcurrentRequestsKey{}).(int); ok && n > 0 {
+		return n
+	}
+	return DefaultMaxConcurrentRequests
+}
+
+// ProxyHeadersFromContext extracts the extra headers to send to datasources
+// from the context, returning nil if there are none.
+func ProxyHeadersFromContext(ctx context.Context) map[string]string {
+	if h, ok := ctx.Value(proxyHeadersKey{}).(map[string]string); ok {
+		return h
+	}
+	return nil
+}
+
+// MaxConcurrentToolCallsFromContext extracts the limit on tool calls in
+// flight at once from the context, returning 0, meaning no limit, if it isn't
+// set.
+func MaxConcurrentToolCallsFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(maxConcurrentToolCallsKey{}).(int); ok {
+		return n
+	}
+	return 0
+}
+
+// PromDirectURLFromContext reports whether the Prometheus datasource with
+// the given UID should be queried at its own URL rather than through
+// Grafana's datasource proxy.
+func PromDirectURLFromContext(ctx context.Context, uid string) bool {
+	uids, _ := ctx.Value(promDirectURLKey{}).([]string)
+	return slices.Contains(uids, PromDirectURLAll) || slices.Contains(uids, uid)
+}
+
 type grafanaClientKey struct{}
 
 // ExtractGrafanaClientFromEnv is a StdioContextFunc that extracts Grafana configuration
 // from environment variables and injects a configured client into the context.
 var ExtractGrafanaClientFromEnv server.StdioContextFunc = func(ctx context.Context) context.Context {
-	cfg := client.DefaultTransportConfig()
-	// Extract transport config from env vars, and set it on the context.
-	var grafanaURL string
-	var parsedURL *url.URL
-	var ok bool
-	var err error
-	if grafanaURL, ok = os.LookupEnv(grafanaURLEnvVar); ok {
-		parsedURL, err = url.Parse(grafanaURL)
-		if err != nil {
-			panic(fmt.Errorf("invalid %s: %w", grafanaURLEnvVar, err))
-		}
-		cfg.Host = parsedURL.Host
-		// The Grafana client will always prefer HTTPS even if the URL is HTTP,
-		// so we need to limit the schemes to HTTP if the URL is HTTP.
-		if parsedURL.Scheme == "http" {
-			cfg.Schemes = []string{"http"}
-		}
-	} else {
-		parsedURL, _ = url.Parse(defaultGrafanaURL)
+	grafanaURL, apiKey := urlAndAPIKeyFromEnv()
+	basicAuth := basicAuthFromEnv()
+	tlsConfig, err := grafanaTLSConfig()
+	if err != nil {
+		panic(fmt.Errorf("invalid TLS configuration: %w", err))
 	}
-
-	apiKey := os.Getenv(grafanaAPIEnvVar)
-	if apiKey != "" {
-		cfg.APIKey = apiKey
+	orgID, err := orgIDFromEnv()
+	if err != nil {
+		panic(err)
+	}
+	cfg, err := newGrafanaTransportConfig(grafanaURL, apiKey, basicAuth, orgID, tlsConfig)
+	if err != nil {
+		panic(err)
 	}
 
-	slog.Debug("Creating Grafana client", "url", parsedURL.Redacted(), "api_key_set", apiKey != "")
+	slog.Debug("Creating Grafana client", "host", cfg.Host, "base_path", cfg.BasePath, "api_key_set", apiKey != "", "basic_auth_set", basicAuth != nil, "tls_config_set", tlsConfig != nil)
 	client := client.NewHTTPClientWithConfig(strfmt.Default, cfg)
-	return context.WithValue(ctx, grafanaClientKey{}, client)
+	return WithGrafanaClient(ctx, client)
 }
 
 // ExtractGrafanaClientFromHeaders is a SSEContextFunc that extracts Grafana configuration
 // from request headers and injects a configured client into the context.
 var ExtractGrafanaClientFromHeaders server.SSEContextFunc = func(ctx context.Context, req *http.Request) context.Context {
+	grafanaURL, apiKey := urlAndAPIKeyFromHeaders(req)
+	// Errors are logged by ExtractGrafanaInfoFromHeaders.
+	tlsConfig, _ := tlsConfigFromHeaders(ctx, req)
+	// An invalid org ID is logged by ExtractGrafanaInfoFromHeaders.
+	orgID, _ := orgIDFromHeaders(req)
+	cfg, err := newGrafanaTransportConfig(grafanaURL, apiKey, basicAuthFromHeaders(req), orgID, tlsConfig)
+	if err != nil {
+		slog.Error("Failed to create Grafana client", "error", err)
+		return ctx
+	}
+	client := client.NewHTTPClientWithConfig(strfmt.Default, cfg)
+	return WithGrafanaClient(ctx, client)
+}
+
+// newGrafanaTransportConfig creates the configuration for a Grafana client
+// which connects to `grafanaURL`. The client authenticates with the API key,
+// or with basic auth if there is no API key, and uses the org with ID `orgID`
+// unless it is 0.
+func newGrafanaTransportConfig(grafanaURL, apiKey string, basicAuth *url.Userinfo, orgID int64, tlsConfig *tls.Config) (*client.TransportConfig, error) {
+	parsedURL, err := url.Parse(grafanaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Grafana URL %s: %w", grafanaURL, err)
+	}
 	cfg := client.DefaultTransportConfig()
-	// Extract transport config from request headers, and set it on the context.
-	u, apiKey := urlAndAPIKeyFromHeaders(req)
-	if u != "" {
-		if url, err := url.Parse(u); err == nil {
-			cfg.Host = url.Host
-			if url.Scheme == "http" {
-				cfg.Schemes = []string{"http"}
-			}
-		}
+	cfg.Host = parsedURL.Host
+	// Grafana may be served from a sub path, such as https://example.com/grafana.
+	cfg.BasePath = strings.TrimRight(parsedURL.Path, "/") + client.DefaultBasePath
+	// The Grafana client will always prefer HTTPS even if the URL is HTTP,
+	// so we need to limit the schemes to HTTP if the URL is HTTP.
+	if parsedURL.Scheme == "http" {
+		cfg.Schemes = []string{"http"}
 	}
 	if apiKey != "" {
 		cfg.APIKey = apiKey
+	} else if basicAuth != nil {
+		cfg.BasicAuth = basicAuth
 	}
-	client := client.NewHTTPClientWithConfig(strfmt.Default, cfg)
-	return WithGrafanaClient(ctx, client)
+	cfg.OrgID = orgID
+	// The client sets TLSConfig on http.DefaultTransport, which is shared by
+	// every request, so use a transport for the TLS configuration instead.
+	cfg.Client = &http.Client{Transport: grafanaTransport(tlsConfig)}
+	return cfg, nil
+}
+
+// grafanaTransports caches the transports created for each TLS configuration
+// so that connections are reused across requests.
+var grafanaTransports sync.Map // map[*tls.Config]*http.Transport
+
+// grafanaTransport returns the transport for Grafana clients with the TLS
+// configuration, or http.DefaultTransport if it is nil.
+func grafanaTransport(tlsConfig *tls.Config) http.RoundTripper {
+	if tlsConfig == nil {
+		return http.DefaultTransport
+	}
+	if t, ok := grafanaTransports.Load(tlsConfig); ok {
+		return t.(*http.Transport)
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsConfig
+	actual, _ := grafanaTransports.LoadOrStore(tlsConfig, t)
+	return actual.(*http.Transport)
 }
 
 // WithGrafanaClient sets the Grafana client in the context.
@@ -163,7 +830,9 @@ func WithGrafanaClient(ctx context.Context, client *client.GrafanaHTTPAPI) conte
 	return context.WithValue(ctx, grafanaClientKey{}, client)
 }
 
-// GrafanaClientFromContext retrieves the Grafana client from the context.
+// GrafanaClientFromContext retrieves the Grafana client from the context,
+// returning nil if there isn't one. Tools should use RequireGrafanaClient
+// instead so that they return an error rather than panicking.
 func GrafanaClientFromContext(ctx context.Context) *client.GrafanaHTTPAPI {
 	c, ok := ctx.Value(grafanaClientKey{}).(*client.GrafanaHTTPAPI)
 	if !ok {
@@ -172,28 +841,69 @@ func GrafanaClientFromContext(ctx context.Context) *client.GrafanaHTTPAPI {
 	return c
 }
 
+// ErrNoGrafanaClient is returned by RequireGrafanaClient if there is no
+// Grafana client in the context, which means the server's context functions
+// weren't set up for the transport in use.
+var ErrNoGrafanaClient = errors.New("no Grafana client in context: the server was not configured with a Grafana client for this transport")
+
+// RequireGrafanaClient retrieves the Grafana client from the context,
+// returning ErrNoGrafanaClient if there isn't one.
+func RequireGrafanaClient(ctx context.Context) (*client.GrafanaHTTPAPI, error) {
+	c := GrafanaClientFromContext(ctx)
+	if c == nil {
+		return nil, ErrNoGrafanaClient
+	}
+	return c, nil
+}
+
 type incidentClientKey struct{}
 
 var ExtractIncidentClientFromEnv server.StdioContextFunc = func(ctx context.Context) context.Context {
 	grafanaURL, apiKey := urlAndAPIKeyFromEnv()
-	if grafanaURL == "" {
-		grafanaURL = defaultGrafanaURL
-	}
-	incidentURL := fmt.Sprintf("%s/api/plugins/grafana-incident-app/resources/api/v1/", grafanaURL)
+	incidentURL := incidentAPIURL(grafanaURL)
 	parsedURL, err := url.Parse(incidentURL)
 	if err != nil {
 		panic(fmt.Errorf("invalid incident URL %s: %w", incidentURL, err))
 	}
-	slog.Debug("Creating Incident client", "url", parsedURL.Redacted(), "api_key_set", apiKey != "")
-	client := incident.NewClient(incidentURL, apiKey)
-	return context.WithValue(ctx, incidentClientKey{}, client)
+	basicAuth := basicAuthFromEnv()
+	slog.Debug("Creating Incident client", "url", parsedURL.Redacted(), "api_key_set", apiKey != "", "basic_auth_set", basicAuth != nil)
+	tlsConfig, err := grafanaTLSConfig()
+	if err != nil {
+		panic(fmt.Errorf("invalid TLS configuration: %w", err))
+	}
+	client := newIncidentClient(incidentURL, apiKey, basicAuth, tlsConfig)
+	return WithIncidentClient(ctx, client)
 }
 
 var ExtractIncidentClientFromHeaders server.SSEContextFunc = func(ctx context.Context, req *http.Request) context.Context {
 	grafanaURL, apiKey := urlAndAPIKeyFromHeaders(req)
-	incidentURL := fmt.Sprintf("%s/api/plugins/grafana-incident-app/resources/api/v1/", grafanaURL)
+	tlsConfig, _ := tlsConfigFromHeaders(ctx, req)
+	client := newIncidentClient(incidentAPIURL(grafanaURL), apiKey, basicAuthFromHeaders(req), tlsConfig)
+	return WithIncidentClient(ctx, client)
+}
+
+// newIncidentClient creates an incident client which authenticates with the
+// API key, or with basic auth if there is no API key.
+func newIncidentClient(incidentURL, apiKey string, basicAuth *url.Userinfo, tlsConfig *tls.Config) *incident.Client {
 	client := incident.NewClient(incidentURL, apiKey)
-	return context.WithValue(ctx, incidentClientKey{}, client)
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.HTTPClient.Transport = transport
+	}
+	if apiKey == "" && basicAuth != nil {
+		password, _ := basicAuth.Password()
+		client.BeforeRequest = func(r *http.Request) error {
+			r.SetBasicAuth(basicAuth.Username(), password)
+			return nil
+		}
+	}
+	beforeRequest := client.BeforeRequest
+	client.BeforeRequest = func(r *http.Request) error {
+		otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+		return beforeRequest(r)
+	}
+	return client
 }
 
 func WithIncidentClient(ctx context.Context, client *incident.Client) context.Context {
@@ -5,9 +5,15 @@ package mcpgrafana
 
 import (
 	"context"
+	"encoding/pem"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +27,95 @@ func TestExtractIncidentClientFromEnv(t *testing.T) {
 	assert.Equal(t, "http://my-test-url.grafana.com/api/plugins/grafana-incident-app/resources/api/v1/", client.RemoteHost)
 }
 
+func TestExtractIncidentClientFromEnvDefaultURL(t *testing.T) {
+	ctx := ExtractIncidentClientFromEnv(context.Background())
+
+	client := IncidentClientFromContext(ctx)
+	require.NotNil(t, client)
+	assert.Equal(t, "http://localhost:3000/api/plugins/grafana-incident-app/resources/api/v1/", client.RemoteHost)
+}
+
+func TestExtractIncidentClientFromHeaders(t *testing.T) {
+	t.Run("no headers, no env", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		ctx := ExtractIncidentClientFromHeaders(context.Background(), req)
+		client := IncidentClientFromContext(ctx)
+		require.NotNil(t, client)
+		assert.Equal(t, "http://localhost:3000/api/plugins/grafana-incident-app/resources/api/v1/", client.RemoteHost)
+	})
+
+	t.Run("no headers, with env", func(t *testing.T) {
+		t.Setenv("GRAFANA_URL", "http://my-test-url.grafana.com/")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		ctx := ExtractIncidentClientFromHeaders(context.Background(), req)
+		client := IncidentClientFromContext(ctx)
+		require.NotNil(t, client)
+		assert.Equal(t, "http://my-test-url.grafana.com/api/plugins/grafana-incident-app/resources/api/v1/", client.RemoteHost)
+	})
+
+	t.Run("with headers", func(t *testing.T) {
+		t.Setenv("GRAFANA_URL", "will-not-be-used")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set(grafanaURLHeader, "https://my-test-url.grafana.com/")
+		ctx := ExtractIncidentClientFromHeaders(context.Background(), req)
+		client := IncidentClientFromContext(ctx)
+		require.NotNil(t, client)
+		assert.Equal(t, "https://my-test-url.grafana.com/api/plugins/grafana-incident-app/resources/api/v1/", client.RemoteHost)
+	})
+}
+
+func TestNewGrafanaTransportConfig(t *testing.T) {
+	t.Run("default url", func(t *testing.T) {
+		cfg, err := newGrafanaTransportConfig(defaultGrafanaURL, "", nil, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost:3000", cfg.Host)
+		assert.Equal(t, "/api", cfg.BasePath)
+		assert.Equal(t, []string{"http"}, cfg.Schemes)
+	})
+
+	t.Run("https url with sub path", func(t *testing.T) {
+		cfg, err := newGrafanaTransportConfig("https://example.com/grafana/", "my-api-key", nil, 0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", cfg.Host)
+		assert.Equal(t, "/grafana/api", cfg.BasePath)
+		assert.Contains(t, cfg.Schemes, "https")
+		assert.Equal(t, "my-api-key", cfg.APIKey)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := newGrafanaTransportConfig("http://[::1", "", nil, 0, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestContextGettersWithoutValues(t *testing.T) {
+	// None of the getters should panic if the value was never set.
+	ctx := context.Background()
+	assert.Equal(t, defaultGrafanaURL, GrafanaURLFromContext(ctx))
+	assert.Equal(t, "", GrafanaAPIKeyFromContext(ctx))
+	assert.Nil(t, GrafanaBasicAuthFromContext(ctx))
+	assert.Equal(t, "", GrafanaTenantIDFromContext(ctx))
+	assert.Equal(t, DefaultTimeout, GrafanaTimeoutFromContext(ctx))
+	assert.Nil(t, GrafanaTLSConfigFromContext(ctx))
+	assert.Nil(t, ProxyHeadersFromContext(ctx))
+	assert.Nil(t, GrafanaClientFromContext(ctx))
+	assert.Nil(t, IncidentClientFromContext(ctx))
+	assert.Equal(t, "", RequestIDFromContext(ctx))
+}
+
+func TestRequireGrafanaClient(t *testing.T) {
+	_, err := RequireGrafanaClient(context.Background())
+	assert.ErrorIs(t, err, ErrNoGrafanaClient)
+
+	c := client.NewHTTPClient(nil)
+	got, err := RequireGrafanaClient(WithGrafanaClient(context.Background(), c))
+	require.NoError(t, err)
+	assert.Same(t, c, got)
+}
+
 func TestExtractGrafanaInfoFromHeaders(t *testing.T) {
 	t.Run("no headers, no env", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "http://example.com", nil)
@@ -33,7 +128,7 @@ func TestExtractGrafanaInfoFromHeaders(t *testing.T) {
 	})
 
 	t.Run("no headers, with env", func(t *testing.T) {
-		t.Setenv("GRAFANA_URL", "http://my-test-url.grafana.com")
+		t.Setenv("GRAFANA_URL", "http://my-test-url.grafana.com/")
 		t.Setenv("GRAFANA_API_KEY", "my-test-api-key")
 
 		req, err := http.NewRequest("GET", "http://example.com", nil)
@@ -73,3 +168,395 @@ func TestExtractGrafanaInfoFromHeaders(t *testing.T) {
 		assert.Equal(t, "my-test-api-key", apiKey)
 	})
 }
+
+func TestExtractGrafanaBasicAuth(t *testing.T) {
+	t.Run("no basic auth", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Nil(t, GrafanaBasicAuthFromContext(ctx))
+	})
+
+	t.Run("basic auth from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_USERNAME", "admin")
+		t.Setenv("GRAFANA_PASSWORD", "secret")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		basicAuth := GrafanaBasicAuthFromContext(ctx)
+		require.NotNil(t, basicAuth)
+		assert.Equal(t, "admin", basicAuth.Username())
+		password, _ := basicAuth.Password()
+		assert.Equal(t, "secret", password)
+	})
+
+	t.Run("basic auth from headers overrides env", func(t *testing.T) {
+		t.Setenv("GRAFANA_USERNAME", "will-not-be-used")
+		t.Setenv("GRAFANA_PASSWORD", "will-not-be-used")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set(grafanaUsernameHeader, "admin")
+		req.Header.Set(grafanaPasswordHeader, "secret")
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		basicAuth := GrafanaBasicAuthFromContext(ctx)
+		require.NotNil(t, basicAuth)
+		assert.Equal(t, "admin", basicAuth.Username())
+		password, _ := basicAuth.Password()
+		assert.Equal(t, "secret", password)
+	})
+}
+
+func TestExtractGrafanaTenantID(t *testing.T) {
+	t.Run("no tenant id", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, "", GrafanaTenantIDFromContext(ctx))
+	})
+
+	t.Run("tenant id from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_TENANT_ID", "tenant-1")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, "tenant-1", GrafanaTenantIDFromContext(ctx))
+	})
+
+	t.Run("tenant id from headers overrides env", func(t *testing.T) {
+		t.Setenv("GRAFANA_TENANT_ID", "will-not-be-used")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set(grafanaTenantIDHeader, "tenant-2")
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, "tenant-2", GrafanaTenantIDFromContext(ctx))
+	})
+}
+
+func TestExtractGrafanaTimeout(t *testing.T) {
+	t.Run("default timeout", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultTimeout, GrafanaTimeoutFromContext(ctx))
+	})
+
+	t.Run("timeout from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_TIMEOUT", "5s")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 5*time.Second, GrafanaTimeoutFromContext(ctx))
+	})
+
+	t.Run("invalid timeout falls back to default", func(t *testing.T) {
+		for _, v := range []string{"not-a-duration", "-1s", "0s"} {
+			t.Setenv("GRAFANA_TIMEOUT", v)
+			ctx := ExtractGrafanaInfoFromEnv(context.Background())
+			assert.Equal(t, DefaultTimeout, GrafanaTimeoutFromContext(ctx), v)
+		}
+	})
+}
+
+func TestTLSConfigFromEnv(t *testing.T) {
+	t.Run("no tls options", func(t *testing.T) {
+		cfg, err := tlsConfigFromEnv()
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("skip verify", func(t *testing.T) {
+		t.Setenv("GRAFANA_TLS_SKIP_VERIFY", "true")
+		cfg, err := tlsConfigFromEnv()
+		require.NoError(t, err)
+		require.NotNil(t, cfg)
+		assert.True(t, cfg.InsecureSkipVerify)
+	})
+
+	t.Run("invalid skip verify", func(t *testing.T) {
+		t.Setenv("GRAFANA_TLS_SKIP_VERIFY", "maybe")
+		_, err := tlsConfigFromEnv()
+		require.Error(t, err)
+	})
+
+	t.Run("ca file", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		require.NoError(t, os.WriteFile(caFile, ca, 0o600))
+		t.Setenv("GRAFANA_TLS_CA_FILE", caFile)
+
+		cfg, err := tlsConfigFromEnv()
+		require.NoError(t, err)
+		require.NotNil(t, cfg)
+		assert.False(t, cfg.InsecureSkipVerify)
+
+		// The server's self-signed certificate is trusted with the CA file.
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("invalid ca file", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+		t.Setenv("GRAFANA_TLS_CA_FILE", caFile)
+		_, err := tlsConfigFromEnv()
+		require.Error(t, err)
+	})
+
+	t.Run("cert without key", func(t *testing.T) {
+		t.Setenv("GRAFANA_TLS_CERT_FILE", "/path/to/cert.pem")
+		_, err := tlsConfigFromEnv()
+		require.Error(t, err)
+	})
+}
+
+func TestExtractGrafanaOrgID(t *testing.T) {
+	t.Run("no org id", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, int64(0), GrafanaOrgIDFromContext(ctx))
+	})
+
+	t.Run("org id from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_ORG_ID", "2")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, int64(2), GrafanaOrgIDFromContext(ctx))
+
+		ctx = ExtractGrafanaClientFromEnv(context.Background())
+		assert.Equal(t, int64(2), GrafanaClientFromContext(ctx).OrgID())
+	})
+
+	t.Run("org id from headers overrides env", func(t *testing.T) {
+		t.Setenv("GRAFANA_ORG_ID", "2")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set(grafanaOrgIDHeader, "3")
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, int64(3), GrafanaOrgIDFromContext(ctx))
+
+		ctx = ExtractGrafanaClientFromHeaders(context.Background(), req)
+		assert.Equal(t, int64(3), GrafanaClientFromContext(ctx).OrgID())
+	})
+
+	t.Run("invalid org id from headers is ignored", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set(grafanaOrgIDHeader, "not-a-number")
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, int64(0), GrafanaOrgIDFromContext(ctx))
+	})
+}
+
+func TestExtractMaxResponseBytes(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultMaxResponseBytes, MaxResponseBytesFromContext(ctx))
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_MAX_RESPONSE_BYTES", "0")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 0, MaxResponseBytesFromContext(ctx))
+	})
+
+	t.Run("invalid value falls back to default", func(t *testing.T) {
+		t.Setenv("GRAFANA_MAX_RESPONSE_BYTES", "-1")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultMaxResponseBytes, MaxResponseBytesFromContext(ctx))
+	})
+}
+
+func TestExtractDatasourceCacheTTL(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultDatasourceCacheTTL, DatasourceCacheTTLFromContext(ctx))
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_DATASOURCE_CACHE_TTL", "0")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, time.Duration(0), DatasourceCacheTTLFromContext(ctx))
+	})
+
+	t.Run("from headers", func(t *testing.T) {
+		t.Setenv("GRAFANA_DATASOURCE_CACHE_TTL", "1m")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, time.Minute, DatasourceCacheTTLFromContext(ctx))
+	})
+
+	t.Run("invalid value falls back to default", func(t *testing.T) {
+		t.Setenv("GRAFANA_DATASOURCE_CACHE_TTL", "soon")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultDatasourceCacheTTL, DatasourceCacheTTLFromContext(ctx))
+	})
+}
+
+func TestExtractDefaultTimeRange(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultTimeRange, DefaultTimeRangeFromContext(ctx))
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_DEFAULT_TIME_RANGE", "6h")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 6*time.Hour, DefaultTimeRangeFromContext(ctx))
+	})
+
+	t.Run("from headers", func(t *testing.T) {
+		t.Setenv("GRAFANA_DEFAULT_TIME_RANGE", "15m")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, 15*time.Minute, DefaultTimeRangeFromContext(ctx))
+	})
+
+	t.Run("invalid value falls back to default", func(t *testing.T) {
+		t.Setenv("GRAFANA_DEFAULT_TIME_RANGE", "0s")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultTimeRange, DefaultTimeRangeFromContext(ctx))
+	})
+}
+
+func TestExtractProxyHeaders(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Empty(t, ProxyHeadersFromContext(ctx))
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_PROXY_HEADERS", "x-query-tags=source=mcp, X-Cost-Center = platform,invalid")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, map[string]string{
+			"X-Query-Tags":  "source=mcp",
+			"X-Cost-Center": "platform",
+		}, ProxyHeadersFromContext(ctx))
+	})
+
+	t.Run("from headers", func(t *testing.T) {
+		t.Setenv("GRAFANA_PROXY_HEADERS", "X-Query-Tags=source=mcp,X-Cost-Center=platform")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Grafana-Proxy-X-Cost-Center", "search")
+		req.Header.Set("X-Grafana-Proxy-X-Route", "eu")
+		req.Header.Set("X-Grafana-URL", "http://grafana.example.com")
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, map[string]string{
+			"X-Query-Tags":  "source=mcp",
+			"X-Cost-Center": "search",
+			"X-Route":       "eu",
+		}, ProxyHeadersFromContext(ctx))
+	})
+
+	t.Run("custom prefix", func(t *testing.T) {
+		t.Setenv("GRAFANA_PROXY_HEADER_PREFIX", "X-Upstream-")
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Upstream-X-Route", "eu")
+		req.Header.Set("X-Grafana-Proxy-X-Cost-Center", "search")
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.Equal(t, map[string]string{"X-Route": "eu"}, ProxyHeadersFromContext(ctx))
+	})
+}
+
+func TestExtractMaxConcurrentRequests(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultMaxConcurrentRequests, MaxConcurrentRequestsFromContext(ctx))
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_MAX_CONCURRENT_REQUESTS", "2")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 2, MaxConcurrentRequestsFromContext(ctx))
+	})
+
+	t.Run("invalid value falls back to default", func(t *testing.T) {
+		t.Setenv("GRAFANA_MAX_CONCURRENT_REQUESTS", "0")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, DefaultMaxConcurrentRequests, MaxConcurrentRequestsFromContext(ctx))
+	})
+}
+
+func TestExtractMaxConcurrentToolCalls(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 0, MaxConcurrentToolCallsFromContext(ctx))
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		t.Setenv("GRAFANA_MAX_CONCURRENT_TOOL_CALLS", "4")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 4, MaxConcurrentToolCallsFromContext(ctx))
+	})
+
+	t.Run("invalid value means no limit", func(t *testing.T) {
+		t.Setenv("GRAFANA_MAX_CONCURRENT_TOOL_CALLS", "-1")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.Equal(t, 0, MaxConcurrentToolCallsFromContext(ctx))
+	})
+}
+
+func TestExtractPromDirectURL(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.False(t, PromDirectURLFromContext(ctx, "prometheus"))
+	})
+
+	t.Run("by UID", func(t *testing.T) {
+		t.Setenv("GRAFANA_PROM_DIRECT_URL", "prometheus, mimir")
+		ctx := ExtractGrafanaInfoFromEnv(context.Background())
+		assert.True(t, PromDirectURLFromContext(ctx, "prometheus"))
+		assert.True(t, PromDirectURLFromContext(ctx, "mimir"))
+		assert.False(t, PromDirectURLFromContext(ctx, "other"))
+	})
+
+	t.Run("all", func(t *testing.T) {
+		t.Setenv("GRAFANA_PROM_DIRECT_URL", "*")
+		req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		ctx := ExtractGrafanaInfoFromHeaders(context.Background(), req)
+		assert.True(t, PromDirectURLFromContext(ctx, "other"))
+	})
+}
+
+func TestTLSSkipVerifyHeader(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "name": "Main Org."}`))
+	}))
+	defer server.Close()
+
+	newRequest := func(t *testing.T, skipVerify string) *http.Request {
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		req.Header.Set(grafanaURLHeader, server.URL)
+		if skipVerify != "" {
+			req.Header.Set(TLSSkipVerifyHeader, skipVerify)
+		}
+		return req
+	}
+	getOrg := func(ctx context.Context) error {
+		_, err := GrafanaClientFromContext(ctx).Org.GetCurrentOrg()
+		return err
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		ctx := ComposedSSEContextFunc(WithAllowInsecureTLSHeader(context.Background(), true), newRequest(t, "true"))
+		tlsConfig := GrafanaTLSConfigFromContext(ctx)
+		require.NotNil(t, tlsConfig)
+		assert.True(t, tlsConfig.InsecureSkipVerify)
+		assert.NoError(t, getOrg(ctx))
+
+		// The same configuration is used for every request, so that
+		// transports are reused.
+		other := ExtractGrafanaInfoFromHeaders(WithAllowInsecureTLSHeader(context.Background(), true), newRequest(t, "true"))
+		assert.Same(t, tlsConfig, GrafanaTLSConfigFromContext(other))
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		ctx := ComposedSSEContextFunc(context.Background(), newRequest(t, "true"))
+		assert.Nil(t, GrafanaTLSConfigFromContext(ctx))
+		assert.Error(t, getOrg(ctx))
+	})
+
+	t.Run("allowed without header", func(t *testing.T) {
+		for _, value := range []string{"", "false", "maybe"} {
+			ctx := ComposedSSEContextFunc(WithAllowInsecureTLSHeader(context.Background(), true), newRequest(t, value))
+			assert.Nil(t, GrafanaTLSConfigFromContext(ctx), value)
+			assert.Error(t, getOrg(ctx), value)
+		}
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/invopop/jsonschema"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -21,6 +24,15 @@ import (
 type Tool struct {
 	Tool    mcp.Tool
 	Handler server.ToolHandlerFunc
+	// Mutating is true if the tool can create, update or delete resources.
+	// Mutating tools aren't registered in read-only mode.
+	Mutating bool
+}
+
+// AsMutating returns a copy of the Tool marked as mutating.
+func (t Tool) AsMutating() Tool {
+	t.Mutating = true
+	return t
 }
 
 // Register adds the Tool to the given MCPServer.
@@ -34,6 +46,54 @@ func (t *Tool) Register(mcp *server.MCPServer) {
 	mcp.AddTool(t.Tool, t.Handler)
 }
 
+// ToolFilter reports whether a tool should be registered. It is given the
+// tool's category, such as "prometheus" or "loki", along with the tool.
+//
+// A nil ToolFilter allows every tool.
+type ToolFilter func(category string, tool Tool) bool
+
+// NewToolFilter creates a ToolFilter from lists of tool or category names.
+//
+// If `enabled` is non-empty, only tools whose name or category is in it are
+// allowed. Tools whose name or category is in `disabled` are never allowed,
+// even if they are also enabled.
+func NewToolFilter(enabled, disabled []string) ToolFilter {
+	if len(enabled) == 0 && len(disabled) == 0 {
+		return nil
+	}
+	return func(category string, tool Tool) bool {
+		if slices.Contains(disabled, category) || slices.Contains(disabled, tool.Tool.Name) {
+			return false
+		}
+		if len(enabled) == 0 {
+			return true
+		}
+		return slices.Contains(enabled, category) || slices.Contains(enabled, tool.Tool.Name)
+	}
+}
+
+// ReadOnly returns a ToolFilter which allows the same tools as f, except for
+// mutating tools.
+func (f ToolFilter) ReadOnly() ToolFilter {
+	return func(category string, tool Tool) bool {
+		if tool.Mutating {
+			return false
+		}
+		return f == nil || f(category, tool)
+	}
+}
+
+// RegisterTools adds the tools in a category to the given MCPServer, skipping
+// any which aren't allowed by the filter.
+func RegisterTools(mcp *server.MCPServer, filter ToolFilter, category string, tools ...Tool) {
+	for _, tool := range tools {
+		if filter != nil && !filter(category, tool) {
+			continue
+		}
+		tool.Register(mcp)
+	}
+}
+
 // MustTool creates a new Tool from the given name, description, and toolHandler.
 // It panics if the tool cannot be created.
 func MustTool[T any, R any](name, description string, toolHandler ToolHandlerFunc[T, R]) Tool {
@@ -44,6 +104,48 @@ func MustTool[T any, R any](name, description string, toolHandler ToolHandlerFun
 	return Tool{Tool: tool, Handler: handler}
 }
 
+// ToolError is an error which is expected in normal use and can be corrected
+// by the caller, such as an invalid query or a datasource which doesn't exist.
+//
+// ConvertTool returns tool errors as a tool result with isError set, rather
+// than as a protocol error, so that the model sees the message and can react
+// to it. Any other error returned by a tool handler is treated as unexpected.
+type ToolError struct {
+	Err error
+}
+
+// NewToolError marks err as a ToolError. It returns nil if err is nil.
+func NewToolError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ToolError{Err: err}
+}
+
+func (e *ToolError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// toolErrorResult returns a tool result with isError set showing err.
+func toolErrorResult(err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{mcp.NewTextContent(err.Error())},
+		IsError: true,
+	}
+}
+
+// contentType is the type of the content of a tool result, such as text or
+// an image.
+var contentType = reflect.TypeOf((*mcp.Content)(nil)).Elem()
+
+// sliceArgumentName is the name of the parameter which holds the items of a
+// tool whose handler takes a slice, since a tool's input must be an object.
+const sliceArgumentName = "items"
+
 // ToolHandlerFunc is the type of a handler function for a tool.
 type ToolHandlerFunc[T any, R any] = func(ctx context.Context, request T) (R, error)
 
@@ -53,6 +155,14 @@ type ToolHandlerFunc[T any, R any] = func(ctx context.Context, request T) (R, er
 // to be used as the parameters for the tool. The second argument must not be a pointer,
 // should be marshalable to JSON, and the fields should have a `jsonschema` tag with the
 // description of the parameter.
+//
+// The second argument may instead be a slice, for tools which take a list of
+// inputs, such as the UIDs of dashboards to fetch. The tool then has a single
+// required `items` parameter holding the list.
+//
+// The handler's result is returned as JSON text, unless it is a string, a
+// *mcp.CallToolResult, or content such as an mcp.ImageContent or a
+// []mcp.Content, which are returned as they are.
 func ConvertTool[T any, R any](name, description string, toolHandler ToolHandlerFunc[T, R]) (mcp.Tool, server.ToolHandlerFunc, error) {
 	zero := mcp.Tool{}
 	handlerValue := reflect.ValueOf(toolHandler)
@@ -75,13 +185,19 @@ func ConvertTool[T any, R any](name, description string, toolHandler ToolHandler
 	}
 
 	argType := handlerType.In(1)
-	if argType.Kind() != reflect.Struct {
-		return zero, nil, errors.New("tool handler second argument must be a struct")
+	sliceArg := argType.Kind() == reflect.Slice
+	if argType.Kind() != reflect.Struct && !sliceArg {
+		return zero, nil, errors.New("tool handler second argument must be a struct or a slice")
 	}
 
-	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jsonSchema := createJSONSchemaFromHandler(toolHandler)
 
-		s, err := json.Marshal(request.Params.Arguments)
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var rawArgs any = request.Params.Arguments
+		if sliceArg {
+			rawArgs = request.Params.Arguments[sliceArgumentName]
+		}
+		s, err := json.Marshal(rawArgs)
 		if err != nil {
 			return nil, fmt.Errorf("marshal args: %w", err)
 		}
@@ -90,11 +206,16 @@ func ConvertTool[T any, R any](name, description string, toolHandler ToolHandler
 		if err := json.Unmarshal([]byte(s), unmarshaledArgs); err != nil {
 			return nil, fmt.Errorf("unmarshal args: %s", err)
 		}
+		// Invalid arguments are shown to the model so that it can correct
+		// them, rather than failing the request.
+		if err := validateArguments(jsonSchema, "", request.Params.Arguments); err != nil {
+			return toolErrorResult(NewToolError(err)), nil
+		}
 
 		// Need to dereference the unmarshaled arguments
 		of := reflect.ValueOf(unmarshaledArgs)
 		if of.Kind() != reflect.Ptr || !of.Elem().CanInterface() {
-			return nil, errors.New("arguments must be a struct")
+			return nil, errors.New("arguments must be a struct or a slice")
 		}
 
 		args := []reflect.Value{reflect.ValueOf(ctx), of.Elem()}
@@ -117,7 +238,12 @@ func ConvertTool[T any, R any](name, description string, toolHandler ToolHandler
 			}
 		}
 
-		// If there's an error, return nil result and the error
+		// If there's an error, return nil result and the error, unless it's
+		// an expected failure which should be shown to the model.
+		var toolErr *ToolError
+		if errors.As(handlerErr, &toolErr) {
+			return toolErrorResult(handlerErr), nil
+		}
 		if handlerErr != nil {
 			return nil, handlerErr
 		}
@@ -148,31 +274,42 @@ func ConvertTool[T any, R any](name, description string, toolHandler ToolHandler
 			return &callResult, nil
 		}
 
-		// Case 3: String or *string
+		// Case 3: Content, such as an image, or a list of contents, which
+		// are returned as they are rather than marshalled to JSON
+		if contents, ok := returnVal.([]mcp.Content); ok {
+			return &mcp.CallToolResult{Content: contents}, nil
+		}
+		if returnType.Kind() == reflect.Ptr && returnType.Elem().Implements(contentType) {
+			returnVal = output[0].Elem().Interface()
+		}
+		if content, ok := returnVal.(mcp.Content); ok {
+			return &mcp.CallToolResult{Content: []mcp.Content{content}}, nil
+		}
+
+		// Case 4: String or *string
 		if str, ok := returnVal.(string); ok {
 			if str == "" {
 				return nil, nil
 			}
-			return mcp.NewToolResultText(str), nil
+			return mcp.NewToolResultText(truncateResponse(ctx, str)), nil
 		}
 
 		if strPtr, ok := returnVal.(*string); ok {
 			if strPtr == nil || *strPtr == "" {
 				return nil, nil
 			}
-			return mcp.NewToolResultText(*strPtr), nil
+			return mcp.NewToolResultText(truncateResponse(ctx, *strPtr)), nil
 		}
 
-		// Case 4: Any other type - marshal to JSON
+		// Case 5: Any other type - marshal to JSON
 		jsonBytes, err := json.Marshal(returnVal)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal return value: %s", err)
 		}
 
-		return mcp.NewToolResultText(string(jsonBytes)), nil
+		return mcp.NewToolResultText(truncateResponse(ctx, string(jsonBytes))), nil
 	}
 
-	jsonSchema := createJSONSchemaFromHandler(toolHandler)
 	properties := make(map[string]any, jsonSchema.Properties.Len())
 	for pair := jsonSchema.Properties.Oldest(); pair != nil; pair = pair.Next() {
 		properties[pair.Key] = pair.Value
@@ -187,16 +324,121 @@ func ConvertTool[T any, R any](name, description string, toolHandler ToolHandler
 		Name:        name,
 		Description: description,
 		InputSchema: inputSchema,
-	}, handler, nil
+	}, loggedToolHandler(name, limitedToolHandler(tracedToolHandler(name, handler))), nil
 }
 
-// Creates a full JSON schema from a user provided handler by introspecting the arguments
+// validateArguments checks tool arguments against the schema generated for
+// the handler's parameters, so that a missing required parameter is reported
+// rather than silently becoming a zero value. Only `required` and `enum` are
+// enforced, in nested objects and arrays too; type mismatches are left to
+// the JSON unmarshalling of the arguments. An empty string is accepted for an
+// optional enum parameter, since handlers treat it the same as leaving it
+// out.
+func validateArguments(schema *jsonschema.Schema, path string, value any) error {
+	if schema == nil || value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if arg, ok := v[name]; !ok || arg == nil {
+				return fmt.Errorf("missing required parameter %q", joinArgumentPath(path, name))
+			}
+		}
+		if schema.Properties == nil {
+			return nil
+		}
+		for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			arg, ok := v[pair.Key]
+			if !ok {
+				continue
+			}
+			argPath := joinArgumentPath(path, pair.Key)
+			if str, ok := arg.(string); ok && str == "" && !slices.Contains(schema.Required, pair.Key) {
+				continue
+			}
+			if err := validateArguments(pair.Value, argPath, arg); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if err := validateArguments(schema.Items, fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	default:
+		if len(schema.Enum) == 0 {
+			return nil
+		}
+		// Enum values from struct tags are always strings, so compare the
+		// formatted values rather than the JSON-decoded ones.
+		allowed := make([]string, len(schema.Enum))
+		for i, e := range schema.Enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		if !slices.Contains(allowed, fmt.Sprint(value)) {
+			return fmt.Errorf("invalid value %q for parameter %q, must be one of %s", fmt.Sprint(value), path, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+func joinArgumentPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// truncateResponse truncates a tool result which is larger than the response
+// size budget in the context, adding a marker with the number of bytes removed
+// and a note asking for a narrower query.
+func truncateResponse(ctx context.Context, text string) string {
+	maxBytes := MaxResponseBytesFromContext(ctx)
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text
+	}
+	// Don't cut a multi-byte character in half.
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf(
+		"%s...[truncated %d bytes]\n\nThe result was too large to return in full. Narrow the query, for example with a shorter time range, more specific filters or a lower limit, to see the rest.",
+		text[:cut], len(text)-cut,
+	)
+}
+
+// Creates a full JSON schema from a user provided handler by introspecting the arguments.
+// If the handler takes a slice, the schema is an object with the slice as its
+// single, required `items` parameter.
+// Parameters are described with `jsonschema` struct tags; a parameter which
+// only accepts a fixed set of values should list them with repeated `enum`
+// keys, e.g. `jsonschema:"description=The type of query,enum=range,enum=instant"`,
+// so that clients are told the allowed values. Commas inside a description
+// must be escaped as `\,`, otherwise the rest of the description is dropped.
 func createJSONSchemaFromHandler(handler any) *jsonschema.Schema {
 	handlerValue := reflect.ValueOf(handler)
 	handlerType := handlerValue.Type()
 	argumentType := handlerType.In(1)
-	inputSchema := jsonSchemaReflector.ReflectFromType(argumentType)
-	return inputSchema
+	if argumentType.Kind() != reflect.Slice {
+		return jsonSchemaReflector.ReflectFromType(argumentType)
+	}
+	// Only structs can be expanded.
+	reflector := jsonSchemaReflector
+	reflector.ExpandedStruct = false
+	itemsSchema := reflector.ReflectFromType(argumentType)
+	itemsSchema.Version = ""
+	itemsSchema.Definitions = nil
+	itemsSchema.Description = "The items to process"
+	properties := jsonschema.NewProperties()
+	properties.Set(sliceArgumentName, itemsSchema)
+	return &jsonschema.Schema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{sliceArgumentName},
+	}
 }
 
 var (
@@ -0,0 +1,456 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/grafana/grafana-openapi-client-go/client/health"
+	"github.com/grafana/grafana-openapi-client-go/client/org"
+	"github.com/grafana/grafana-openapi-client-go/client/orgs"
+	"github.com/grafana/grafana-openapi-client-go/client/signed_in_user"
+	"github.com/grafana/grafana-openapi-client-go/client/teams"
+	"github.com/grafana/grafana-openapi-client-go/client/users"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+type ListTeamsParams struct {
+	Query string `json:"query,omitempty" jsonschema:"description=Optionally\\, only return teams whose name contains this string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of teams to return. Defaults to 1000"`
+	Page  int    `json:"page,omitempty" jsonschema:"description=Optionally\\, the page number to return (1-based). The limit is used as the page size"`
+}
+
+func (p ListTeamsParams) validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	if p.Page < 0 {
+		return fmt.Errorf("invalid page: %d, must be greater than 0", p.Page)
+	}
+	return nil
+}
+
+type teamSummary struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	MemberCount int64  `json:"memberCount"`
+}
+
+type listTeamsResult struct {
+	Teams []teamSummary `json:"teams"`
+	// TotalCount is the number of teams matching the query across all
+	// pages.
+	TotalCount int64 `json:"totalCount"`
+	Page       int64 `json:"page"`
+}
+
+func listTeams(ctx context.Context, args ListTeamsParams) (*listTeamsResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list teams: %w", err))
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	params := teams.NewSearchTeamsParamsWithContext(ctx)
+	if args.Query != "" {
+		params.SetQuery(&args.Query)
+	}
+	if args.Limit > 0 {
+		limit := int64(args.Limit)
+		params.SetPerpage(&limit)
+	}
+	if args.Page > 0 {
+		page := int64(args.Page)
+		params.SetPage(&page)
+	}
+	response, err := c.Teams.SearchTeams(params)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	return summarizeTeams(response.Payload), nil
+}
+
+func summarizeTeams(result *models.SearchTeamQueryResult) *listTeamsResult {
+	summary := &listTeamsResult{Teams: []teamSummary{}}
+	if result == nil {
+		return summary
+	}
+	summary.TotalCount = result.TotalCount
+	summary.Page = result.Page
+	for _, t := range result.Teams {
+		if t == nil {
+			continue
+		}
+		summary.Teams = append(summary.Teams, teamSummary{
+			ID:          t.ID,
+			Name:        t.Name,
+			MemberCount: t.MemberCount,
+		})
+	}
+	return summary
+}
+
+var ListTeams = mcpgrafana.MustTool(
+	"list_teams",
+	"List Grafana teams, returning the ID, name and member count of each, and the total number of matching teams. Use this to resolve a team name to the ID used by alert rules, OnCall and permissions",
+	listTeams,
+)
+
+type GetCurrentUserParams struct{}
+
+type currentUser struct {
+	ID             int64  `json:"id"`
+	UID            string `json:"uid"`
+	Login          string `json:"login"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	OrgID          int64  `json:"orgId"`
+	OrgRole        string `json:"orgRole"`
+	IsGrafanaAdmin bool   `json:"isGrafanaAdmin"`
+}
+
+func getCurrentUser(ctx context.Context, args GetCurrentUserParams) (*currentUser, error) {
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get current user: %w", err)
+	}
+	profile, err := c.SignedInUser.GetSignedInUserWithParams(signed_in_user.NewGetSignedInUserParamsWithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get current user: %w", err)
+	}
+	// The profile doesn't include the user's role, so find it in the list of
+	// the user's orgs.
+	orgs, err := c.SignedInUser.GetSignedInUserOrgListWithParams(signed_in_user.NewGetSignedInUserOrgListParamsWithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get current user orgs: %w", err)
+	}
+	return summarizeCurrentUser(profile.Payload, orgs.Payload), nil
+}
+
+func summarizeCurrentUser(profile *models.UserProfileDTO, orgs []*models.UserOrgDTO) *currentUser {
+	user := &currentUser{
+		ID:             profile.ID,
+		UID:            profile.UID,
+		Login:          profile.Login,
+		Name:           profile.Name,
+		Email:          profile.Email,
+		OrgID:          profile.OrgID,
+		IsGrafanaAdmin: profile.IsGrafanaAdmin,
+	}
+	for _, org := range orgs {
+		if org != nil && org.OrgID == profile.OrgID {
+			user.OrgRole = org.Role
+			break
+		}
+	}
+	return user
+}
+
+var GetCurrentUser = mcpgrafana.MustTool(
+	"get_current_user",
+	"Get the user the server is authenticated as, including their login, email, current org and role in it. Use this to find out who 'me' is, for example when assigning an incident role or creating a silence",
+	getCurrentUser,
+)
+
+type SearchUsersParams struct {
+	Query string `json:"query,omitempty" jsonschema:"description=Optionally\\, only return users whose login\\, email or name contains this string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of users to return. Defaults to 1000"`
+	Page  int    `json:"page,omitempty" jsonschema:"description=Optionally\\, the page number to return (1-based). The limit is used as the page size"`
+}
+
+func (p SearchUsersParams) validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	if p.Page < 0 {
+		return fmt.Errorf("invalid page: %d, must be greater than 0", p.Page)
+	}
+	return nil
+}
+
+type userSummary struct {
+	ID            int64  `json:"id"`
+	UID           string `json:"uid"`
+	Login         string `json:"login"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	IsAdmin       bool   `json:"isAdmin"`
+	IsDisabled    bool   `json:"isDisabled"`
+	LastSeenAtAge string `json:"lastSeenAtAge,omitempty"`
+}
+
+type searchUsersResult struct {
+	Users []userSummary `json:"users"`
+	// TotalCount is the number of users matching the query across all
+	// pages.
+	TotalCount int64 `json:"totalCount"`
+	Page       int64 `json:"page"`
+}
+
+// withQueryParams adds query parameters to a request which the generated
+// client doesn't support for the operation.
+func withQueryParams(params url.Values) func(*runtime.ClientOperation) {
+	return func(op *runtime.ClientOperation) {
+		writer := op.Params
+		op.Params = runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, reg strfmt.Registry) error {
+			if err := writer.WriteToRequest(r, reg); err != nil {
+				return err
+			}
+			for k, v := range params {
+				if err := r.SetQueryParam(k, v...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+func searchUsers(ctx context.Context, args SearchUsersParams) (*searchUsersResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("search users: %w", err))
+	}
+
+	query := url.Values{}
+	if args.Query != "" {
+		query.Set("query", args.Query)
+	}
+	if args.Limit > 0 {
+		query.Set("perpage", strconv.Itoa(args.Limit))
+	}
+	if args.Page > 0 {
+		query.Set("page", strconv.Itoa(args.Page))
+	}
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	response, err := c.Users.SearchUsersWithPagingWithParams(
+		users.NewSearchUsersWithPagingParamsWithContext(ctx),
+		withQueryParams(query),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	return summarizeUsers(response.Payload), nil
+}
+
+func summarizeUsers(result *models.SearchUserQueryResult) *searchUsersResult {
+	summary := &searchUsersResult{Users: []userSummary{}}
+	if result == nil {
+		return summary
+	}
+	summary.TotalCount = result.TotalCount
+	summary.Page = result.Page
+	for _, u := range result.Users {
+		if u == nil {
+			continue
+		}
+		summary.Users = append(summary.Users, userSummary{
+			ID:            u.ID,
+			UID:           u.UID,
+			Login:         u.Login,
+			Name:          u.Name,
+			Email:         u.Email,
+			IsAdmin:       u.IsAdmin,
+			IsDisabled:    u.IsDisabled,
+			LastSeenAtAge: u.LastSeenAtAge,
+		})
+	}
+	return summary
+}
+
+var SearchUsers = mcpgrafana.MustTool(
+	"search_users",
+	"Search all users of the Grafana instance by login, email or name, returning the ID, login, name and email of each, and the total number of matching users. Requires Grafana server admin permissions",
+	searchUsers,
+)
+
+type ListOrganizationsParams struct {
+	Query string `json:"query,omitempty" jsonschema:"description=Optionally\\, only return organizations whose name contains this string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of organizations to return. Defaults to 1000"`
+	Page  int    `json:"page,omitempty" jsonschema:"description=Optionally\\, the page number to return (1-based). The limit is used as the page size"`
+}
+
+func (p ListOrganizationsParams) validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	if p.Page < 0 {
+		return fmt.Errorf("invalid page: %d, must be greater than 0", p.Page)
+	}
+	return nil
+}
+
+type organization struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// serverAdminError explains a 403 Forbidden from an API which requires
+// Grafana server admin permissions. Service accounts are scoped to a single
+// org, so they can never have them.
+func serverAdminError(err error) error {
+	var sc statusCoder
+	if errors.As(err, &sc) && sc.IsCode(http.StatusForbidden) {
+		return mcpgrafana.NewToolError(fmt.Errorf("permission denied: this requires Grafana server admin permissions, which service accounts can't have. Use get_organization without an ID or name to get the current organization: %w", err))
+	}
+	return grafanaAPIError(err)
+}
+
+func listOrganizations(ctx context.Context, args ListOrganizationsParams) ([]organization, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list organizations: %w", err))
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	params := orgs.NewSearchOrgsParamsWithContext(ctx)
+	if args.Query != "" {
+		params.SetQuery(&args.Query)
+	}
+	if args.Limit > 0 {
+		limit := int64(args.Limit)
+		params.SetPerpage(&limit)
+	}
+	if args.Page > 0 {
+		page := int64(args.Page)
+		params.SetPage(&page)
+	}
+	response, err := c.Orgs.SearchOrgs(params)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", serverAdminError(err))
+	}
+	result := make([]organization, 0, len(response.Payload))
+	for _, o := range response.Payload {
+		if o != nil {
+			result = append(result, organization{ID: o.ID, Name: o.Name})
+		}
+	}
+	return result, nil
+}
+
+var ListOrganizations = mcpgrafana.MustTool(
+	"list_organizations",
+	"List the organizations of the Grafana instance, returning the ID and name of each. Requires Grafana server admin permissions, which service accounts can't have. To query another organization, pass its ID in the X-Grafana-Org-Id header or GRAFANA_ORG_ID",
+	listOrganizations,
+)
+
+type GetOrganizationParams struct {
+	ID   int64  `json:"id,omitempty" jsonschema:"description=Optionally\\, the ID of the organization to get"`
+	Name string `json:"name,omitempty" jsonschema:"description=Optionally\\, the name of the organization to get. If neither ID nor name is given\\, the current organization is returned"`
+}
+
+func getOrganization(ctx context.Context, args GetOrganizationParams) (*organization, error) {
+	if args.ID != 0 && args.Name != "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get organization: only one of id or name can be given"))
+	}
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	var details *models.OrgDetailsDTO
+	switch {
+	case args.ID != 0:
+		var response *orgs.GetOrgByIDOK
+		response, err = c.Orgs.GetOrgByIDWithParams(orgs.NewGetOrgByIDParamsWithContext(ctx).WithOrgID(args.ID))
+		if response != nil {
+			details = response.Payload
+		}
+	case args.Name != "":
+		var response *orgs.GetOrgByNameOK
+		response, err = c.Orgs.GetOrgByNameWithParams(orgs.NewGetOrgByNameParamsWithContext(ctx).WithOrgName(args.Name))
+		if response != nil {
+			details = response.Payload
+		}
+	default:
+		// The current organization is readable by any user.
+		var response *org.GetCurrentOrgOK
+		response, err = c.Org.GetCurrentOrgWithParams(org.NewGetCurrentOrgParamsWithContext(ctx))
+		if response != nil {
+			details = response.Payload
+		}
+	}
+	if err != nil {
+		var sc statusCoder
+		if errors.As(err, &sc) && sc.IsCode(http.StatusNotFound) {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("get organization: organization not found"))
+		}
+		return nil, fmt.Errorf("get organization: %w", serverAdminError(err))
+	}
+	if details == nil {
+		return nil, fmt.Errorf("get organization: empty response")
+	}
+	return &organization{ID: details.ID, Name: details.Name}, nil
+}
+
+var GetOrganization = mcpgrafana.MustTool(
+	"get_organization",
+	"Get the ID and name of a Grafana organization by ID or name, or of the current organization if neither is given. Getting an organization other than the current one requires Grafana server admin permissions",
+	getOrganization,
+)
+
+type GetGrafanaHealthParams struct{}
+
+type grafanaHealth struct {
+	Version          string `json:"version,omitempty"`
+	Commit           string `json:"commit,omitempty"`
+	EnterpriseCommit string `json:"enterpriseCommit,omitempty"`
+	// Database is "ok" if Grafana can reach its database, or "failing".
+	Database string `json:"database"`
+}
+
+// getGrafanaHealth calls /api/health, which Grafana serves without
+// authentication, so it works even if the credentials are wrong.
+func getGrafanaHealth(ctx context.Context, args GetGrafanaHealthParams) (*grafanaHealth, error) {
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get Grafana health: %w", err)
+	}
+	response, err := c.Health.GetHealthWithParams(health.NewGetHealthParamsWithContext(ctx))
+	if err != nil {
+		// Grafana returns 503 if its database is unavailable, which is a
+		// result rather than a failure of the tool.
+		var unavailable *health.GetHealthServiceUnavailable
+		if errors.As(err, &unavailable) {
+			return &grafanaHealth{Database: "failing"}, nil
+		}
+		return nil, fmt.Errorf("get Grafana health: %w", err)
+	}
+	return &grafanaHealth{
+		Version:          response.Payload.Version,
+		Commit:           response.Payload.Commit,
+		EnterpriseCommit: response.Payload.EnterpriseCommit,
+		Database:         response.Payload.Database,
+	}, nil
+}
+
+var GetGrafanaHealth = mcpgrafana.MustTool(
+	"get_grafana_health",
+	"Get the version and commit of the Grafana instance and whether its database is healthy. Use this to diagnose connection problems or to check which features the Grafana version supports",
+	getGrafanaHealth,
+)
+
+func AddAdminTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "admin",
+		ListTeams,
+		GetCurrentUser,
+		SearchUsers,
+		ListOrganizations,
+		GetOrganization,
+		GetGrafanaHealth,
+	)
+}
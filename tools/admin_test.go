@@ -0,0 +1,77 @@
+// Requires a Grafana instance running on localhost:3000.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminTools(t *testing.T) {
+	t.Run("list teams", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listTeams(ctx, ListTeamsParams{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(result.Teams)), result.TotalCount)
+	})
+
+	t.Run("list teams with query", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listTeams(ctx, ListTeamsParams{Query: "non-existent-team"})
+		require.NoError(t, err)
+		assert.Empty(t, result.Teams)
+		assert.Equal(t, int64(0), result.TotalCount)
+	})
+
+	t.Run("list teams with invalid limit", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := listTeams(ctx, ListTeamsParams{Limit: -1})
+		require.Error(t, err)
+	})
+
+	t.Run("get current user", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := getCurrentUser(ctx, GetCurrentUserParams{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.Login)
+		assert.NotEmpty(t, result.OrgRole)
+	})
+
+	t.Run("search users", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := searchUsers(ctx, SearchUsersParams{Query: "admin"})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Users)
+		logins := []string{}
+		for _, u := range result.Users {
+			logins = append(logins, u.Login)
+		}
+		assert.Contains(t, logins, "admin")
+	})
+
+	t.Run("list organizations", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listOrganizations(ctx, ListOrganizationsParams{})
+		require.NoError(t, err)
+		assert.Contains(t, result, organization{ID: 1, Name: "Main Org."})
+	})
+
+	t.Run("get current organization", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := getOrganization(ctx, GetOrganizationParams{})
+		require.NoError(t, err)
+		assert.Equal(t, &organization{ID: 1, Name: "Main Org."}, result)
+	})
+
+	t.Run("get Grafana health", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := getGrafanaHealth(ctx, GetGrafanaHealthParams{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.Version)
+		assert.Equal(t, "ok", result.Database)
+	})
+}
@@ -0,0 +1,153 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchUsersQueryParams(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/users/search", r.URL.Path)
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(models.SearchUserQueryResult{
+			Users:      []*models.UserSearchHitDTO{{ID: 2, Login: "alice", Email: "alice@example.com"}},
+			TotalCount: 3,
+			Page:       2,
+		})
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	cfg := client.DefaultTransportConfig().WithHost(u.Host).WithSchemes([]string{"http"})
+	ctx := mcpgrafana.WithGrafanaClient(context.Background(), client.NewHTTPClientWithConfig(strfmt.Default, cfg))
+
+	result, err := searchUsers(ctx, SearchUsersParams{Query: "ali", Limit: 1, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"query": {"ali"}, "perpage": {"1"}, "page": {"2"}}, query)
+	assert.Equal(t, &searchUsersResult{
+		Users:      []userSummary{{ID: 2, Login: "alice", Email: "alice@example.com"}},
+		TotalCount: 3,
+		Page:       2,
+	}, result)
+}
+
+func TestSummarizeCurrentUser(t *testing.T) {
+	profile := &models.UserProfileDTO{ID: 1, Login: "admin", Email: "admin@localhost", OrgID: 2, IsGrafanaAdmin: true}
+	orgs := []*models.UserOrgDTO{
+		{OrgID: 1, Name: "Main Org.", Role: "Viewer"},
+		{OrgID: 2, Name: "Other", Role: "Admin"},
+	}
+	assert.Equal(t, &currentUser{
+		ID:             1,
+		Login:          "admin",
+		Email:          "admin@localhost",
+		OrgID:          2,
+		OrgRole:        "Admin",
+		IsGrafanaAdmin: true,
+	}, summarizeCurrentUser(profile, orgs))
+}
+
+func TestGetGrafanaHealth(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/health", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"commit":"abc123","database":"ok","version":"11.3.0"}`))
+		})
+		result, err := getGrafanaHealth(ctx, GetGrafanaHealthParams{})
+		require.NoError(t, err)
+		assert.Equal(t, &grafanaHealth{Version: "11.3.0", Commit: "abc123", Database: "ok"}, result)
+	})
+
+	t.Run("database failing", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"commit":"abc123","database":"failing","version":"11.3.0"}`))
+		})
+		result, err := getGrafanaHealth(ctx, GetGrafanaHealthParams{})
+		require.NoError(t, err)
+		assert.Equal(t, "failing", result.Database)
+	})
+}
+
+func TestListOrganizations(t *testing.T) {
+	t.Run("server admin", func(t *testing.T) {
+		var query url.Values
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/orgs", r.URL.Path)
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"id":1,"name":"Main Org."},{"id":2,"name":"Other"}]`))
+		})
+		result, err := listOrganizations(ctx, ListOrganizationsParams{Query: "o", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, "o", query.Get("query"))
+		assert.Equal(t, "10", query.Get("perpage"))
+		assert.Equal(t, []organization{{ID: 1, Name: "Main Org."}, {ID: 2, Name: "Other"}}, result)
+	})
+
+	t.Run("not server admin", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"Permission denied"}`))
+		})
+		_, err := listOrganizations(ctx, ListOrganizationsParams{})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "requires Grafana server admin permissions")
+	})
+}
+
+func TestGetOrganization(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/org":
+			_, _ = w.Write([]byte(`{"id":1,"name":"Main Org."}`))
+		case "/api/orgs/name/Other":
+			_, _ = w.Write([]byte(`{"id":2,"name":"Other"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Organization not found"}`))
+		}
+	})
+
+	t.Run("current", func(t *testing.T) {
+		result, err := getOrganization(ctx, GetOrganizationParams{})
+		require.NoError(t, err)
+		assert.Equal(t, &organization{ID: 1, Name: "Main Org."}, result)
+	})
+
+	t.Run("by name", func(t *testing.T) {
+		result, err := getOrganization(ctx, GetOrganizationParams{Name: "Other"})
+		require.NoError(t, err)
+		assert.Equal(t, &organization{ID: 2, Name: "Other"}, result)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := getOrganization(ctx, GetOrganizationParams{ID: 3})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.EqualError(t, err, "get organization: organization not found")
+	})
+}
@@ -1,9 +1,19 @@
 package tools
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
-
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/prometheus/prometheus/model/labels"
@@ -19,6 +29,18 @@ type ListAlertRulesParams struct {
 	Limit          int        `json:"limit,omitempty" jsonschema:"description=The maximum number of results to return. Default is 100."`
 	Page           int        `json:"page,omitempty" jsonschema:"description=The page number to return."`
 	LabelSelectors []Selector `json:"label_selectors,omitempty" jsonschema:"description=Optionally, a list of matchers to filter alert rules by labels"`
+	FolderUID      string     `json:"folder_uid,omitempty" jsonschema:"description=Optionally\\, the UID of the folder to filter alert rules by. Combined with label selectors\\, only rules matching both are returned"`
+	State          string     `json:"state,omitempty" jsonschema:"description=Optionally\\, only return rules currently in this evaluation state. This requires an extra API call to fetch rule states,enum=firing,enum=pending,enum=normal,enum=error"`
+	SortBy         string     `json:"sort_by,omitempty" jsonschema:"description=Optionally\\, the field to sort rules by before paginating. Defaults to the order returned by Grafana,enum=title,enum=uid,enum=folder"`
+	SortDesc       bool       `json:"sort_desc,omitempty" jsonschema:"description=Optionally\\, sort in descending rather than ascending order. Only used with sort_by"`
+	MatchMode      string     `json:"match_mode,omitempty" jsonschema:"description=Optionally\\, whether rules must match 'all' of the label selectors (the default) or 'any' of them,enum=all,enum=any"`
+}
+
+var validAlertRuleStates = map[string]bool{
+	"firing":  true,
+	"pending": true,
+	"normal":  true,
+	"error":   true,
 }
 
 func (p ListAlertRulesParams) validate() error {
@@ -28,14 +50,27 @@ func (p ListAlertRulesParams) validate() error {
 	if p.Page < 0 {
 		return fmt.Errorf("invalid page: %d, must be greater than 0", p.Page)
 	}
+	if p.State != "" && !validAlertRuleStates[p.State] {
+		return fmt.Errorf("invalid state: %s, must be one of 'firing', 'pending', 'normal' or 'error'", p.State)
+	}
+	if p.SortBy != "" && alertRuleSortKeys[p.SortBy] == nil {
+		return fmt.Errorf("invalid sort_by: %s, must be one of 'title', 'uid' or 'folder'", p.SortBy)
+	}
+	if p.MatchMode != "" && p.MatchMode != "all" && p.MatchMode != "any" {
+		return fmt.Errorf("invalid match_mode: %s, must be 'all' or 'any'", p.MatchMode)
+	}
 
 	return nil
 }
 
 type alertRuleSummary struct {
-	UID    string            `json:"uid"`
-	Title  string            `json:"title"`
-	Labels map[string]string `json:"labels,omitempty"`
+	UID         string            `json:"uid"`
+	Title       string            `json:"title"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	For         string            `json:"for,omitempty"`
+	FolderUID   string            `json:"folderUid,omitempty"`
+	IsPaused    bool              `json:"isPaused"`
 }
 
 func listAlertRules(ctx context.Context, args ListAlertRulesParams) ([]alertRuleSummary, error) {
@@ -43,18 +78,35 @@ func listAlertRules(ctx context.Context, args ListAlertRulesParams) ([]alertRule
 		return nil, fmt.Errorf("list alert rules: %w", err)
 	}
 
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
-	response, err := c.Provisioning.GetAlertRules()
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("list alert rules: %w", err)
 	}
+	response, err := c.Provisioning.GetAlertRules()
+	if err != nil {
+		return nil, fmt.Errorf("list alert rules: %w", grafanaAPIError(err))
+	}
 
-	alertRules, err := filterAlertRules(response.Payload, args.LabelSelectors)
+	alertRules := filterAlertRulesByFolder(response.Payload, args.FolderUID)
+	alertRules, err = filterAlertRules(alertRules, args.LabelSelectors, args.MatchMode == "any")
 	if err != nil {
 		return nil, fmt.Errorf("list alert rules: %w", err)
 	}
 
-	alertRules, err = applyPagination(alertRules, args.Limit, args.Page)
+	if args.State != "" {
+		ac, err := newAlertingClientFromContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list alert rules: %w", err)
+		}
+		states, err := ac.getRuleStates(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list alert rules: %w", err)
+		}
+		alertRules = filterAlertRulesByState(alertRules, states, args.State)
+	}
+
+	sortAlertRules(alertRules, args.SortBy, args.SortDesc)
+	alertRules, err = paginate(alertRules, cmp.Or(args.Limit, DefaultListAlertRulesLimit), args.Page)
 	if err != nil {
 		return nil, fmt.Errorf("list alert rules: %w", err)
 	}
@@ -62,19 +114,94 @@ func listAlertRules(ctx context.Context, args ListAlertRulesParams) ([]alertRule
 	return summarizeAlertRules(alertRules), nil
 }
 
-// filterAlertRules filters a list of alert rules based on label selectors
-func filterAlertRules(rules models.ProvisionedAlertRules, selectors []Selector) (models.ProvisionedAlertRules, error) {
+// alertRuleSortKeys returns the value of each field alert rules can be sorted
+// by.
+var alertRuleSortKeys = map[string]func(rule *models.ProvisionedAlertRule) string{
+	"title": func(rule *models.ProvisionedAlertRule) string {
+		if rule.Title == nil {
+			return ""
+		}
+		return *rule.Title
+	},
+	"uid": func(rule *models.ProvisionedAlertRule) string { return rule.UID },
+	"folder": func(rule *models.ProvisionedAlertRule) string {
+		if rule.FolderUID == nil {
+			return ""
+		}
+		return *rule.FolderUID
+	},
+}
+
+// sortAlertRules sorts alert rules in place by the given field, breaking ties
+// by UID so that pages are stable. If `sortBy` is an empty string the order is
+// left unchanged.
+func sortAlertRules(rules models.ProvisionedAlertRules, sortBy string, desc bool) {
+	key := alertRuleSortKeys[sortBy]
+	if key == nil {
+		return
+	}
+	slices.SortStableFunc(rules, func(a, b *models.ProvisionedAlertRule) int {
+		c := cmp.Or(cmp.Compare(key(a), key(b)), cmp.Compare(a.UID, b.UID))
+		if desc {
+			return -c
+		}
+		return c
+	})
+}
+
+// filterAlertRulesByFolder returns only the alert rules in the folder with
+// the given UID. If `folderUID` is an empty string no filtering is done.
+func filterAlertRulesByFolder(rules models.ProvisionedAlertRules, folderUID string) models.ProvisionedAlertRules {
+	if folderUID == "" {
+		return rules
+	}
+
+	filteredResult := models.ProvisionedAlertRules{}
+	for _, rule := range rules {
+		if rule == nil || rule.FolderUID == nil {
+			continue
+		}
+		if *rule.FolderUID == folderUID {
+			filteredResult = append(filteredResult, rule)
+		}
+	}
+	return filteredResult
+}
+
+// filterAlertRulesByState returns only the alert rules whose current state, as
+// looked up by UID in `states`, is equal to `state`.
+func filterAlertRulesByState(rules models.ProvisionedAlertRules, states map[string]string, state string) models.ProvisionedAlertRules {
+	filteredResult := models.ProvisionedAlertRules{}
+	for _, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		if states[rule.UID] == state {
+			filteredResult = append(filteredResult, rule)
+		}
+	}
+	return filteredResult
+}
+
+// filterAlertRules filters a list of alert rules based on label selectors,
+// keeping the rules which match all of them, or any of them if matchAny is
+// set.
+func filterAlertRules(rules models.ProvisionedAlertRules, selectors []Selector, matchAny bool) (models.ProvisionedAlertRules, error) {
 	if len(selectors) == 0 {
 		return rules, nil
 	}
 
+	matches := matchesSelectors
+	if matchAny {
+		matches = matchesAnySelector
+	}
 	filteredResult := models.ProvisionedAlertRules{}
 	for _, rule := range rules {
 		if rule == nil {
 			continue
 		}
 
-		match, err := matchesSelectors(*rule, selectors)
+		match, err := matches(rule.Labels, selectors)
 		if err != nil {
 			return nil, fmt.Errorf("filtering alert rules: %w", err)
 		}
@@ -87,9 +214,10 @@ func filterAlertRules(rules models.ProvisionedAlertRules, selectors []Selector)
 	return filteredResult, nil
 }
 
-// matchesSelectors checks if an alert rule matches all provided selectors
-func matchesSelectors(rule models.ProvisionedAlertRule, selectors []Selector) (bool, error) {
-	promLabels := labels.FromMap(rule.Labels)
+// matchesSelectors checks if a set of labels, such as those of an alert rule
+// or alert, matches all provided selectors
+func matchesSelectors(lbls map[string]string, selectors []Selector) (bool, error) {
+	promLabels := labels.FromMap(lbls)
 
 	for _, selector := range selectors {
 		match, err := selector.Matches(promLabels)
@@ -103,6 +231,23 @@ func matchesSelectors(rule models.ProvisionedAlertRule, selectors []Selector) (b
 	return true, nil
 }
 
+// matchesAnySelector checks if a set of labels matches at least one of the
+// provided selectors.
+func matchesAnySelector(lbls map[string]string, selectors []Selector) (bool, error) {
+	promLabels := labels.FromMap(lbls)
+
+	for _, selector := range selectors {
+		match, err := selector.Matches(promLabels)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func summarizeAlertRules(alertRules models.ProvisionedAlertRules) []alertRuleSummary {
 	result := make([]alertRuleSummary, 0, len(alertRules))
 	for _, r := range alertRules {
@@ -111,37 +256,24 @@ func summarizeAlertRules(alertRules models.ProvisionedAlertRules) []alertRuleSum
 			title = *r.Title
 		}
 
-		result = append(result, alertRuleSummary{
-			UID:    r.UID,
-			Title:  title,
-			Labels: r.Labels,
-		})
+		summary := alertRuleSummary{
+			UID:         r.UID,
+			Title:       title,
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+			IsPaused:    r.IsPaused,
+		}
+		if r.For != nil {
+			summary.For = r.For.String()
+		}
+		if r.FolderUID != nil {
+			summary.FolderUID = *r.FolderUID
+		}
+		result = append(result, summary)
 	}
 	return result
 }
 
-// applyPagination applies pagination to the list of alert rules.
-// It doesn't sort the items and relies on the order returned by the API.
-func applyPagination(items models.ProvisionedAlertRules, limit, page int) (models.ProvisionedAlertRules, error) {
-	if limit == 0 {
-		limit = DefaultListAlertRulesLimit
-	}
-	if page == 0 {
-		page = 1
-	}
-
-	start := (page - 1) * limit
-	end := start + limit
-
-	if start >= len(items) {
-		return models.ProvisionedAlertRules{}, nil
-	} else if end > len(items) {
-		return items[start:], nil
-	}
-
-	return items[start:end], nil
-}
-
 var ListAlertRules = mcpgrafana.MustTool(
 	"list_alert_rules",
 	"List alert rules",
@@ -165,11 +297,14 @@ func getAlertRuleByUID(ctx context.Context, args GetAlertRuleByUIDParams) (*mode
 		return nil, fmt.Errorf("get alert rule by uid: %w", err)
 	}
 
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
-	alertRule, err := c.Provisioning.GetAlertRule(args.UID)
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get alert rule by uid %s: %w", args.UID, err)
 	}
+	alertRule, err := c.Provisioning.GetAlertRule(args.UID)
+	if err != nil {
+		return nil, fmt.Errorf("get alert rule by uid %s: %w", args.UID, grafanaAPIError(err))
+	}
 	return alertRule.Payload, nil
 }
 
@@ -179,7 +314,668 @@ var GetAlertRuleByUID = mcpgrafana.MustTool(
 	getAlertRuleByUID,
 )
 
-func AddAlertingTools(mcp *server.MCPServer) {
-	ListAlertRules.Register(mcp)
-	GetAlertRuleByUID.Register(mcp)
+type ExportAlertRulesParams struct {
+	FolderUID string `json:"folder_uid,omitempty" jsonschema:"description=Optionally\\, the UID of the folder to export the alert rules of. Defaults to all alert rules"`
+	Format    string `json:"format,omitempty" jsonschema:"description=Optionally\\, the format of the exported file. Defaults to 'yaml',enum=yaml,enum=json"`
+}
+
+func (p ExportAlertRulesParams) validate() error {
+	if p.Format != "" && p.Format != "yaml" && p.Format != "json" {
+		return fmt.Errorf("invalid format: %s, must be 'yaml' or 'json'", p.Format)
+	}
+	return nil
+}
+
+// exportAlertRules returns the alert rules in Grafana's file provisioning
+// format, as is, so that it can be committed to a repository.
+func exportAlertRules(ctx context.Context, args ExportAlertRulesParams) (string, error) {
+	if err := args.validate(); err != nil {
+		return "", mcpgrafana.NewToolError(fmt.Errorf("export alert rules: %w", err))
+	}
+
+	params := url.Values{}
+	params.Set("format", cmp.Or(args.Format, "yaml"))
+	if args.FolderUID != "" {
+		params.Set("folderUid", args.FolderUID)
+	}
+	c, err := newAlertingClientFromContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("export alert rules: %w", err)
+	}
+	content, err := c.makeRawRequest(ctx, http.MethodGet, "/api/v1/provisioning/alert-rules/export", params, nil)
+	if err != nil {
+		return "", fmt.Errorf("export alert rules: %w", err)
+	}
+	return string(content), nil
+}
+
+var ExportAlertRules = mcpgrafana.MustTool(
+	"export_alert_rules",
+	"Export alert rules, all of them or those in a folder, as a Grafana file provisioning document in YAML or JSON. The result can be saved to a file to provision the rules in another Grafana instance or kept in version control",
+	exportAlertRules,
+)
+
+type TestAlertRuleParams struct {
+	UID         string           `json:"uid,omitempty" jsonschema:"description=The UID of an existing alert rule to test. Either this or condition and data are required"`
+	Condition   string           `json:"condition,omitempty" jsonschema:"description=The refId of the query or expression which is the rule's condition"`
+	Data        []map[string]any `json:"data,omitempty" jsonschema:"description=The rule's queries and expressions\\, in the same format as the 'data' of a rule returned by get_alert_rule_by_uid: each with a refId\\, datasourceUid\\, relativeTimeRange and model"`
+	TimeRFC3339 string           `json:"timeRfc3339,omitempty" jsonschema:"description=Optionally\\, the time to evaluate the rule at in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+}
+
+func (p TestAlertRuleParams) validate() error {
+	if p.UID != "" && (p.Condition != "" || len(p.Data) > 0) {
+		return fmt.Errorf("give either uid or condition and data, not both")
+	}
+	if p.UID == "" && (p.Condition == "" || len(p.Data) == 0) {
+		return fmt.Errorf("either uid or condition and data are required")
+	}
+	return nil
+}
+
+type alertRuleQueryResult struct {
+	RefID  string         `json:"refId"`
+	Error  string         `json:"error,omitempty"`
+	Frames []frameSummary `json:"frames"`
+}
+
+type alertRuleTestResult struct {
+	// Firing is whether the condition is true for any series, in which
+	// case the rule would fire once its pending period has passed.
+	Firing bool `json:"firing"`
+	// FiringSeries holds the labels of each series the condition is true
+	// for, each of which would be a separate alert.
+	FiringSeries []map[string]string `json:"firingSeries"`
+	// NoData is set if the condition returned no series, in which case the
+	// rule's no data state applies.
+	NoData bool `json:"noData,omitempty"`
+	// Error is the error evaluating the condition, if any, in which case
+	// the rule's error state applies.
+	Error string `json:"error,omitempty"`
+	// Results holds the evaluated values of every query and expression.
+	Results []alertRuleQueryResult `json:"results"`
+}
+
+// evaluateAlertCondition sets whether the rule would fire from the frames
+// returned by its condition, which hold a number for each series. As in
+// Grafana, the condition is true for a series if its last value is
+// non-zero.
+func evaluateAlertCondition(result *alertRuleTestResult, frames []dataFrame) {
+	result.NoData = true
+	for _, frame := range frames {
+		for i, field := range frame.Schema.Fields {
+			if field.Type != "number" || i >= len(frame.Data.Values) {
+				continue
+			}
+			values := frame.Data.Values[i]
+			if len(values) == 0 {
+				continue
+			}
+			result.NoData = false
+			if v, ok := values[len(values)-1].(float64); ok && v != 0 {
+				result.Firing = true
+				lbls := field.Labels
+				if lbls == nil {
+					lbls = map[string]string{}
+				}
+				result.FiringSeries = append(result.FiringSeries, lbls)
+			}
+		}
+	}
+}
+
+// testAlertRule evaluates the queries and expressions of a rule once using
+// Grafana's /api/v1/eval endpoint, which is what the rule editor's preview
+// uses, without saving the rule.
+func testAlertRule(ctx context.Context, args TestAlertRuleParams) (*alertRuleTestResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("test alert rule: %w", err))
+	}
+	now := time.Now()
+	if args.TimeRFC3339 != "" {
+		var err error
+		if now, err = parseTime(args.TimeRFC3339, now, false); err != nil {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("test alert rule: parsing time: %w", err))
+		}
+	}
+
+	condition, data := args.Condition, any(args.Data)
+	uids := make([]string, 0, len(args.Data))
+	for _, query := range args.Data {
+		uids = append(uids, stringField(query, "datasourceUid"))
+	}
+	if args.UID != "" {
+		rule, err := getAlertRuleByUID(ctx, GetAlertRuleByUIDParams{UID: args.UID})
+		if err != nil {
+			return nil, fmt.Errorf("test alert rule: %w", err)
+		}
+		if rule.Condition != nil {
+			condition = *rule.Condition
+		}
+		data = rule.Data
+		uids = uids[:0]
+		for _, query := range rule.Data {
+			uids = append(uids, query.DatasourceUID)
+		}
+	}
+	if err := checkReadOnlyDatasources(ctx, uids); err != nil {
+		return nil, fmt.Errorf("test alert rule: %w", err)
+	}
+
+	c, err := newAlertingClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("test alert rule: %w", err)
+	}
+	var response queryDataResponse
+	body := map[string]any{"data": data, "now": now}
+	if err := c.makeRequest(ctx, http.MethodPost, "/api/v1/eval", nil, body, &response); err != nil {
+		// Grafana rejects invalid queries and expressions with 400 Bad
+		// Request, explaining why.
+		var sc statusCoder
+		if errors.As(err, &sc) && sc.IsCode(http.StatusBadRequest) {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("test alert rule: %w", err))
+		}
+		return nil, fmt.Errorf("test alert rule: %w", err)
+	}
+
+	result := &alertRuleTestResult{
+		FiringSeries: []map[string]string{},
+		Results:      make([]alertRuleQueryResult, 0, len(response.Results)),
+	}
+	for _, refID := range slices.Sorted(maps.Keys(response.Results)) {
+		res := response.Results[refID]
+		r := alertRuleQueryResult{RefID: refID, Error: res.Error, Frames: []frameSummary{}}
+		for _, frame := range res.Frames {
+			r.Frames = append(r.Frames, summarizeFrame(frame, DefaultQueryDatasourceMaxRows))
+		}
+		result.Results = append(result.Results, r)
+	}
+	res, ok := response.Results[condition]
+	switch {
+	case !ok:
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("test alert rule: the condition %q is not the refId of any query or expression", condition))
+	case res.Error != "":
+		result.Error = res.Error
+	default:
+		evaluateAlertCondition(result, res.Frames)
+	}
+	return result, nil
+}
+
+var TestAlertRule = mcpgrafana.MustTool(
+	"test_alert_rule",
+	"Test an alert rule without saving it, by evaluating its queries and expressions once, as the rule editor's preview does. Give either the UID of an existing rule or a condition and data for a new one. Returns whether the rule would fire and for which series, along with the evaluated values of each query and expression and any errors. The rule's pending period isn't taken into account",
+	testAlertRule,
+)
+
+type ListSilencesParams struct{}
+
+type silenceSummary struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"`
+	Matchers  []LabelMatcher `json:"matchers"`
+	StartsAt  string         `json:"startsAt"`
+	EndsAt    string         `json:"endsAt"`
+	CreatedBy string         `json:"createdBy"`
+	Comment   string         `json:"comment"`
+}
+
+func listSilences(ctx context.Context, args ListSilencesParams) ([]silenceSummary, error) {
+	c, err := newAlertingClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list silences: %w", err)
+	}
+
+	var silences []*models.GettableSilence
+	if err := c.makeRequest(ctx, http.MethodGet, alertmanagerAPIPath+"/silences", nil, nil, &silences); err != nil {
+		return nil, fmt.Errorf("list silences: %w", err)
+	}
+
+	return summarizeSilences(silences), nil
+}
+
+// summarizeSilences converts silences into summaries. Expired silences are
+// kept by Alertmanager for a while after they end, so they are included with
+// their status rather than being reported as active.
+func summarizeSilences(silences []*models.GettableSilence) []silenceSummary {
+	result := make([]silenceSummary, 0, len(silences))
+	for _, s := range silences {
+		if s == nil {
+			continue
+		}
+		summary := silenceSummary{
+			Matchers: fromAlertmanagerMatchers(s.Matchers),
+		}
+		if s.Status != nil && s.Status.State != nil {
+			summary.Status = *s.Status.State
+		}
+		if s.ID != nil {
+			summary.ID = *s.ID
+		}
+		if s.StartsAt != nil {
+			summary.StartsAt = s.StartsAt.String()
+		}
+		if s.EndsAt != nil {
+			summary.EndsAt = s.EndsAt.String()
+		}
+		if s.CreatedBy != nil {
+			summary.CreatedBy = *s.CreatedBy
+		}
+		if s.Comment != nil {
+			summary.Comment = *s.Comment
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+type ListActiveAlertsParams struct {
+	LabelSelectors []Selector `json:"labelSelectors,omitempty" jsonschema:"description=Optionally\\, a list of selectors to filter the alerts by their labels"`
+	Limit          int        `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of alerts to return. Defaults to 100"`
+}
+
+func (p ListActiveAlertsParams) validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	return nil
+}
+
+type activeAlertSummary struct {
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// State is "active", or "suppressed" if the alert is silenced or
+	// inhibited.
+	State        string   `json:"state"`
+	ActiveSince  string   `json:"activeSince"`
+	SilencedBy   []string `json:"silencedBy,omitempty"`
+	InhibitedBy  []string `json:"inhibitedBy,omitempty"`
+	GeneratorURL string   `json:"generatorURL,omitempty"`
+}
+
+type listActiveAlertsResult struct {
+	Alerts []activeAlertSummary `json:"alerts"`
+	// TotalCount is the number of matching alerts, which may be more than
+	// were returned.
+	TotalCount int `json:"totalCount"`
+}
+
+func listActiveAlerts(ctx context.Context, args ListActiveAlertsParams) (*listActiveAlertsResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list active alerts: %w", err))
+	}
+	c, err := newAlertingClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list active alerts: %w", err)
+	}
+
+	var alerts []*models.GettableAlert
+	params := url.Values{"active": {"true"}}
+	if err := c.makeRequest(ctx, http.MethodGet, alertmanagerAPIPath+"/alerts", params, nil, &alerts); err != nil {
+		return nil, fmt.Errorf("list active alerts: %w", err)
+	}
+
+	summaries, err := summarizeActiveAlerts(alerts, args.LabelSelectors)
+	if err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list active alerts: %w", err))
+	}
+	result := &listActiveAlertsResult{Alerts: summaries, TotalCount: len(summaries)}
+	limit := args.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	if len(result.Alerts) > limit {
+		result.Alerts = result.Alerts[:limit]
+	}
+	return result, nil
+}
+
+// summarizeActiveAlerts converts the alerts which match the selectors into
+// summaries, with the longest-firing alerts first.
+func summarizeActiveAlerts(alerts []*models.GettableAlert, selectors []Selector) ([]activeAlertSummary, error) {
+	matching := make([]*models.GettableAlert, 0, len(alerts))
+	for _, a := range alerts {
+		if a == nil {
+			continue
+		}
+		match, err := matchesSelectors(a.Labels, selectors)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			matching = append(matching, a)
+		}
+	}
+	startsAt := func(a *models.GettableAlert) time.Time {
+		if a.StartsAt == nil {
+			return time.Time{}
+		}
+		return time.Time(*a.StartsAt)
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return startsAt(matching[i]).Before(startsAt(matching[j])) })
+
+	result := make([]activeAlertSummary, 0, len(matching))
+	for _, a := range matching {
+		summary := activeAlertSummary{
+			Labels:       a.Labels,
+			GeneratorURL: a.GeneratorURL.String(),
+		}
+		if len(a.Annotations) > 0 {
+			summary.Annotations = a.Annotations
+		}
+		if a.Fingerprint != nil {
+			summary.Fingerprint = *a.Fingerprint
+		}
+		if a.StartsAt != nil {
+			summary.ActiveSince = a.StartsAt.String()
+		}
+		if a.Status != nil {
+			if a.Status.State != nil {
+				summary.State = *a.Status.State
+			}
+			summary.SilencedBy = a.Status.SilencedBy
+			summary.InhibitedBy = a.Status.InhibitedBy
+		}
+		result = append(result, summary)
+	}
+	return result, nil
+}
+
+var ListActiveAlerts = mcpgrafana.MustTool(
+	"list_active_alerts",
+	"List the alerts which are currently firing in Grafana's Alertmanager, i.e. the alert instances rather than the rules. Optionally filter by label selectors. Returns each alert's labels, annotations (such as its summary and description), state ('active', or 'suppressed' if silenced or inhibited, with the IDs of the silences) and the time it has been active since, longest-firing first",
+	listActiveAlerts,
+)
+
+// toAlertmanagerMatchers converts label matchers into the matcher format
+// expected by the Alertmanager API.
+func toAlertmanagerMatchers(matchers []LabelMatcher) (models.Matchers, error) {
+	result := make(models.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		var isEqual, isRegex bool
+		switch m.Type {
+		case "", "=":
+			isEqual, isRegex = true, false
+		case "!=":
+			isEqual, isRegex = false, false
+		case "=~":
+			isEqual, isRegex = true, true
+		case "!~":
+			isEqual, isRegex = false, true
+		default:
+			return nil, fmt.Errorf("invalid matcher type: %s", m.Type)
+		}
+		result = append(result, &models.Matcher{
+			Name:    &m.Name,
+			Value:   &m.Value,
+			IsEqual: isEqual,
+			IsRegex: &isRegex,
+		})
+	}
+	return result, nil
+}
+
+// fromAlertmanagerMatchers converts Alertmanager matchers into label matchers.
+func fromAlertmanagerMatchers(matchers models.Matchers) []LabelMatcher {
+	result := make([]LabelMatcher, 0, len(matchers))
+	for _, m := range matchers {
+		if m == nil || m.Name == nil || m.Value == nil {
+			continue
+		}
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		matchType := "="
+		switch {
+		case m.IsEqual && isRegex:
+			matchType = "=~"
+		case !m.IsEqual && isRegex:
+			matchType = "!~"
+		case !m.IsEqual:
+			matchType = "!="
+		}
+		result = append(result, LabelMatcher{
+			Name:  *m.Name,
+			Value: *m.Value,
+			Type:  matchType,
+		})
+	}
+	return result
+}
+
+var ListSilences = mcpgrafana.MustTool(
+	"list_silences",
+	"List silences in the Grafana Alertmanager, including their status (active, pending or expired)",
+	listSilences,
+)
+
+type CreateSilenceParams struct {
+	Matchers     []LabelMatcher `json:"matchers" jsonschema:"required,description=The label matchers that alerts must match to be silenced"`
+	StartRFC3339 string         `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the silence in RFC3339 format (defaults to now)"`
+	EndRFC3339   string         `json:"endRfc3339" jsonschema:"required,description=The end time of the silence in RFC3339 format"`
+	Comment      string         `json:"comment" jsonschema:"required,description=A comment describing why the silence was created"`
+	CreatedBy    string         `json:"createdBy" jsonschema:"required,description=The name of the person creating the silence"`
+}
+
+func (p CreateSilenceParams) validate() error {
+	if len(p.Matchers) == 0 {
+		return fmt.Errorf("at least one matcher is required")
+	}
+	if p.EndRFC3339 == "" {
+		return fmt.Errorf("endRfc3339 is required")
+	}
+	if p.Comment == "" {
+		return fmt.Errorf("comment is required")
+	}
+	if p.CreatedBy == "" {
+		return fmt.Errorf("createdBy is required")
+	}
+	return nil
+}
+
+// postableSilence is the request body for creating a silence.
+type postableSilence struct {
+	Matchers  models.Matchers `json:"matchers"`
+	StartsAt  strfmt.DateTime `json:"startsAt"`
+	EndsAt    strfmt.DateTime `json:"endsAt"`
+	Comment   string          `json:"comment"`
+	CreatedBy string          `json:"createdBy"`
+}
+
+type createSilenceResult struct {
+	SilenceID string `json:"silenceID"`
+}
+
+func createSilence(ctx context.Context, args CreateSilenceParams) (*createSilenceResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("create silence: %w", err)
+	}
+
+	startTime := time.Now()
+	if args.StartRFC3339 != "" {
+		var err error
+		if startTime, err = time.Parse(time.RFC3339, args.StartRFC3339); err != nil {
+			return nil, fmt.Errorf("create silence: parsing start time: %w", err)
+		}
+	}
+	endTime, err := time.Parse(time.RFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, fmt.Errorf("create silence: parsing end time: %w", err)
+	}
+	if !endTime.After(startTime) {
+		return nil, fmt.Errorf("create silence: end time must be after start time")
+	}
+
+	matchers, err := toAlertmanagerMatchers(args.Matchers)
+	if err != nil {
+		return nil, fmt.Errorf("create silence: %w", err)
+	}
+
+	c, err := newAlertingClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create silence: %w", err)
+	}
+
+	var result createSilenceResult
+	err = c.makeRequest(ctx, http.MethodPost, alertmanagerAPIPath+"/silences", nil, postableSilence{
+		Matchers:  matchers,
+		StartsAt:  strfmt.DateTime(startTime),
+		EndsAt:    strfmt.DateTime(endTime),
+		Comment:   args.Comment,
+		CreatedBy: args.CreatedBy,
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("create silence: %w", err)
+	}
+	return &result, nil
+}
+
+var CreateSilence = mcpgrafana.MustTool(
+	"create_silence",
+	"Create a silence in the Grafana Alertmanager for alerts matching the given label matchers. Returns the ID of the new silence",
+	createSilence,
+).AsMutating()
+
+type DeleteSilenceParams struct {
+	ID string `json:"id" jsonschema:"required,description=The ID of the silence to delete"`
+}
+
+func (p DeleteSilenceParams) validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	return nil
+}
+
+func deleteSilence(ctx context.Context, args DeleteSilenceParams) (string, error) {
+	if err := args.validate(); err != nil {
+		return "", fmt.Errorf("delete silence: %w", err)
+	}
+
+	c, err := newAlertingClientFromContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("delete silence: %w", err)
+	}
+
+	urlPath := fmt.Sprintf("%s/silence/%s", alertmanagerAPIPath, url.PathEscape(args.ID))
+	if err := c.makeRequest(ctx, http.MethodDelete, urlPath, nil, nil, nil); err != nil {
+		return "", fmt.Errorf("delete silence %s: %w", args.ID, err)
+	}
+	return fmt.Sprintf("Silence %s deleted", args.ID), nil
+}
+
+var DeleteSilence = mcpgrafana.MustTool(
+	"delete_silence",
+	"Delete (expire) a silence in the Grafana Alertmanager by ID",
+	deleteSilence,
+).AsMutating()
+
+type ListMuteTimingsParams struct{}
+
+func listMuteTimings(ctx context.Context, args ListMuteTimingsParams) (models.MuteTimings, error) {
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list mute timings: %w", err)
+	}
+	response, err := c.Provisioning.GetMuteTimings()
+	if err != nil {
+		return nil, fmt.Errorf("list mute timings: %w", grafanaAPIError(err))
+	}
+	return response.Payload, nil
+}
+
+var ListMuteTimings = mcpgrafana.MustTool(
+	"list_mute_timings",
+	"List mute timings. Mute timings are referenced by notification policies to suppress notifications during recurring periods, such as outside business hours",
+	listMuteTimings,
+)
+
+type TimeRange struct {
+	StartTime string `json:"startTime" jsonschema:"required,description=The start time in 24-hour HH:MM format (e.g. '09:00')"`
+	EndTime   string `json:"endTime" jsonschema:"required,description=The end time in 24-hour HH:MM format (e.g. '17:00')"`
+}
+
+type TimeInterval struct {
+	Times       []TimeRange `json:"times,omitempty" jsonschema:"description=Optionally\\, the ranges of time of day the interval covers"`
+	Weekdays    []string    `json:"weekdays,omitempty" jsonschema:"description=Optionally\\, the days of the week or ranges of days the interval covers (e.g. 'monday' or 'monday:friday')"`
+	DaysOfMonth []string    `json:"daysOfMonth,omitempty" jsonschema:"description=Optionally\\, the days of the month or ranges of days the interval covers (e.g. '1' or '1:5'). Negative values count from the end of the month"`
+	Months      []string    `json:"months,omitempty" jsonschema:"description=Optionally\\, the months or ranges of months the interval covers (e.g. 'january' or '1:3')"`
+	Years       []string    `json:"years,omitempty" jsonschema:"description=Optionally\\, the years or ranges of years the interval covers (e.g. '2025' or '2025:2026')"`
+	Location    string      `json:"location,omitempty" jsonschema:"description=Optionally\\, the IANA time zone name the interval is evaluated in (defaults to UTC)"`
+}
+
+type CreateMuteTimingParams struct {
+	Name          string         `json:"name" jsonschema:"required,description=The name of the mute timing"`
+	TimeIntervals []TimeInterval `json:"timeIntervals" jsonschema:"required,description=The time intervals during which notifications are muted"`
+}
+
+func (p CreateMuteTimingParams) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.TimeIntervals) == 0 {
+		return fmt.Errorf("at least one time interval is required")
+	}
+	return nil
+}
+
+func toTimeIntervalItems(intervals []TimeInterval) []*models.TimeIntervalItem {
+	result := make([]*models.TimeIntervalItem, 0, len(intervals))
+	for _, interval := range intervals {
+		times := make([]*models.TimeIntervalTimeRange, 0, len(interval.Times))
+		for _, t := range interval.Times {
+			times = append(times, &models.TimeIntervalTimeRange{
+				StartTime: t.StartTime,
+				EndTime:   t.EndTime,
+			})
+		}
+		result = append(result, &models.TimeIntervalItem{
+			Times:       times,
+			Weekdays:    interval.Weekdays,
+			DaysOfMonth: interval.DaysOfMonth,
+			Months:      interval.Months,
+			Years:       interval.Years,
+			Location:    interval.Location,
+		})
+	}
+	return result
+}
+
+func createMuteTiming(ctx context.Context, args CreateMuteTimingParams) (*models.MuteTimeInterval, error) {
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("create mute timing: %w", err)
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create mute timing %s: %w", args.Name, err)
+	}
+	params := provisioning.NewPostMuteTimingParamsWithContext(ctx).WithBody(&models.MuteTimeInterval{
+		Name:          args.Name,
+		TimeIntervals: toTimeIntervalItems(args.TimeIntervals),
+	})
+	response, err := c.Provisioning.PostMuteTiming(params)
+	if err != nil {
+		return nil, fmt.Errorf("create mute timing %s: %w", args.Name, grafanaAPIError(err))
+	}
+	return response.Payload, nil
+}
+
+var CreateMuteTiming = mcpgrafana.MustTool(
+	"create_mute_timing",
+	"Create a mute timing from a list of time intervals. Returns the created mute timing",
+	createMuteTiming,
+).AsMutating()
+
+func AddAlertingTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "alerting",
+		ListAlertRules,
+		GetAlertRuleByUID,
+		ExportAlertRules,
+		TestAlertRule,
+		ListActiveAlerts,
+		ListSilences,
+		CreateSilence,
+		DeleteSilence,
+		ListMuteTimings,
+		CreateMuteTiming,
+	)
 }
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+const (
+	// alertmanagerAPIPath is the path of the Grafana-managed Alertmanager API.
+	alertmanagerAPIPath = "/api/alertmanager/grafana/api/v2"
+)
+
+// alertingClient is a minimal HTTP client for the Grafana alerting endpoints
+// which are not covered by the openapi client, such as the Alertmanager API.
+type alertingClient struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func newAlertingClientFromContext(ctx context.Context) (*alertingClient, error) {
+	grafanaURL := mcpgrafana.GrafanaURLFromContext(ctx)
+	if _, err := url.Parse(grafanaURL); err != nil {
+		return nil, fmt.Errorf("invalid Grafana URL %s: %w", grafanaURL, err)
+	}
+
+	client := &http.Client{
+		Transport: newAuthRoundTripper(ctx, newTransport(ctx)),
+	}
+
+	return &alertingClient{
+		httpClient: client,
+		baseURL:    strings.TrimRight(grafanaURL, "/"),
+	}, nil
+}
+
+// makeRequest sends a request to the Grafana API at the given path, encoding
+// `body` as JSON if it is non-nil and decoding the response into `result` if
+// it is non-nil.
+func (c *alertingClient) makeRequest(ctx context.Context, method, urlPath string, params url.Values, body, result any) error {
+	bodyBytes, err := c.makeRawRequest(ctx, method, urlPath, params, body)
+	if err != nil {
+		return err
+	}
+	if result == nil || len(bytes.TrimSpace(bodyBytes)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(bodyBytes, result); err != nil {
+		return fmt.Errorf("unmarshalling response (content: %s): %w", string(bodyBytes), err)
+	}
+	return nil
+}
+
+// makeRawRequest is like makeRequest, but returns the body of the response
+// as is, for endpoints which don't return JSON.
+func (c *alertingClient) makeRawRequest(ctx context.Context, method, urlPath string, params url.Values, body any) ([]byte, error) {
+	u, err := url.Parse(c.baseURL + urlPath)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+	if params != nil {
+		u.RawQuery = params.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body with a limit to prevent memory issues
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024*48))
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, grafanaStatusError(resp.StatusCode, &alertingStatusError{statusCode: resp.StatusCode, body: string(bodyBytes)})
+	}
+	return bodyBytes, nil
+}
+
+// alertingStatusError is the error for a non-2xx response from the Grafana
+// API. It implements statusCoder, so callers can check the status.
+type alertingStatusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *alertingStatusError) Error() string {
+	return fmt.Sprintf("Grafana API returned status code %d: %s", e.statusCode, e.body)
+}
+
+func (e *alertingStatusError) IsCode(code int) bool {
+	return e.statusCode == code
+}
+
+// rulesResponse is the response of the Prometheus-compatible rules API for
+// Grafana-managed alert rules.
+type rulesResponse struct {
+	Data struct {
+		RuleGroups []struct {
+			Rules []struct {
+				UID    string `json:"uid"`
+				State  string `json:"state"`
+				Health string `json:"health"`
+			} `json:"rules"`
+		} `json:"groups"`
+	} `json:"data"`
+}
+
+// getRuleStates fetches the current evaluation state of all Grafana-managed
+// alert rules, keyed by rule UID. The state is one of "firing", "pending",
+// "normal" or "error".
+func (c *alertingClient) getRuleStates(ctx context.Context) (map[string]string, error) {
+	var resp rulesResponse
+	if err := c.makeRequest(ctx, http.MethodGet, "/api/prometheus/grafana/api/v1/rules", nil, nil, &resp); err != nil {
+		return nil, fmt.Errorf("fetching alert rule states: %w", err)
+	}
+
+	states := make(map[string]string)
+	for _, group := range resp.Data.RuleGroups {
+		for _, rule := range group.Rules {
+			states[rule.UID] = normalizeRuleState(rule.State, rule.Health)
+		}
+	}
+	return states, nil
+}
+
+// normalizeRuleState maps the Prometheus-style state and health of a rule to
+// a single state.
+func normalizeRuleState(state, health string) string {
+	if strings.ToLower(health) == "error" {
+		return "error"
+	}
+	switch s := strings.ToLower(state); s {
+	case "inactive", "":
+		return "normal"
+	default:
+		return s
+	}
+}
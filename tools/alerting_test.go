@@ -6,8 +6,13 @@
 package tools
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	mcpgrafana "github.com/grafana/mcp-grafana"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,27 +46,54 @@ var (
 		UID:    rule1UID,
 		Title:  rule1Title,
 		Labels: rule1Labels,
+		Annotations: map[string]string{
+			"description": "This is a test alert rule that is always firing",
+		},
+		For: "1m0s",
 	}
 	rule2 = alertRuleSummary{
 		UID:    rule2UID,
 		Title:  rule2Title,
 		Labels: rule2Labels,
+		Annotations: map[string]string{
+			"description": "This is a test alert rule that is always normal",
+		},
+		For: "1m0s",
 	}
 	rulePaused = alertRuleSummary{
 		UID:    rulePausedUID,
 		Title:  rulePausedTitle,
 		Labels: rule3Labels,
+		Annotations: map[string]string{
+			"description": "This is a paused alert rule",
+		},
+		For:      "1m0s",
+		IsPaused: true,
 	}
 	allExpectedRules = []alertRuleSummary{rule1, rule2, rulePaused}
 )
 
+// requireAlertRulesMatch asserts that the actual alert rule summaries match the
+// expected ones, ignoring order. The folder UID is generated by Grafana when the
+// rules are provisioned, so it is only checked to be set.
+func requireAlertRulesMatch(t *testing.T, expected, actual []alertRuleSummary) {
+	t.Helper()
+	withoutFolders := make([]alertRuleSummary, 0, len(actual))
+	for _, r := range actual {
+		require.NotEmpty(t, r.FolderUID)
+		r.FolderUID = ""
+		withoutFolders = append(withoutFolders, r)
+	}
+	require.ElementsMatch(t, expected, withoutFolders)
+}
+
 func TestAlertingTools_ListAlertRules(t *testing.T) {
 	t.Run("list alert rules", func(t *testing.T) {
 		ctx := newTestContext()
 		result, err := listAlertRules(ctx, ListAlertRulesParams{})
 		require.NoError(t, err)
 
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with pagination", func(t *testing.T) {
@@ -104,7 +136,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 		ctx := newTestContext()
 		result, err := listAlertRules(ctx, ListAlertRulesParams{})
 		require.NoError(t, err)
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with selectors that match", func(t *testing.T) {
@@ -123,7 +155,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with selectors that don't match", func(t *testing.T) {
@@ -170,7 +202,36 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, []alertRuleSummary{rule2}, result)
+		requireAlertRulesMatch(t, []alertRuleSummary{rule2}, result)
+	})
+
+	t.Run("list alert rules matching any selector", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			LabelSelectors: []Selector{
+				{
+					Filters: []LabelMatcher{
+						{
+							Name:  "rule",
+							Value: "first",
+							Type:  "=",
+						},
+					},
+				},
+				{
+					Filters: []LabelMatcher{
+						{
+							Name:  "rule",
+							Value: "second",
+							Type:  "=",
+						},
+					},
+				},
+			},
+			MatchMode: "any",
+		})
+		require.NoError(t, err)
+		requireAlertRulesMatch(t, []alertRuleSummary{rule1, rule2}, result)
 	})
 
 	t.Run("list alert rules with regex matcher", func(t *testing.T) {
@@ -189,7 +250,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, []alertRuleSummary{rule1}, result)
+		requireAlertRulesMatch(t, []alertRuleSummary{rule1}, result)
 	})
 
 	t.Run("list alert rules with selectors and pagination", func(t *testing.T) {
@@ -211,7 +272,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 		})
 		require.NoError(t, err)
 		require.Len(t, result, 1)
-		require.ElementsMatch(t, []alertRuleSummary{rule1}, result)
+		requireAlertRulesMatch(t, []alertRuleSummary{rule1}, result)
 
 		// Second page
 		result, err = listAlertRules(ctx, ListAlertRulesParams{
@@ -231,7 +292,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 		})
 		require.NoError(t, err)
 		require.Len(t, result, 1)
-		require.ElementsMatch(t, []alertRuleSummary{rule2}, result)
+		requireAlertRulesMatch(t, []alertRuleSummary{rule2}, result)
 	})
 
 	t.Run("list alert rules with not equals operator", func(t *testing.T) {
@@ -250,7 +311,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with not matches operator", func(t *testing.T) {
@@ -269,7 +330,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with non-existent label", func(t *testing.T) {
@@ -309,7 +370,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			},
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with a limit that is larger than the number of rules", func(t *testing.T) {
@@ -319,7 +380,7 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 			Page:  1,
 		})
 		require.NoError(t, err)
-		require.ElementsMatch(t, allExpectedRules, result)
+		requireAlertRulesMatch(t, allExpectedRules, result)
 	})
 
 	t.Run("list alert rules with a page that doesn't exist", func(t *testing.T) {
@@ -349,6 +410,125 @@ func TestAlertingTools_ListAlertRules(t *testing.T) {
 		require.Error(t, err)
 		require.Empty(t, result)
 	})
+
+	t.Run("list alert rules by folder", func(t *testing.T) {
+		ctx := newTestContext()
+		// The folder UID is generated when the folder is provisioned, so look it up.
+		rule, err := getAlertRuleByUID(ctx, GetAlertRuleByUIDParams{UID: rule1UID})
+		require.NoError(t, err)
+		require.NotNil(t, rule.FolderUID)
+
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			FolderUID: *rule.FolderUID,
+		})
+		require.NoError(t, err)
+		requireAlertRulesMatch(t, allExpectedRules, result)
+	})
+
+	t.Run("list alert rules by non-existent folder", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			FolderUID: "non-existent-folder-uid",
+		})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("list alert rules sorted by title", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listAlertRules(ctx, ListAlertRulesParams{SortBy: "title"})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, []string{rulePausedTitle, rule1Title, rule2Title}, []string{result[0].Title, result[1].Title, result[2].Title})
+
+		result, err = listAlertRules(ctx, ListAlertRulesParams{SortBy: "uid", SortDesc: true, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, rulePausedUID, result[0].UID)
+	})
+
+	t.Run("list alert rules with invalid sort", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := listAlertRules(ctx, ListAlertRulesParams{SortBy: "severity"})
+		require.Error(t, err)
+	})
+
+	t.Run("list alert rules by folder and selectors", func(t *testing.T) {
+		ctx := newTestContext()
+		rule, err := getAlertRuleByUID(ctx, GetAlertRuleByUIDParams{UID: rule1UID})
+		require.NoError(t, err)
+		require.NotNil(t, rule.FolderUID)
+
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			FolderUID: *rule.FolderUID,
+			LabelSelectors: []Selector{
+				{
+					Filters: []LabelMatcher{
+						{
+							Name:  "rule",
+							Value: "second",
+							Type:  "=",
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		requireAlertRulesMatch(t, []alertRuleSummary{rule2}, result)
+
+		// Selectors matching rules in another folder return nothing.
+		result, err = listAlertRules(ctx, ListAlertRulesParams{
+			FolderUID: "non-existent-folder-uid",
+			LabelSelectors: []Selector{
+				{
+					Filters: []LabelMatcher{
+						{
+							Name:  "rule",
+							Value: "second",
+							Type:  "=",
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+}
+
+func TestAlertingTools_ListAlertRulesByState(t *testing.T) {
+	t.Run("list alert rules in normal state", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			State: "normal",
+		})
+		require.NoError(t, err)
+		uids := make([]string, 0, len(result))
+		for _, r := range result {
+			uids = append(uids, r.UID)
+		}
+		// Rule 2 evaluates `vector(0)` so it never fires.
+		require.Contains(t, uids, rule2UID)
+		require.NotContains(t, uids, rule1UID)
+	})
+
+	t.Run("list alert rules in error state", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			State: "error",
+		})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("list alert rules with invalid state", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listAlertRules(ctx, ListAlertRulesParams{
+			State: "on-fire",
+		})
+		require.Error(t, err)
+		require.Empty(t, result)
+	})
 }
 
 func TestAlertingTools_GetAlertRuleByUID(t *testing.T) {
@@ -399,3 +579,153 @@ func TestAlertingTools_GetAlertRuleByUID(t *testing.T) {
 		require.Contains(t, err.Error(), "getAlertRuleNotFound")
 	})
 }
+
+func TestAlertingTools_ExportAlertRules(t *testing.T) {
+	t.Run("export alert rules as yaml", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := exportAlertRules(ctx, ExportAlertRulesParams{})
+		require.NoError(t, err)
+		require.Contains(t, result, "apiVersion: 1")
+		require.Contains(t, result, rule1Title)
+	})
+
+	t.Run("export alert rules as json", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := exportAlertRules(ctx, ExportAlertRulesParams{Format: "json"})
+		require.NoError(t, err)
+		require.True(t, json.Valid([]byte(result)))
+		require.Contains(t, result, rule1UID)
+	})
+}
+
+func TestAlertingTools_Silences(t *testing.T) {
+	t.Run("create, list and delete a silence", func(t *testing.T) {
+		ctx := newTestContext()
+		created, err := createSilence(ctx, CreateSilenceParams{
+			Matchers: []LabelMatcher{
+				{Name: "rule", Value: "first", Type: "="},
+			},
+			EndRFC3339: time.Now().Add(time.Hour).Format(time.RFC3339),
+			Comment:    "Silenced by integration tests",
+			CreatedBy:  "mcp-grafana",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.SilenceID)
+
+		silences, err := listSilences(ctx, ListSilencesParams{})
+		require.NoError(t, err)
+		var found *silenceSummary
+		for i := range silences {
+			if silences[i].ID == created.SilenceID {
+				found = &silences[i]
+			}
+		}
+		require.NotNil(t, found, "created silence should be listed")
+		require.Equal(t, "active", found.Status)
+		require.Equal(t, []LabelMatcher{{Name: "rule", Value: "first", Type: "="}}, found.Matchers)
+
+		_, err = deleteSilence(ctx, DeleteSilenceParams{ID: created.SilenceID})
+		require.NoError(t, err)
+
+		// Deleted silences are expired rather than removed.
+		silences, err = listSilences(ctx, ListSilencesParams{})
+		require.NoError(t, err)
+		for _, s := range silences {
+			if s.ID == created.SilenceID {
+				require.Equal(t, "expired", s.Status)
+			}
+		}
+	})
+
+	t.Run("create silence without matchers fails", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := createSilence(ctx, CreateSilenceParams{
+			EndRFC3339: time.Now().Add(time.Hour).Format(time.RFC3339),
+			Comment:    "Silenced by integration tests",
+			CreatedBy:  "mcp-grafana",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("create silence with end before start fails", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := createSilence(ctx, CreateSilenceParams{
+			Matchers: []LabelMatcher{
+				{Name: "rule", Value: "first", Type: "="},
+			},
+			EndRFC3339: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			Comment:    "Silenced by integration tests",
+			CreatedBy:  "mcp-grafana",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestAlertingTools_ListActiveAlerts(t *testing.T) {
+	t.Run("list active alerts by label", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listActiveAlerts(ctx, ListActiveAlertsParams{
+			LabelSelectors: []Selector{
+				{Filters: []LabelMatcher{{Name: "rule", Value: "first", Type: "="}}},
+			},
+		})
+		require.NoError(t, err)
+		// Rule 1 may still be pending, in which case it has no alerts yet.
+		for _, a := range result.Alerts {
+			require.Equal(t, "first", a.Labels["rule"])
+			require.NotEmpty(t, a.ActiveSince)
+		}
+	})
+
+	t.Run("list active alerts with no matches", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listActiveAlerts(ctx, ListActiveAlertsParams{
+			LabelSelectors: []Selector{
+				{Filters: []LabelMatcher{{Name: "rule", Value: "no-such-rule", Type: "="}}},
+			},
+		})
+		require.NoError(t, err)
+		require.Empty(t, result.Alerts)
+		require.Zero(t, result.TotalCount)
+	})
+}
+
+func TestAlertingTools_MuteTimings(t *testing.T) {
+	t.Run("create and list mute timings", func(t *testing.T) {
+		ctx := newTestContext()
+		name := fmt.Sprintf("mcp-test-mute-timing-%d", time.Now().UnixNano())
+		created, err := createMuteTiming(ctx, CreateMuteTimingParams{
+			Name: name,
+			TimeIntervals: []TimeInterval{
+				{
+					Times:    []TimeRange{{StartTime: "18:00", EndTime: "23:59"}},
+					Weekdays: []string{"monday:friday"},
+				},
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			c := mcpgrafana.GrafanaClientFromContext(ctx)
+			_, _ = c.Provisioning.DeleteMuteTiming(provisioning.NewDeleteMuteTimingParams().WithName(name))
+		})
+		require.Equal(t, name, created.Name)
+		require.Len(t, created.TimeIntervals, 1)
+		require.Equal(t, []string{"monday:friday"}, created.TimeIntervals[0].Weekdays)
+
+		result, err := listMuteTimings(ctx, ListMuteTimingsParams{})
+		require.NoError(t, err)
+		names := make([]string, 0, len(result))
+		for _, mt := range result {
+			names = append(names, mt.Name)
+		}
+		require.Contains(t, names, name)
+	})
+
+	t.Run("create mute timing without intervals fails", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := createMuteTiming(ctx, CreateMuteTimingParams{
+			Name: "mcp-test-mute-timing-invalid",
+		})
+		require.Error(t, err)
+	})
+}
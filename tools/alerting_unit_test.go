@@ -0,0 +1,270 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeActiveAlerts(t *testing.T) {
+	alert := func(name string, startsAt time.Time, state string, silencedBy ...string) *models.GettableAlert {
+		fingerprint := name + "-fp"
+		ts := strfmt.DateTime(startsAt)
+		return &models.GettableAlert{
+			Fingerprint: &fingerprint,
+			Labels:      models.LabelSet{"alertname": name, "team": "a"},
+			Annotations: models.LabelSet{"summary": name + " is firing"},
+			StartsAt:    &ts,
+			Status:      &models.AlertStatus{State: &state, SilencedBy: silencedBy},
+		}
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	alerts := []*models.GettableAlert{
+		alert("HighLatency", now, "active"),
+		nil,
+		alert("DiskFull", now.Add(-time.Hour), "suppressed", "silence-1"),
+	}
+
+	t.Run("all alerts", func(t *testing.T) {
+		result, err := summarizeActiveAlerts(alerts, nil)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, activeAlertSummary{
+			Fingerprint: "DiskFull-fp",
+			Labels:      map[string]string{"alertname": "DiskFull", "team": "a"},
+			Annotations: map[string]string{"summary": "DiskFull is firing"},
+			State:       "suppressed",
+			ActiveSince: strfmt.DateTime(now.Add(-time.Hour)).String(),
+			SilencedBy:  []string{"silence-1"},
+		}, result[0])
+		assert.Equal(t, "HighLatency", result[1].Labels["alertname"])
+	})
+
+	t.Run("filtered by selector", func(t *testing.T) {
+		result, err := summarizeActiveAlerts(alerts, []Selector{
+			{Filters: []LabelMatcher{{Name: "alertname", Value: "High.*", Type: "=~"}}},
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "HighLatency", result[0].Labels["alertname"])
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		_, err := summarizeActiveAlerts(alerts, []Selector{
+			{Filters: []LabelMatcher{{Name: "alertname", Value: "x", Type: "=="}}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestSortAlertRules(t *testing.T) {
+	rule := func(uid, title, folderUID string) *models.ProvisionedAlertRule {
+		return &models.ProvisionedAlertRule{UID: uid, Title: &title, FolderUID: &folderUID}
+	}
+	uids := func(rules models.ProvisionedAlertRules) []string {
+		result := make([]string, len(rules))
+		for i, r := range rules {
+			result[i] = r.UID
+		}
+		return result
+	}
+	newRules := func() models.ProvisionedAlertRules {
+		return models.ProvisionedAlertRules{
+			rule("c", "Beta", "f1"),
+			rule("a", "Gamma", "f2"),
+			rule("b", "Alpha", "f1"),
+			{UID: "d"},
+		}
+	}
+
+	for _, tc := range []struct {
+		sortBy   string
+		desc     bool
+		expected []string
+	}{
+		{"", false, []string{"c", "a", "b", "d"}},
+		{"title", false, []string{"d", "b", "c", "a"}},
+		{"title", true, []string{"a", "c", "b", "d"}},
+		{"uid", false, []string{"a", "b", "c", "d"}},
+		{"folder", false, []string{"d", "b", "c", "a"}},
+		{"folder", true, []string{"a", "c", "b", "d"}},
+	} {
+		t.Run(fmt.Sprintf("%s desc=%t", tc.sortBy, tc.desc), func(t *testing.T) {
+			rules := newRules()
+			sortAlertRules(rules, tc.sortBy, tc.desc)
+			assert.Equal(t, tc.expected, uids(rules))
+		})
+	}
+}
+
+func TestFilterAlertRulesMatchMode(t *testing.T) {
+	rules := models.ProvisionedAlertRules{
+		{UID: "a", Labels: map[string]string{"team": "db", "severity": "critical"}},
+		{UID: "b", Labels: map[string]string{"team": "web", "severity": "warning"}},
+		{UID: "c", Labels: map[string]string{"team": "infra", "severity": "info"}},
+	}
+	selectors := []Selector{
+		{Filters: []LabelMatcher{{Name: "team", Value: "db", Type: "="}}},
+		{Filters: []LabelMatcher{{Name: "severity", Value: "warning", Type: "="}}},
+	}
+	uids := func(rules models.ProvisionedAlertRules) []string {
+		result := make([]string, len(rules))
+		for i, r := range rules {
+			result[i] = r.UID
+		}
+		return result
+	}
+
+	t.Run("all", func(t *testing.T) {
+		result, err := filterAlertRules(rules, selectors, false)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("any", func(t *testing.T) {
+		result, err := filterAlertRules(rules, selectors, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, uids(result))
+	})
+
+	t.Run("invalid match mode", func(t *testing.T) {
+		err := ListAlertRulesParams{MatchMode: "some"}.validate()
+		assert.EqualError(t, err, "invalid match_mode: some, must be 'all' or 'any'")
+	})
+}
+
+func TestExportAlertRules(t *testing.T) {
+	var query url.Values
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/provisioning/alert-rules/export", r.URL.Path)
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "text/yaml")
+		_, _ = w.Write([]byte("apiVersion: 1\ngroups:\n  - orgId: 1\n    name: group\n"))
+	})
+
+	t.Run("folder as yaml", func(t *testing.T) {
+		result, err := exportAlertRules(ctx, ExportAlertRulesParams{FolderUID: "alerts"})
+		require.NoError(t, err)
+		assert.Equal(t, "apiVersion: 1\ngroups:\n  - orgId: 1\n    name: group\n", result)
+		assert.Equal(t, url.Values{"format": {"yaml"}, "folderUid": {"alerts"}}, query)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		_, err := exportAlertRules(ctx, ExportAlertRulesParams{Format: "json"})
+		require.NoError(t, err)
+		assert.Equal(t, url.Values{"format": {"json"}}, query)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := exportAlertRules(ctx, ExportAlertRulesParams{Format: "hcl"})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.EqualError(t, err, "export alert rules: invalid format: hcl, must be 'yaml' or 'json'")
+	})
+}
+
+func TestTestAlertRule(t *testing.T) {
+	var body map[string]any
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/provisioning/alert-rules/high-cpu":
+			_, _ = w.Write([]byte(`{"uid":"high-cpu","title":"High CPU","condition":"C","data":[{"refId":"A","datasourceUid":"prometheus","model":{"expr":"cpu"}}]}`))
+		case "/api/v1/eval":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["data"].([]any)[0].(map[string]any)["refId"] == "invalid" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"message":"failed to build query 'invalid'"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":{
+				"C":{"status":200,"frames":[
+					{"schema":{"fields":[{"name":"C","type":"number","labels":{"instance":"a"}}]},"data":{"values":[[1]]}},
+					{"schema":{"fields":[{"name":"C","type":"number","labels":{"instance":"b"}}]},"data":{"values":[[0]]}}
+				]},
+				"A":{"status":200,"frames":[
+					{"schema":{"fields":[{"name":"time","type":"time"},{"name":"cpu","type":"number","labels":{"instance":"a"}}]},"data":{"values":[[1700000000000],[0.95]]}}
+				]}
+			}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	t.Run("existing rule", func(t *testing.T) {
+		result, err := testAlertRule(ctx, TestAlertRuleParams{UID: "high-cpu", TimeRFC3339: "2024-01-01T00:00:00Z"})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-01T00:00:00Z", body["now"])
+		assert.True(t, result.Firing)
+		assert.Equal(t, []map[string]string{{"instance": "a"}}, result.FiringSeries)
+		assert.False(t, result.NoData)
+		require.Len(t, result.Results, 2)
+		assert.Equal(t, "A", result.Results[0].RefID)
+		assert.Equal(t, []any{"2023-11-14T22:13:20Z", 0.95}, result.Results[0].Frames[0].Rows[0])
+	})
+
+	t.Run("new rule", func(t *testing.T) {
+		_, err := testAlertRule(ctx, TestAlertRuleParams{
+			Condition: "B",
+			Data:      []map[string]any{{"refId": "A", "datasourceUid": "prometheus"}},
+		})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), `the condition "B" is not the refId of any query or expression`)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := testAlertRule(ctx, TestAlertRuleParams{
+			Condition: "invalid",
+			Data:      []map[string]any{{"refId": "invalid"}},
+		})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "failed to build query 'invalid'")
+	})
+
+	t.Run("read-only mode", func(t *testing.T) {
+		ctx := mcpgrafana.WithReadOnly(ctx, true)
+		_, err := testAlertRule(ctx, TestAlertRuleParams{UID: "high-cpu"})
+		require.NoError(t, err)
+
+		body = nil
+		_, err = testAlertRule(ctx, TestAlertRuleParams{
+			Condition: "C",
+			Data: []map[string]any{
+				{"refId": "A", "datasourceUid": "postgres"},
+				{"refId": "C", "datasourceUid": expressionDatasourceUID},
+			},
+		})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "can't be queried in read-only mode")
+		assert.Nil(t, body)
+	})
+
+	t.Run("uid and data", func(t *testing.T) {
+		_, err := testAlertRule(ctx, TestAlertRuleParams{UID: "high-cpu", Condition: "C", Data: []map[string]any{{"refId": "C"}}})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestEvaluateAlertCondition(t *testing.T) {
+	var result alertRuleTestResult
+	evaluateAlertCondition(&result, nil)
+	assert.True(t, result.NoData)
+	assert.False(t, result.Firing)
+}
@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/grafana/grafana-openapi-client-go/client/annotations"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// defaultAnnotationLimit is the maximum number of annotations returned by
+// list_annotations if no limit is given.
+const defaultAnnotationLimit = 100
+
+// parseAnnotationTime parses an RFC3339 time, returning it in milliseconds
+// since the epoch as used by the annotations API, or 0 if it's empty.
+func parseAnnotationTime(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, mcpgrafana.NewToolError(fmt.Errorf("parsing %s: %w", name, err))
+	}
+	return t.UnixMilli(), nil
+}
+
+type CreateAnnotationParams struct {
+	Text           string   `json:"text" jsonschema:"required,description=The text of the annotation"`
+	Tags           []string `json:"tags,omitempty" jsonschema:"description=Optionally\\, tags to add to the annotation\\, e.g. ['deploy'\\, 'api']"`
+	DashboardUID   string   `json:"dashboardUid,omitempty" jsonschema:"description=Optionally\\, the UID of the dashboard to add the annotation to. If not set\\, an org-wide annotation is created\\, which dashboards can show by filtering on its tags"`
+	PanelID        int64    `json:"panelId,omitempty" jsonschema:"description=Optionally\\, the ID of the panel to add the annotation to. Requires dashboardUid"`
+	TimeRFC3339    string   `json:"timeRfc3339,omitempty" jsonschema:"description=Optionally\\, the time of the annotation in RFC3339 format. Defaults to now"`
+	TimeEndRFC3339 string   `json:"timeEndRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time in RFC3339 format\\, to annotate a time range rather than a single point in time"`
+}
+
+type createAnnotationResult struct {
+	ID      int64  `json:"id"`
+	Message string `json:"message"`
+}
+
+func createAnnotation(ctx context.Context, args CreateAnnotationParams) (*createAnnotationResult, error) {
+	if args.Text == "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("text is required"))
+	}
+	if args.PanelID != 0 && args.DashboardUID == "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("dashboardUid is required when panelId is set"))
+	}
+	start, err := parseAnnotationTime("timeRfc3339", args.TimeRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	if start == 0 {
+		start = time.Now().UnixMilli()
+	}
+	end, err := parseAnnotationTime("timeEndRfc3339", args.TimeEndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	if end != 0 && end < start {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("timeEndRfc3339 must not be before timeRfc3339"))
+	}
+
+	tags := args.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create annotation: %w", err)
+	}
+	params := annotations.NewPostAnnotationParamsWithContext(ctx).WithBody(&models.PostAnnotationsCmd{
+		DashboardUID: args.DashboardUID,
+		PanelID:      args.PanelID,
+		Text:         &args.Text,
+		Tags:         tags,
+		Time:         start,
+		TimeEnd:      end,
+	})
+	response, err := c.Annotations.PostAnnotationWithParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("create annotation: %w", err)
+	}
+	result := &createAnnotationResult{}
+	if response.Payload.ID != nil {
+		result.ID = *response.Payload.ID
+	}
+	if response.Payload.Message != nil {
+		result.Message = *response.Payload.Message
+	}
+	return result, nil
+}
+
+var CreateAnnotation = mcpgrafana.MustTool(
+	"create_annotation",
+	"Create an annotation to mark an event, such as a deploy or an incident, on graphs. The annotation can be added to a specific dashboard or panel, or created org-wide and shown on dashboards by its tags. Set an end time to annotate a time range. Returns the ID of the created annotation",
+	createAnnotation,
+).AsMutating()
+
+type ListAnnotationsParams struct {
+	StartRFC3339 string   `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, only return annotations after this time in RFC3339 format"`
+	EndRFC3339   string   `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, only return annotations before this time in RFC3339 format"`
+	Tags         []string `json:"tags,omitempty" jsonschema:"description=Optionally\\, only return annotations with all of these tags"`
+	DashboardUID string   `json:"dashboardUid,omitempty" jsonschema:"description=Optionally\\, only return annotations on the dashboard with this UID"`
+	Type         string   `json:"type,omitempty" jsonschema:"description=Optionally\\, only return annotations created by users ('annotation') or by alerts ('alert'),enum=annotation,enum=alert"`
+	Limit        int      `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of annotations to return. Defaults to 100"`
+}
+
+type annotationSummary struct {
+	ID           int64    `json:"id"`
+	DashboardUID string   `json:"dashboardUid,omitempty"`
+	PanelID      int64    `json:"panelId,omitempty"`
+	Time         string   `json:"time"`
+	TimeEnd      string   `json:"timeEnd,omitempty"`
+	Text         string   `json:"text"`
+	Tags         []string `json:"tags"`
+	Login        string   `json:"login,omitempty"`
+	AlertName    string   `json:"alertName,omitempty"`
+}
+
+func listAnnotations(ctx context.Context, args ListAnnotationsParams) ([]annotationSummary, error) {
+	if args.Limit < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid limit: %d, must be greater than 0", args.Limit))
+	}
+	from, err := parseAnnotationTime("startRfc3339", args.StartRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseAnnotationTime("endRfc3339", args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+
+	params := annotations.NewGetAnnotationsParamsWithContext(ctx)
+	if from != 0 {
+		params.SetFrom(&from)
+	}
+	if to != 0 {
+		params.SetTo(&to)
+	}
+	if len(args.Tags) > 0 {
+		params.SetTags(args.Tags)
+	}
+	if args.DashboardUID != "" {
+		params.SetDashboardUID(&args.DashboardUID)
+	}
+	if args.Type != "" {
+		params.SetType(&args.Type)
+	}
+	limit := int64(defaultAnnotationLimit)
+	if args.Limit > 0 {
+		limit = int64(args.Limit)
+	}
+	params.SetLimit(&limit)
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list annotations: %w", err)
+	}
+	response, err := c.Annotations.GetAnnotations(params)
+	if err != nil {
+		return nil, fmt.Errorf("list annotations: %w", err)
+	}
+	return summarizeAnnotations(response.Payload), nil
+}
+
+func summarizeAnnotations(items []*models.Annotation) []annotationSummary {
+	result := make([]annotationSummary, 0, len(items))
+	for _, a := range items {
+		if a == nil {
+			continue
+		}
+		summary := annotationSummary{
+			ID:           a.ID,
+			DashboardUID: a.DashboardUID,
+			PanelID:      a.PanelID,
+			Time:         time.UnixMilli(a.Time).UTC().Format(time.RFC3339),
+			Text:         a.Text,
+			Tags:         a.Tags,
+			Login:        a.Login,
+			AlertName:    a.AlertName,
+		}
+		if summary.Tags == nil {
+			summary.Tags = []string{}
+		}
+		if a.TimeEnd != 0 && a.TimeEnd != a.Time {
+			summary.TimeEnd = time.UnixMilli(a.TimeEnd).UTC().Format(time.RFC3339)
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+var ListAnnotations = mcpgrafana.MustTool(
+	"list_annotations",
+	"List annotations, optionally filtered by time range, tags, dashboard and type. Returns the ID, time (and end time for ranges), text, tags and creator of each, newest first",
+	listAnnotations,
+)
+
+type ListAnnotationTagsParams struct {
+	Tag   string `json:"tag,omitempty" jsonschema:"description=Optionally\\, only return tags containing this string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of tags to return. Defaults to 100"`
+}
+
+type annotationTag struct {
+	Tag string `json:"tag"`
+	// Count is the number of annotations with the tag.
+	Count int64 `json:"count"`
+}
+
+func listAnnotationTags(ctx context.Context, args ListAnnotationTagsParams) ([]annotationTag, error) {
+	if args.Limit < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid limit: %d, must be greater than 0", args.Limit))
+	}
+	params := annotations.NewGetAnnotationTagsParamsWithContext(ctx)
+	if args.Tag != "" {
+		params.SetTag(&args.Tag)
+	}
+	limit := strconv.Itoa(defaultAnnotationLimit)
+	if args.Limit > 0 {
+		limit = strconv.Itoa(args.Limit)
+	}
+	params.SetLimit(&limit)
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list annotation tags: %w", err)
+	}
+	response, err := c.Annotations.GetAnnotationTags(params)
+	if err != nil {
+		return nil, fmt.Errorf("list annotation tags: %w", grafanaAPIError(err))
+	}
+	result := []annotationTag{}
+	if response.Payload == nil || response.Payload.Result == nil {
+		return result, nil
+	}
+	for _, t := range response.Payload.Result.Tags {
+		if t != nil {
+			result = append(result, annotationTag{Tag: t.Tag, Count: t.Count})
+		}
+	}
+	return result, nil
+}
+
+var ListAnnotationTags = mcpgrafana.MustTool(
+	"list_annotation_tags",
+	"List the tags used by annotations, with the number of annotations with each. Use this to discover the tags to filter list_annotations by",
+	listAnnotationTags,
+)
+
+type DeleteAnnotationParams struct {
+	ID int64 `json:"id" jsonschema:"required,description=The ID of the annotation to delete. Use list_annotations to find it"`
+}
+
+func deleteAnnotation(ctx context.Context, args DeleteAnnotationParams) (string, error) {
+	if args.ID <= 0 {
+		return "", mcpgrafana.NewToolError(fmt.Errorf("delete annotation: id is required"))
+	}
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("delete annotation: %w", err)
+	}
+	id := strconv.FormatInt(args.ID, 10)
+	response, err := c.Annotations.DeleteAnnotationByIDWithParams(annotations.NewDeleteAnnotationByIDParamsWithContext(ctx).WithAnnotationID(id))
+	if err != nil {
+		var sc statusCoder
+		if errors.As(err, &sc) && sc.IsCode(http.StatusNotFound) {
+			return "", mcpgrafana.NewToolError(fmt.Errorf("delete annotation: annotation with id %d not found", args.ID))
+		}
+		return "", fmt.Errorf("delete annotation %d: %w", args.ID, grafanaAPIError(err))
+	}
+	if response.Payload == nil || response.Payload.Message == "" {
+		return fmt.Sprintf("Annotation %d deleted", args.ID), nil
+	}
+	return response.Payload.Message, nil
+}
+
+var DeleteAnnotation = mcpgrafana.MustTool(
+	"delete_annotation",
+	"Delete the annotation with the given ID. This cannot be undone",
+	deleteAnnotation,
+).AsMutating()
+
+func AddAnnotationTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "annotations",
+		ListAnnotations,
+		ListAnnotationTags,
+		CreateAnnotation,
+		DeleteAnnotation,
+	)
+}
@@ -0,0 +1,79 @@
+// Requires a Grafana instance running on localhost:3000.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationTools(t *testing.T) {
+	t.Run("create and list annotations", func(t *testing.T) {
+		ctx := newTestContext()
+		tag := "mcp-test-" + time.Now().Format("20060102150405.000")
+		start := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+
+		created, err := createAnnotation(ctx, CreateAnnotationParams{
+			Text:           "Deployed v1.2.3",
+			Tags:           []string{tag, "deploy"},
+			TimeRFC3339:    start.Format(time.RFC3339),
+			TimeEndRFC3339: start.Add(30 * time.Second).Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		require.NotZero(t, created.ID)
+
+		result, err := listAnnotations(ctx, ListAnnotationsParams{
+			Tags: []string{tag},
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, created.ID, result[0].ID)
+		assert.Equal(t, "Deployed v1.2.3", result[0].Text)
+		assert.ElementsMatch(t, []string{tag, "deploy"}, result[0].Tags)
+		assert.Equal(t, start.Format(time.RFC3339), result[0].Time)
+		assert.Equal(t, start.Add(30*time.Second).Format(time.RFC3339), result[0].TimeEnd)
+	})
+
+	t.Run("list annotation tags and delete annotation", func(t *testing.T) {
+		ctx := newTestContext()
+		tag := "mcp-delete-" + time.Now().Format("20060102150405.000")
+		created, err := createAnnotation(ctx, CreateAnnotationParams{
+			Text: "To be deleted",
+			Tags: []string{tag},
+		})
+		require.NoError(t, err)
+
+		tags, err := listAnnotationTags(ctx, ListAnnotationTagsParams{Tag: tag})
+		require.NoError(t, err)
+		assert.Equal(t, []annotationTag{{Tag: tag, Count: 1}}, tags)
+
+		_, err = deleteAnnotation(ctx, DeleteAnnotationParams{ID: created.ID})
+		require.NoError(t, err)
+		result, err := listAnnotations(ctx, ListAnnotationsParams{Tags: []string{tag}})
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("create annotation - invalid time", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := createAnnotation(ctx, CreateAnnotationParams{
+			Text:        "Bad time",
+			TimeRFC3339: "yesterday",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("create annotation - panel without dashboard", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := createAnnotation(ctx, CreateAnnotationParams{
+			Text:    "No dashboard",
+			PanelID: 1,
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+func TestListAnnotationTags(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/annotations/tags", r.URL.Path)
+		assert.Equal(t, "deploy", r.URL.Query().Get("tag"))
+		assert.Equal(t, "100", r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":{"tags":[{"tag":"deploy","count":3},{"tag":"deploy:api","count":1}]}}`))
+	})
+
+	result, err := listAnnotationTags(ctx, ListAnnotationTagsParams{Tag: "deploy"})
+	require.NoError(t, err)
+	assert.Equal(t, []annotationTag{{Tag: "deploy", Count: 3}, {Tag: "deploy:api", Count: 1}}, result)
+}
+
+func TestDeleteAnnotation(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/annotations/1" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Annotation not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"message":"Annotation deleted"}`))
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		result, err := deleteAnnotation(ctx, DeleteAnnotationParams{ID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "Annotation deleted", result)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := deleteAnnotation(ctx, DeleteAnnotationParams{ID: 2})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.EqualError(t, err, "delete annotation: annotation with id 2 not found")
+	})
+
+	t.Run("missing ID", func(t *testing.T) {
+		_, err := deleteAnnotation(ctx, DeleteAnnotationParams{})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+	})
+}
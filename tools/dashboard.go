@@ -2,35 +2,749 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/grafana/grafana-openapi-client-go/client/dashboard_versions"
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	mcpgrafana "github.com/grafana/mcp-grafana"
 )
 
 type GetDashboardByUIDParams struct {
-	UID string `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+	UID    string   `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+	Fields []string `json:"fields,omitempty" jsonschema:"description=Optionally\\, only return these parts of the dashboard JSON\\, as dot-separated paths such as 'title'\\, 'templating' or 'panels.title'. Paths through lists apply to each item. Use this to keep the response small for large dashboards. Defaults to the whole dashboard and its metadata"`
 }
 
 func getDashboardByUID(ctx context.Context, args GetDashboardByUIDParams) (*models.DashboardFullWithMeta, error) {
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard by uid %s: %w", args.UID, err)
+	}
 
 	dashboard, err := c.Dashboards.GetDashboardByUID(args.UID)
 	if err != nil {
-		return nil, fmt.Errorf("get dashboard by uid %s: %w", args.UID, err)
+		return nil, fmt.Errorf("get dashboard by uid %s: %w", args.UID, grafanaAPIError(err))
 	}
 	return dashboard.Payload, nil
 }
 
+// fieldTree holds dot-separated paths into a JSON value, split into their
+// parts. An empty tree selects the whole value.
+type fieldTree map[string]fieldTree
+
+// newFieldTree returns the tree of the given paths. A path which is a
+// prefix of another selects the whole of its value, so the longer path is
+// redundant.
+func newFieldTree(paths []string) (fieldTree, error) {
+	tree := fieldTree{}
+	for _, path := range paths {
+		parts := strings.Split(path, ".")
+		if slices.Contains(parts, "") {
+			return nil, fmt.Errorf("invalid field %q", path)
+		}
+		node := tree
+		for i, part := range parts {
+			child, ok := node[part]
+			if ok && len(child) == 0 {
+				break
+			}
+			if i == len(parts)-1 {
+				node[part] = fieldTree{}
+				break
+			}
+			if !ok {
+				child = fieldTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return tree, nil
+}
+
+// projectFields returns the parts of v, a decoded JSON value, selected by
+// tree, keeping their structure. Lists are projected item by item. Fields
+// which don't exist are left out.
+func projectFields(v any, tree fieldTree) (any, bool) {
+	if len(tree) == 0 {
+		return v, true
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(tree))
+		for key, child := range tree {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+			if projected, ok := projectFields(value, child); ok {
+				result[key] = projected
+			}
+		}
+		return result, true
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i], _ = projectFields(item, tree)
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// getDashboardFields is the handler of get_dashboard_by_uid, which returns
+// only the requested fields of the dashboard JSON if there are any.
+func getDashboardFields(ctx context.Context, args GetDashboardByUIDParams) (any, error) {
+	if len(args.Fields) == 0 {
+		return getDashboardByUID(ctx, args)
+	}
+	tree, err := newFieldTree(args.Fields)
+	if err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get dashboard by uid %s: %w", args.UID, err))
+	}
+	dashboard, err := getDashboardByUID(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	projected, _ := projectFields(dashboard.Dashboard, tree)
+	return projected, nil
+}
+
 var GetDashboardByUID = mcpgrafana.MustTool(
 	"get_dashboard_by_uid",
-	"Get dashboard by uid",
-	getDashboardByUID,
+	"Get dashboard by uid. Large dashboards can use thousands of tokens, so use fields to get only the parts you need, such as 'panels.title'",
+	getDashboardFields,
+)
+
+// MaxGetDashboardsUIDs is the maximum number of dashboards which can be
+// fetched at once with get_dashboards_by_uids.
+const MaxGetDashboardsUIDs = 20
+
+type GetDashboardsByUIDsParams struct {
+	UIDs []string `json:"uids" jsonschema:"required,description=The UIDs of the dashboards to get\\, at most 20"`
+}
+
+type dashboardOrError struct {
+	Dashboard *models.DashboardFullWithMeta `json:"dashboard,omitempty"`
+	Error     string                        `json:"error,omitempty"`
+}
+
+func getDashboardsByUIDs(ctx context.Context, args GetDashboardsByUIDsParams) (map[string]dashboardOrError, error) {
+	uids := slices.Compact(slices.Sorted(slices.Values(args.UIDs)))
+	if len(uids) == 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get dashboards by uids: at least one UID is required"))
+	}
+	if len(uids) > MaxGetDashboardsUIDs {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get dashboards by uids: at most %d UIDs can be given, got %d", MaxGetDashboardsUIDs, len(uids)))
+	}
+	if _, err := mcpgrafana.RequireGrafanaClient(ctx); err != nil {
+		return nil, fmt.Errorf("get dashboards by uids: %w", err)
+	}
+
+	results := make([]dashboardOrError, len(uids))
+	// Failures are returned per dashboard, so this can only fail if the
+	// context is cancelled.
+	err := forEachConcurrently(ctx, len(uids), func(ctx context.Context, i int) error {
+		dashboard, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: uids[i]})
+		if err != nil {
+			results[i].Error = err.Error()
+		} else {
+			results[i].Dashboard = dashboard
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get dashboards by uids: %w", err)
+	}
+
+	byUID := make(map[string]dashboardOrError, len(uids))
+	for i, uid := range uids {
+		byUID[uid] = results[i]
+	}
+	return byUID, nil
+}
+
+var GetDashboardsByUIDs = mcpgrafana.MustTool(
+	"get_dashboards_by_uids",
+	"Get several dashboards by uid at once. Returns an object keyed by uid, with either the dashboard or the error fetching it for each. Use this instead of calling get_dashboard_by_uid repeatedly",
+	getDashboardsByUIDs,
+)
+
+type GetDashboardByTitleParams struct {
+	Title string `json:"title" jsonschema:"required,description=The title of the dashboard"`
+}
+
+type dashboardCandidate struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	FolderTitle string `json:"folderTitle,omitempty"`
+}
+
+type getDashboardByTitleResult struct {
+	Dashboard  *models.DashboardFullWithMeta `json:"dashboard,omitempty"`
+	Candidates []dashboardCandidate          `json:"candidates,omitempty"`
+}
+
+func getDashboardByTitle(ctx context.Context, args GetDashboardByTitleParams) (*getDashboardByTitleResult, error) {
+	if args.Title == "" {
+		return nil, fmt.Errorf("get dashboard by title: title is required")
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard by title %s: %w", args.Title, err)
+	}
+	params := search.NewSearchParamsWithContext(ctx)
+	params.SetQuery(&args.Title)
+	params.SetType(&dashboardTypeStr)
+	response, err := c.Search.Search(params)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard by title %s: %w", args.Title, grafanaAPIError(err))
+	}
+
+	hits := response.Payload
+	// The search matches titles by substring, so prefer exact matches if there
+	// are any.
+	exact := models.HitList{}
+	for _, hit := range hits {
+		if strings.EqualFold(hit.Title, args.Title) {
+			exact = append(exact, hit)
+		}
+	}
+	if len(exact) > 0 {
+		hits = exact
+	}
+
+	switch len(hits) {
+	case 0:
+		return nil, fmt.Errorf("get dashboard by title: no dashboard found with title %s", args.Title)
+	case 1:
+		dashboard, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: hits[0].UID})
+		if err != nil {
+			return nil, err
+		}
+		return &getDashboardByTitleResult{Dashboard: dashboard}, nil
+	}
+
+	candidates := make([]dashboardCandidate, 0, len(hits))
+	for _, hit := range hits {
+		candidates = append(candidates, dashboardCandidate{
+			UID:         hit.UID,
+			Title:       hit.Title,
+			FolderTitle: hit.FolderTitle,
+		})
+	}
+	return &getDashboardByTitleResult{Candidates: candidates}, nil
+}
+
+var GetDashboardByTitle = mcpgrafana.MustTool(
+	"get_dashboard_by_title",
+	"Get a dashboard by title. If exactly one dashboard matches, it is returned. If several match, a list of candidates is returned instead; use get_dashboard_by_uid with the UID of the right one",
+	getDashboardByTitle,
+)
+
+type DeleteDashboardParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The UID of the dashboard to delete"`
+}
+
+func (p DeleteDashboardParams) validate() error {
+	if p.UID == "" {
+		return fmt.Errorf("uid is required")
+	}
+	return nil
+}
+
+func deleteDashboard(ctx context.Context, args DeleteDashboardParams) (string, error) {
+	if err := args.validate(); err != nil {
+		return "", fmt.Errorf("delete dashboard: %w", err)
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("delete dashboard %s: %w", args.UID, err)
+	}
+	response, err := c.Dashboards.DeleteDashboardByUID(args.UID)
+	if err != nil {
+		var notFound *dashboards.DeleteDashboardByUIDNotFound
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("delete dashboard: dashboard with uid %s not found", args.UID)
+		}
+		return "", fmt.Errorf("delete dashboard by uid %s: %w", args.UID, grafanaAPIError(err))
+	}
+	if response.Payload == nil || response.Payload.Message == nil {
+		return fmt.Sprintf("Dashboard %s deleted", args.UID), nil
+	}
+	return *response.Payload.Message, nil
+}
+
+var DeleteDashboard = mcpgrafana.MustTool(
+	"delete_dashboard",
+	"Delete a dashboard by uid",
+	deleteDashboard,
+).AsMutating()
+
+type ListDashboardVersionsParams struct {
+	UID   string `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of versions to return"`
+}
+
+func (p ListDashboardVersionsParams) validate() error {
+	if p.UID == "" {
+		return fmt.Errorf("uid is required")
+	}
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	return nil
+}
+
+type dashboardVersionSummary struct {
+	Version   int64  `json:"version"`
+	Created   string `json:"created"`
+	CreatedBy string `json:"createdBy"`
+	Message   string `json:"message,omitempty"`
+}
+
+func listDashboardVersions(ctx context.Context, args ListDashboardVersionsParams) ([]dashboardVersionSummary, error) {
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("list dashboard versions: %w", err)
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list dashboard versions for uid %s: %w", args.UID, err)
+	}
+	params := dashboard_versions.NewGetDashboardVersionsByUIDParamsWithContext(ctx).WithUID(args.UID)
+	if args.Limit > 0 {
+		limit := int64(args.Limit)
+		params.SetLimit(&limit)
+	}
+	response, err := c.DashboardVersions.GetDashboardVersionsByUID(params)
+	if err != nil {
+		return nil, fmt.Errorf("list dashboard versions for uid %s: %w", args.UID, grafanaAPIError(err))
+	}
+	return summarizeDashboardVersions(response.Payload), nil
+}
+
+func summarizeDashboardVersions(versions []*models.DashboardVersionMeta) []dashboardVersionSummary {
+	result := make([]dashboardVersionSummary, 0, len(versions))
+	for _, v := range versions {
+		if v == nil {
+			continue
+		}
+		result = append(result, dashboardVersionSummary{
+			Version:   v.Version,
+			Created:   v.Created.String(),
+			CreatedBy: v.CreatedBy,
+			Message:   v.Message,
+		})
+	}
+	return result
+}
+
+var ListDashboardVersions = mcpgrafana.MustTool(
+	"list_dashboard_versions",
+	"List the saved versions of a dashboard, newest first",
+	listDashboardVersions,
+)
+
+type RestoreDashboardVersionParams struct {
+	UID     string `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+	Version int64  `json:"version" jsonschema:"required,description=The version of the dashboard to restore"`
+}
+
+func (p RestoreDashboardVersionParams) validate() error {
+	if p.UID == "" {
+		return fmt.Errorf("uid is required")
+	}
+	if p.Version <= 0 {
+		return fmt.Errorf("invalid version: %d, must be greater than 0", p.Version)
+	}
+	return nil
+}
+
+func restoreDashboardVersion(ctx context.Context, args RestoreDashboardVersionParams) (*models.RestoreDashboardVersionByUIDOKBody, error) {
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("restore dashboard version: %w", err)
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("restore version %d of dashboard %s: %w", args.Version, args.UID, err)
+	}
+	response, err := c.DashboardVersions.RestoreDashboardVersionByUID(args.UID, &models.RestoreDashboardVersionCommand{
+		Version: args.Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("restore version %d of dashboard %s: %w", args.Version, args.UID, grafanaAPIError(err))
+	}
+	return response.Payload, nil
+}
+
+var RestoreDashboardVersion = mcpgrafana.MustTool(
+	"restore_dashboard_version",
+	"Restore a dashboard to a previous version. The restored dashboard is saved as a new version, which is returned",
+	restoreDashboardVersion,
+).AsMutating()
+
+type GetDashboardPanelQueriesParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+}
+
+type panelQueries struct {
+	ID            int      `json:"id"`
+	Title         string   `json:"title"`
+	Type          string   `json:"type"`
+	DatasourceUID string   `json:"datasourceUid,omitempty"`
+	Queries       []string `json:"queries"`
+}
+
+func getDashboardPanelQueries(ctx context.Context, args GetDashboardPanelQueriesParams) ([]panelQueries, error) {
+	dashboard, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: args.UID})
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard panel queries: %w", err)
+	}
+
+	db, ok := dashboard.Dashboard.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("get dashboard panel queries: dashboard %s is not a JSON object", args.UID)
+	}
+
+	result := []panelQueries{}
+	walkPanels(db, func(panel map[string]any) {
+		pq := panelQueries{
+			ID:            intField(panel, "id"),
+			Title:         stringField(panel, "title"),
+			Type:          stringField(panel, "type"),
+			DatasourceUID: datasourceUID(panel["datasource"]),
+			Queries:       []string{},
+		}
+		targets, _ := panel["targets"].([]any)
+		for _, t := range targets {
+			target, ok := t.(map[string]any)
+			if !ok {
+				continue
+			}
+			if expr := targetExpression(target); expr != "" {
+				pq.Queries = append(pq.Queries, expr)
+			}
+		}
+		result = append(result, pq)
+	})
+	return result, nil
+}
+
+// walkPanels calls fn for each panel in the dashboard, excluding rows. Panels
+// nested inside collapsed rows are visited too.
+func walkPanels(dashboard map[string]any, fn func(panel map[string]any)) {
+	var walk func(panels []any)
+	walk = func(panels []any) {
+		for _, p := range panels {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if nested, ok := panel["panels"].([]any); ok {
+				walk(nested)
+			}
+			if stringField(panel, "type") == "row" {
+				continue
+			}
+			fn(panel)
+		}
+	}
+	panels, _ := dashboard["panels"].([]any)
+	walk(panels)
+
+	// Older dashboards group panels in a top-level list of rows instead.
+	rows, _ := dashboard["rows"].([]any)
+	for _, r := range rows {
+		if row, ok := r.(map[string]any); ok {
+			nested, _ := row["panels"].([]any)
+			walk(nested)
+		}
+	}
+}
+
+// datasourceUID returns the UID of a panel or target datasource reference,
+// which is either an object with a `uid` field or, in older dashboards, the
+// datasource name.
+func datasourceUID(ds any) string {
+	switch v := ds.(type) {
+	case map[string]any:
+		return stringField(v, "uid")
+	case string:
+		return v
+	}
+	return ""
+}
+
+// targetExpression returns the query expression of a panel target, checking
+// the fields used by the most common datasource types.
+func targetExpression(target map[string]any) string {
+	for _, key := range []string{"expr", "query", "rawSql", "expression", "target"} {
+		if expr := stringField(target, key); expr != "" {
+			return expr
+		}
+	}
+	return ""
+}
+
+func stringField(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func intField(m map[string]any, key string) int {
+	// JSON numbers are decoded as float64.
+	f, _ := m[key].(float64)
+	return int(f)
+}
+
+var GetDashboardPanelQueries = mcpgrafana.MustTool(
+	"get_dashboard_panel_queries",
+	"Get the title, type, datasource UID and query expressions of each panel in a dashboard. Use this instead of fetching the whole dashboard when you only need to know which queries power its panels",
+	getDashboardPanelQueries,
+)
+
+type SummarizeDashboardParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+}
+
+// maxSummaryExpressionLength is the length at which query expressions are
+// truncated in dashboard summaries.
+const maxSummaryExpressionLength = 120
+
+func summarizeDashboard(ctx context.Context, args SummarizeDashboardParams) (string, error) {
+	dashboard, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: args.UID})
+	if err != nil {
+		return "", fmt.Errorf("summarize dashboard: %w", err)
+	}
+	db, ok := dashboard.Dashboard.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("summarize dashboard: dashboard %s is not a JSON object", args.UID)
+	}
+	folder := ""
+	if dashboard.Meta != nil {
+		folder = dashboard.Meta.FolderTitle
+	}
+	return dashboardMarkdown(args.UID, folder, db), nil
+}
+
+// dashboardMarkdown returns a short markdown description of a dashboard: its
+// title, folder, tags and description, followed by a list of its panels with
+// their types and queries.
+func dashboardMarkdown(uid, folder string, db map[string]any) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", stringField(db, "title"))
+	fmt.Fprintf(&b, "- UID: %s\n", uid)
+	if folder != "" {
+		fmt.Fprintf(&b, "- Folder: %s\n", folder)
+	}
+	tags, _ := db["tags"].([]any)
+	if len(tags) > 0 {
+		names := make([]string, 0, len(tags))
+		for _, t := range tags {
+			if s, ok := t.(string); ok {
+				names = append(names, s)
+			}
+		}
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(names, ", "))
+	}
+	if description := stringField(db, "description"); description != "" {
+		fmt.Fprintf(&b, "\n%s\n", description)
+	}
+
+	var panels []string
+	walkPanels(db, func(panel map[string]any) {
+		line := fmt.Sprintf("- **%s** (%s)", stringField(panel, "title"), stringField(panel, "type"))
+		var exprs []string
+		targets, _ := panel["targets"].([]any)
+		for _, t := range targets {
+			target, ok := t.(map[string]any)
+			if !ok {
+				continue
+			}
+			if expr := targetExpression(target); expr != "" {
+				exprs = append(exprs, "`"+truncateExpression(expr)+"`")
+			}
+		}
+		if len(exprs) > 0 {
+			line += ": " + strings.Join(exprs, ", ")
+		}
+		panels = append(panels, line)
+	})
+	fmt.Fprintf(&b, "\n## Panels (%d)\n\n", len(panels))
+	for _, p := range panels {
+		b.WriteString(p + "\n")
+	}
+	return b.String()
+}
+
+// truncateExpression flattens a query expression onto one line and truncates
+// it to maxSummaryExpressionLength.
+func truncateExpression(expr string) string {
+	expr = strings.Join(strings.Fields(expr), " ")
+	if runes := []rune(expr); len(runes) > maxSummaryExpressionLength {
+		return string(runes[:maxSummaryExpressionLength]) + "…"
+	}
+	return expr
+}
+
+var SummarizeDashboard = mcpgrafana.MustTool(
+	"summarize_dashboard",
+	"Get a short markdown summary of a dashboard: its title, folder, tags and description, and a list of its panels with their types and queries. Use this to find out what's on a dashboard; it's much smaller than the dashboard's JSON",
+	summarizeDashboard,
 )
 
-// TODO: Implement delete/restore
+// dashboardPermissionLevels maps the names of dashboard permission levels to
+// their values in the Grafana API.
+var dashboardPermissionLevels = map[string]models.PermissionType{
+	"View":  1,
+	"Edit":  2,
+	"Admin": 4,
+}
+
+type GetDashboardPermissionsParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+}
+
+type dashboardPermissionSummary struct {
+	Role       string `json:"role,omitempty"`
+	TeamID     int64  `json:"teamId,omitempty"`
+	Team       string `json:"team,omitempty"`
+	UserID     int64  `json:"userId,omitempty"`
+	UserLogin  string `json:"userLogin,omitempty"`
+	Permission string `json:"permission"`
+	Inherited  bool   `json:"inherited"`
+}
+
+func getDashboardPermissions(ctx context.Context, args GetDashboardPermissionsParams) ([]dashboardPermissionSummary, error) {
+	if args.UID == "" {
+		return nil, fmt.Errorf("get dashboard permissions: uid is required")
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard permissions for uid %s: %w", args.UID, err)
+	}
+	response, err := c.DashboardPermissions.GetDashboardPermissionsListByUID(args.UID)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard permissions for uid %s: %w", args.UID, grafanaAPIError(err))
+	}
+	return summarizeDashboardPermissions(response.Payload), nil
+}
+
+func summarizeDashboardPermissions(permissions []*models.DashboardACLInfoDTO) []dashboardPermissionSummary {
+	result := make([]dashboardPermissionSummary, 0, len(permissions))
+	for _, p := range permissions {
+		if p == nil {
+			continue
+		}
+		result = append(result, dashboardPermissionSummary{
+			Role:       p.Role,
+			TeamID:     p.TeamID,
+			Team:       p.Team,
+			UserID:     p.UserID,
+			UserLogin:  p.UserLogin,
+			Permission: p.PermissionName,
+			Inherited:  p.Inherited,
+		})
+	}
+	return result
+}
+
+var GetDashboardPermissions = mcpgrafana.MustTool(
+	"get_dashboard_permissions",
+	"Get the role, team and user permissions of a dashboard, including those inherited from its folder",
+	getDashboardPermissions,
+)
+
+type DashboardPermissionItem struct {
+	Role       string `json:"role,omitempty" jsonschema:"description=The basic role to grant the permission to: 'Viewer' or 'Editor'. Exactly one of role\\, teamId or userId must be set"`
+	TeamID     int64  `json:"teamId,omitempty" jsonschema:"description=The ID of the team to grant the permission to"`
+	UserID     int64  `json:"userId,omitempty" jsonschema:"description=The ID of the user to grant the permission to"`
+	Permission string `json:"permission" jsonschema:"required,description=The permission level,enum=View,enum=Edit,enum=Admin"`
+}
+
+type UpdateDashboardPermissionsParams struct {
+	UID   string                    `json:"uid" jsonschema:"required,description=The UID of the dashboard"`
+	Items []DashboardPermissionItem `json:"items" jsonschema:"required,description=The complete list of permissions to set on the dashboard. Existing permissions that aren't included are removed"`
+	// RemoveAll must be set to send an empty list of items, so that all of
+	// the dashboard's permissions can't be removed by mistake.
+	RemoveAll bool `json:"removeAll,omitempty" jsonschema:"description=Set to true with an empty list of items to remove all permissions which aren't inherited from the folder"`
+}
+
+func (p UpdateDashboardPermissionsParams) validate() error {
+	if p.UID == "" {
+		return fmt.Errorf("uid is required")
+	}
+	if len(p.Items) == 0 && !p.RemoveAll {
+		return fmt.Errorf("items is empty, which would remove all of the dashboard's permissions. Set removeAll to true to do so")
+	}
+	for i, item := range p.Items {
+		set := 0
+		if item.Role != "" {
+			set++
+		}
+		if item.TeamID != 0 {
+			set++
+		}
+		if item.UserID != 0 {
+			set++
+		}
+		if set != 1 {
+			return fmt.Errorf("item %d: exactly one of role, teamId or userId must be set", i)
+		}
+		if _, ok := dashboardPermissionLevels[item.Permission]; !ok {
+			return fmt.Errorf("item %d: invalid permission %q, must be one of 'View', 'Edit' or 'Admin'", i, item.Permission)
+		}
+	}
+	return nil
+}
+
+func updateDashboardPermissions(ctx context.Context, args UpdateDashboardPermissionsParams) (string, error) {
+	if err := args.validate(); err != nil {
+		return "", mcpgrafana.NewToolError(fmt.Errorf("update dashboard permissions: %w", err))
+	}
+
+	items := make([]*models.DashboardACLUpdateItem, 0, len(args.Items))
+	for _, item := range args.Items {
+		items = append(items, &models.DashboardACLUpdateItem{
+			Role:       item.Role,
+			TeamID:     item.TeamID,
+			UserID:     item.UserID,
+			Permission: dashboardPermissionLevels[item.Permission],
+		})
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("update dashboard permissions for uid %s: %w", args.UID, err)
+	}
+	response, err := c.DashboardPermissions.UpdateDashboardPermissionsByUID(args.UID, &models.UpdateDashboardACLCommand{
+		Items: items,
+	})
+	if err != nil {
+		return "", fmt.Errorf("update dashboard permissions for uid %s: %w", args.UID, grafanaAPIError(err))
+	}
+	if response.Payload == nil || response.Payload.Message == "" {
+		return fmt.Sprintf("Permissions of dashboard %s updated", args.UID), nil
+	}
+	return response.Payload.Message, nil
+}
+
+var UpdateDashboardPermissions = mcpgrafana.MustTool(
+	"update_dashboard_permissions",
+	"Set the permissions of a dashboard. This replaces all existing permissions that are not inherited from the folder, so include any permissions that should be kept",
+	updateDashboardPermissions,
+).AsMutating()
 
 type PostDashboardParams struct {
 	Dashboard models.JSON `json:"dashboard" jsonschema:"required,description=The JSON object of the Grafana dashboard POST request."`
@@ -40,7 +754,10 @@ type PostDashboardParams struct {
 }
 
 func postDashboard(ctx context.Context, args PostDashboardParams) (*models.PostDashboardOKBody, error) {
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("post dashboard: %w", err)
+	}
 
 	response, err := c.Dashboards.PostDashboard(&models.SaveDashboardCommand{
 		Dashboard: args.Dashboard,
@@ -48,7 +765,7 @@ func postDashboard(ctx context.Context, args PostDashboardParams) (*models.PostD
 		Overwrite: args.Overwrite,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("post dashboard failed: JSON: %v: %w", args.Dashboard, err)
+		return nil, fmt.Errorf("post dashboard failed: JSON: %v: %w", args.Dashboard, grafanaAPIError(err))
 	}
 	return response.Payload, nil
 }
@@ -68,9 +785,26 @@ var PostDashboard = mcpgrafana.MustTool(
 	"post_dashboard",
 	postDashboardDesc,
 	postDashboard,
-)
+).AsMutating()
 
-func AddDashboardTools(mcp *server.MCPServer) {
-	GetDashboardByUID.Register(mcp)
-	PostDashboard.Register(mcp)
+func AddDashboardTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "dashboard",
+		GetDashboardByUID,
+		GetDashboardsByUIDs,
+		GetDashboardByTitle,
+		PostDashboard,
+		DeleteDashboard,
+		ListDashboardVersions,
+		RestoreDashboardVersion,
+		GetDashboardPanelQueries,
+		GetDashboardPanelData,
+		RenderDashboardPanel,
+		SummarizeDashboard,
+		ListLibraryPanels,
+		GetLibraryPanelByUID,
+		CreateDashboardSnapshot,
+		DeleteDashboardSnapshot,
+		GetDashboardPermissions,
+		UpdateDashboardPermissions,
+	)
 }
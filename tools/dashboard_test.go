@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
 )
 
 func TestDashboardTools(t *testing.T) {
@@ -42,4 +44,312 @@ func TestDashboardTools(t *testing.T) {
 		})
 		require.Error(t, err)
 	})
+
+	t.Run("delete dashboard", func(t *testing.T) {
+		ctx := newTestContext()
+
+		// Create a throwaway dashboard to delete.
+		created, err := postDashboard(ctx, PostDashboardParams{
+			Dashboard: map[string]interface{}{
+				"title": "Dashboard to delete",
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, created.UID)
+
+		message, err := deleteDashboard(ctx, DeleteDashboardParams{
+			UID: *created.UID,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, message, "deleted")
+
+		_, err = getDashboardByUID(ctx, GetDashboardByUIDParams{
+			UID: *created.UID,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("delete dashboard - invalid uid", func(t *testing.T) {
+		ctx := newTestContext()
+
+		_, err := deleteDashboard(ctx, DeleteDashboardParams{
+			UID: "non-existent-uid",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("delete dashboard - empty uid", func(t *testing.T) {
+		ctx := newTestContext()
+
+		_, err := deleteDashboard(ctx, DeleteDashboardParams{})
+		require.Error(t, err)
+	})
+
+	t.Run("list and restore dashboard versions", func(t *testing.T) {
+		ctx := newTestContext()
+
+		// Create a dashboard and update it so that it has two versions.
+		created, err := postDashboard(ctx, PostDashboardParams{
+			Dashboard: map[string]interface{}{
+				"title": "Dashboard with versions",
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, created.UID)
+		t.Cleanup(func() {
+			_, _ = deleteDashboard(ctx, DeleteDashboardParams{UID: *created.UID})
+		})
+
+		_, err = postDashboard(ctx, PostDashboardParams{
+			Dashboard: map[string]interface{}{
+				"uid":   *created.UID,
+				"title": "Dashboard with versions (updated)",
+			},
+			Overwrite: true,
+		})
+		require.NoError(t, err)
+
+		versions, err := listDashboardVersions(ctx, ListDashboardVersionsParams{
+			UID: *created.UID,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+
+		limited, err := listDashboardVersions(ctx, ListDashboardVersionsParams{
+			UID:   *created.UID,
+			Limit: 1,
+		})
+		require.NoError(t, err)
+		require.Len(t, limited, 1)
+
+		restored, err := restoreDashboardVersion(ctx, RestoreDashboardVersionParams{
+			UID:     *created.UID,
+			Version: 1,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, restored.Version)
+		assert.Equal(t, int64(3), *restored.Version)
+
+		dashboard, err := getDashboardByUID(ctx, GetDashboardByUIDParams{
+			UID: *created.UID,
+		})
+		require.NoError(t, err)
+		dashboardMap, ok := dashboard.Dashboard.(map[string]interface{})
+		require.True(t, ok, "Dashboard should be a map")
+		assert.Equal(t, "Dashboard with versions", dashboardMap["title"])
+	})
+
+	t.Run("restore dashboard version - invalid version", func(t *testing.T) {
+		ctx := newTestContext()
+
+		_, err := restoreDashboardVersion(ctx, RestoreDashboardVersionParams{
+			UID:     "some-uid",
+			Version: 0,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("get dashboard panel queries", func(t *testing.T) {
+		ctx := newTestContext()
+
+		searchResults, err := searchDashboards(ctx, SearchDashboardsParams{
+			Query: "Demo",
+		})
+		require.NoError(t, err)
+		require.Len(t, searchResults, 1)
+
+		result, err := getDashboardPanelQueries(ctx, GetDashboardPanelQueriesParams{
+			UID: searchResults[0].UID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []panelQueries{
+			{
+				ID:            1,
+				Title:         "Node Load",
+				Type:          "timeseries",
+				DatasourceUID: "robustperception",
+				Queries:       []string{"node_load1"},
+			},
+		}, result)
+	})
+
+	t.Run("summarize dashboard", func(t *testing.T) {
+		ctx := newTestContext()
+
+		searchResults, err := searchDashboards(ctx, SearchDashboardsParams{
+			Query: "Demo",
+		})
+		require.NoError(t, err)
+		require.Len(t, searchResults, 1)
+
+		result, err := summarizeDashboard(ctx, SummarizeDashboardParams{
+			UID: searchResults[0].UID,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result, "# Demo\n")
+		assert.Contains(t, result, "- Tags: demo\n")
+		assert.Contains(t, result, "- **Node Load** (timeseries): `node_load1`\n")
+	})
+
+	t.Run("get dashboard panel queries - nested rows", func(t *testing.T) {
+		ctx := newTestContext()
+
+		created, err := postDashboard(ctx, PostDashboardParams{
+			Dashboard: map[string]interface{}{
+				"title": "Dashboard with rows",
+				"panels": []interface{}{
+					map[string]interface{}{
+						"id":    1,
+						"type":  "stat",
+						"title": "Top level",
+						"datasource": map[string]interface{}{
+							"type": "prometheus",
+							"uid":  "prometheus",
+						},
+						"targets": []interface{}{
+							map[string]interface{}{"refId": "A", "expr": "up"},
+						},
+					},
+					map[string]interface{}{
+						"id":        2,
+						"type":      "row",
+						"title":     "Collapsed row",
+						"collapsed": true,
+						"panels": []interface{}{
+							map[string]interface{}{
+								"id":    3,
+								"type":  "logs",
+								"title": "Nested",
+								"datasource": map[string]interface{}{
+									"type": "loki",
+									"uid":  "loki",
+								},
+								"targets": []interface{}{
+									map[string]interface{}{"refId": "A", "expr": `{job="a"}`},
+									map[string]interface{}{"refId": "B", "expr": `{job="b"}`},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = deleteDashboard(ctx, DeleteDashboardParams{UID: *created.UID})
+		})
+
+		result, err := getDashboardPanelQueries(ctx, GetDashboardPanelQueriesParams{
+			UID: *created.UID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []panelQueries{
+			{ID: 1, Title: "Top level", Type: "stat", DatasourceUID: "prometheus", Queries: []string{"up"}},
+			{ID: 3, Title: "Nested", Type: "logs", DatasourceUID: "loki", Queries: []string{`{job="a"}`, `{job="b"}`}},
+		}, result)
+	})
+
+	t.Run("get dashboard by title", func(t *testing.T) {
+		ctx := newTestContext()
+
+		result, err := getDashboardByTitle(ctx, GetDashboardByTitleParams{
+			Title: "demo",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.Dashboard)
+		assert.Empty(t, result.Candidates)
+		dashboardMap, ok := result.Dashboard.Dashboard.(map[string]interface{})
+		require.True(t, ok, "Dashboard should be a map")
+		assert.Equal(t, "Demo", dashboardMap["title"])
+	})
+
+	t.Run("get dashboard by title - multiple matches", func(t *testing.T) {
+		ctx := newTestContext()
+
+		for _, title := range []string{"Duplicate title A", "Duplicate title B"} {
+			created, err := postDashboard(ctx, PostDashboardParams{
+				Dashboard: map[string]interface{}{"title": title},
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_, _ = deleteDashboard(ctx, DeleteDashboardParams{UID: *created.UID})
+			})
+		}
+
+		result, err := getDashboardByTitle(ctx, GetDashboardByTitleParams{
+			Title: "Duplicate title",
+		})
+		require.NoError(t, err)
+		assert.Nil(t, result.Dashboard)
+		assert.Len(t, result.Candidates, 2)
+	})
+
+	t.Run("get dashboard by title - no match", func(t *testing.T) {
+		ctx := newTestContext()
+
+		_, err := getDashboardByTitle(ctx, GetDashboardByTitleParams{
+			Title: "non-existent dashboard title",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("get and update dashboard permissions", func(t *testing.T) {
+		ctx := newTestContext()
+
+		created, err := postDashboard(ctx, PostDashboardParams{
+			Dashboard: map[string]interface{}{
+				"title": "Dashboard with permissions",
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = deleteDashboard(ctx, DeleteDashboardParams{UID: *created.UID})
+		})
+
+		_, err = updateDashboardPermissions(ctx, UpdateDashboardPermissionsParams{
+			UID: *created.UID,
+			Items: []DashboardPermissionItem{
+				{Role: "Viewer", Permission: "View"},
+				{Role: "Editor", Permission: "Admin"},
+			},
+		})
+		require.NoError(t, err)
+
+		result, err := getDashboardPermissions(ctx, GetDashboardPermissionsParams{
+			UID: *created.UID,
+		})
+		require.NoError(t, err)
+		direct := []dashboardPermissionSummary{}
+		for _, p := range result {
+			if !p.Inherited {
+				direct = append(direct, p)
+			}
+		}
+		assert.ElementsMatch(t, []dashboardPermissionSummary{
+			{Role: "Viewer", Permission: "View"},
+			{Role: "Editor", Permission: "Admin"},
+		}, direct)
+	})
+
+	t.Run("update dashboard permissions - invalid item", func(t *testing.T) {
+		ctx := newTestContext()
+
+		_, err := updateDashboardPermissions(ctx, UpdateDashboardPermissionsParams{
+			UID: "some-uid",
+			Items: []DashboardPermissionItem{
+				{Role: "Viewer", TeamID: 1, Permission: "View"},
+			},
+		})
+		var toolErr *mcpgrafana.ToolError
+		require.ErrorAs(t, err, &toolErr)
+
+		_, err = updateDashboardPermissions(ctx, UpdateDashboardPermissionsParams{
+			UID: "some-uid",
+			Items: []DashboardPermissionItem{
+				{Role: "Viewer", Permission: "Owner"},
+			},
+		})
+		require.ErrorAs(t, err, &toolErr)
+	})
 }
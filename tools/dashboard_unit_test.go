@@ -0,0 +1,186 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+func TestGetDashboardByUIDWithoutClient(t *testing.T) {
+	_, err := getDashboardByUID(context.Background(), GetDashboardByUIDParams{UID: "abc"})
+	assert.ErrorIs(t, err, mcpgrafana.ErrNoGrafanaClient)
+}
+
+func TestGetDashboardsByUIDs(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		uid, ok := strings.CutPrefix(r.URL.Path, "/api/dashboards/uid/")
+		require.True(t, ok, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if uid == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Dashboard not found"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"dashboard":{"uid":%q,"title":"Dashboard %s"},"meta":{"slug":%q}}`, uid, uid, uid)
+	})
+
+	uids := []string{"missing", "a", "b", "a"}
+	for i := range 10 {
+		uids = append(uids, fmt.Sprintf("d%d", i))
+	}
+	results, err := getDashboardsByUIDs(mcpgrafana.WithMaxConcurrentRequests(ctx, 2), GetDashboardsByUIDsParams{UIDs: uids})
+	require.NoError(t, err)
+	assert.Len(t, results, 13)
+	assert.Equal(t, "Dashboard a", results["a"].Dashboard.Dashboard.(map[string]any)["title"])
+	assert.Empty(t, results["a"].Error)
+	assert.Nil(t, results["missing"].Dashboard)
+	assert.Contains(t, results["missing"].Error, "get dashboard by uid missing")
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+
+	t.Run("invalid number of uids", func(t *testing.T) {
+		var toolErr *mcpgrafana.ToolError
+		_, err := getDashboardsByUIDs(ctx, GetDashboardsByUIDsParams{})
+		assert.True(t, errors.As(err, &toolErr))
+		many := make([]string, MaxGetDashboardsUIDs+1)
+		for i := range many {
+			many[i] = fmt.Sprint(i)
+		}
+		_, err = getDashboardsByUIDs(ctx, GetDashboardsByUIDsParams{UIDs: many})
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestGetDashboardFields(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"dashboard":{"uid":"abc","title":"Service","templating":{"list":[{"name":"env"}]},"panels":[
+			{"id":1,"title":"Requests","type":"timeseries","targets":[{"expr":"rate(requests[5m])"}]},
+			{"id":2,"type":"text"}
+		]},"meta":{"slug":"service"}}`))
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		result, err := getDashboardFields(ctx, GetDashboardByUIDParams{UID: "abc", Fields: []string{"title", "panels.title", "panels.id", "templating", "missing", "title.nested"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"title":      "Service",
+			"templating": map[string]any{"list": []any{map[string]any{"name": "env"}}},
+			"panels": []any{
+				map[string]any{"id": float64(1), "title": "Requests"},
+				map[string]any{"id": float64(2)},
+			},
+		}, result)
+	})
+
+	t.Run("whole field wins over subfields", func(t *testing.T) {
+		result, err := getDashboardFields(ctx, GetDashboardByUIDParams{UID: "abc", Fields: []string{"panels.title", "panels"}})
+		require.NoError(t, err)
+		panels := result.(map[string]any)["panels"].([]any)
+		assert.Equal(t, "timeseries", panels[0].(map[string]any)["type"])
+	})
+
+	t.Run("no fields", func(t *testing.T) {
+		result, err := getDashboardFields(ctx, GetDashboardByUIDParams{UID: "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, "service", result.(*models.DashboardFullWithMeta).Meta.Slug)
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		_, err := getDashboardFields(ctx, GetDashboardByUIDParams{UID: "abc", Fields: []string{"panels..title"}})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestDashboardMarkdown(t *testing.T) {
+	db := map[string]any{
+		"title":       "Service overview",
+		"description": "Golden signals for the API.",
+		"tags":        []any{"api", "prod"},
+		"panels": []any{
+			map[string]any{
+				"type":  "timeseries",
+				"title": "Request rate",
+				"targets": []any{
+					map[string]any{"expr": "sum(rate(http_requests_total[5m]))\n  by (code)"},
+					map[string]any{"expr": "sum(rate(http_errors_total[5m]))"},
+				},
+			},
+			map[string]any{
+				"type":      "row",
+				"title":     "Details",
+				"collapsed": true,
+				"panels": []any{
+					map[string]any{"type": "text", "title": "Runbook"},
+				},
+			},
+		},
+	}
+
+	expected := "# Service overview\n\n" +
+		"- UID: abc\n" +
+		"- Folder: Services\n" +
+		"- Tags: api, prod\n\n" +
+		"Golden signals for the API.\n\n" +
+		"## Panels (2)\n\n" +
+		"- **Request rate** (timeseries): `sum(rate(http_requests_total[5m])) by (code)`, `sum(rate(http_errors_total[5m]))`\n" +
+		"- **Runbook** (text)\n"
+	assert.Equal(t, expected, dashboardMarkdown("abc", "Services", db))
+}
+
+func TestTruncateExpression(t *testing.T) {
+	long := strings.Repeat("a", maxSummaryExpressionLength+10)
+	assert.Equal(t, strings.Repeat("a", maxSummaryExpressionLength)+"…", truncateExpression(long))
+	assert.Equal(t, "up", truncateExpression("  up\n"))
+}
+
+func TestUpdateDashboardPermissionsEmptyItems(t *testing.T) {
+	var requests atomic.Int32
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/api/dashboards/uid/abc/permissions", r.URL.Path)
+		var body models.UpdateDashboardACLCommand
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Empty(t, body.Items)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"Dashboard permissions updated"}`))
+	})
+
+	t.Run("rejected without removeAll", func(t *testing.T) {
+		_, err := updateDashboardPermissions(ctx, UpdateDashboardPermissionsParams{UID: "abc", Items: []DashboardPermissionItem{}})
+		var toolErr *mcpgrafana.ToolError
+		require.ErrorAs(t, err, &toolErr)
+		assert.Contains(t, err.Error(), "removeAll")
+		assert.Zero(t, requests.Load())
+	})
+
+	t.Run("allowed with removeAll", func(t *testing.T) {
+		result, err := updateDashboardPermissions(ctx, UpdateDashboardPermissionsParams{UID: "abc", Items: []DashboardPermissionItem{}, RemoveAll: true})
+		require.NoError(t, err)
+		assert.Equal(t, "Dashboard permissions updated", result)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
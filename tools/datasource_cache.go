@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// datasourceCache caches datasource lookups for a short time, since clients
+// tend to look up the same datasources over and over again in a session.
+type datasourceCache struct {
+	mu      sync.Mutex
+	entries map[string]datasourceCacheEntry
+	now     func() time.Time
+}
+
+type datasourceCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+func newDatasourceCache() *datasourceCache {
+	return &datasourceCache{
+		entries: map[string]datasourceCacheEntry{},
+		now:     time.Now,
+	}
+}
+
+var datasourceLookups = newDatasourceCache()
+
+func (c *datasourceCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *datasourceCache) set(key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	// Drop expired entries so that the cache doesn't grow without bound
+	// when many different Grafana instances or credentials are used.
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = datasourceCacheEntry{value: value, expires: now.Add(ttl)}
+}
+
+// datasourceCacheKey returns the cache key for a lookup of the given kind,
+// e.g. "uid", and identifier. The key includes the Grafana URL, org and
+// credentials from the context, so that results are never shared between
+// instances or between users who might be allowed to see different
+// datasources. It's hashed so that the credentials aren't kept in memory in
+// the clear.
+func datasourceCacheKey(ctx context.Context, kind, id string) string {
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	write(mcpgrafana.GrafanaURLFromContext(ctx))
+	write(strconv.FormatInt(mcpgrafana.GrafanaOrgIDFromContext(ctx), 10))
+	write(mcpgrafana.GrafanaAPIKeyFromContext(ctx))
+	if basicAuth := mcpgrafana.GrafanaBasicAuthFromContext(ctx); basicAuth != nil {
+		password, _ := basicAuth.Password()
+		write(basicAuth.Username())
+		write(password)
+	}
+	write(kind)
+	write(id)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedDatasourceLookup returns the cached result of a lookup of the given
+// kind and identifier, calling fetch and caching its result if there is none.
+// Errors aren't cached. The cache is bypassed if its TTL is 0.
+func cachedDatasourceLookup[T any](ctx context.Context, kind, id string, fetch func() (T, error)) (T, error) {
+	ttl := mcpgrafana.DatasourceCacheTTLFromContext(ctx)
+	if ttl <= 0 {
+		return fetch()
+	}
+	key := datasourceCacheKey(ctx, kind, id)
+	if v, ok := datasourceLookups.get(key); ok {
+		if value, ok := v.(T); ok {
+			return value, nil
+		}
+	}
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	datasourceLookups.set(key, value, ttl)
+	return value, nil
+}
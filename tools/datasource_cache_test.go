@@ -0,0 +1,104 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestDatasourceCache replaces the datasource cache with an empty one
+// whose clock can be moved forward by the returned function.
+func useTestDatasourceCache(t *testing.T) func(time.Duration) {
+	now := time.Now()
+	cache := newDatasourceCache()
+	cache.now = func() time.Time { return now }
+	original := datasourceLookups
+	datasourceLookups = cache
+	t.Cleanup(func() { datasourceLookups = original })
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCachedDatasourceLookup(t *testing.T) {
+	ctx := mcpgrafana.WithGrafanaURL(context.Background(), "http://grafana.example.com")
+	ctx = mcpgrafana.WithGrafanaAPIKey(ctx, "key-a")
+	ctx = mcpgrafana.WithDatasourceCacheTTL(ctx, time.Minute)
+
+	counter := func() (*int, func() (string, error)) {
+		calls := 0
+		return &calls, func() (string, error) {
+			calls++
+			return "result", nil
+		}
+	}
+
+	t.Run("caches until the TTL expires", func(t *testing.T) {
+		advance := useTestDatasourceCache(t)
+		calls, fetch := counter()
+		for range 2 {
+			v, err := cachedDatasourceLookup(ctx, "uid", "prometheus", fetch)
+			require.NoError(t, err)
+			assert.Equal(t, "result", v)
+		}
+		assert.Equal(t, 1, *calls)
+
+		advance(time.Minute)
+		_, err := cachedDatasourceLookup(ctx, "uid", "prometheus", fetch)
+		require.NoError(t, err)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("scoped by lookup", func(t *testing.T) {
+		useTestDatasourceCache(t)
+		calls, fetch := counter()
+		_, _ = cachedDatasourceLookup(ctx, "uid", "prometheus", fetch)
+		_, _ = cachedDatasourceLookup(ctx, "uid", "loki", fetch)
+		_, _ = cachedDatasourceLookup(ctx, "name", "prometheus", fetch)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("scoped by credentials", func(t *testing.T) {
+		useTestDatasourceCache(t)
+		calls, fetch := counter()
+		_, _ = cachedDatasourceLookup(ctx, "uid", "prometheus", fetch)
+		_, _ = cachedDatasourceLookup(mcpgrafana.WithGrafanaAPIKey(ctx, "key-b"), "uid", "prometheus", fetch)
+		basicAuth := mcpgrafana.WithGrafanaBasicAuth(mcpgrafana.WithGrafanaAPIKey(ctx, ""), url.UserPassword("user", "pass"))
+		_, _ = cachedDatasourceLookup(basicAuth, "uid", "prometheus", fetch)
+		_, _ = cachedDatasourceLookup(mcpgrafana.WithGrafanaOrgID(ctx, 2), "uid", "prometheus", fetch)
+		_, _ = cachedDatasourceLookup(mcpgrafana.WithGrafanaURL(ctx, "http://other.example.com"), "uid", "prometheus", fetch)
+		assert.Equal(t, 5, *calls)
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		useTestDatasourceCache(t)
+		calls := 0
+		fetch := func() (string, error) {
+			calls++
+			return "", errors.New("not found")
+		}
+		for range 2 {
+			_, err := cachedDatasourceLookup(ctx, "uid", "missing", fetch)
+			require.Error(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("disabled with a TTL of 0", func(t *testing.T) {
+		useTestDatasourceCache(t)
+		calls, fetch := counter()
+		disabled := mcpgrafana.WithDatasourceCacheTTL(ctx, 0)
+		for range 2 {
+			_, err := cachedDatasourceLookup(disabled, "uid", "prometheus", fetch)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, *calls)
+	})
+}
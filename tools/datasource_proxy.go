@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+var allowedProxyMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// datasourceProxyURL returns the URL of Grafana's datasource proxy for the
+// datasource with the given UID. The UID is escaped so that it can't change
+// the path to reach other Grafana APIs with the server's credentials.
+func datasourceProxyURL(grafanaURL, uid string) string {
+	return fmt.Sprintf("%s/api/datasources/proxy/uid/%s", strings.TrimRight(grafanaURL, "/"), url.PathEscape(uid))
+}
+
+// proxyStatusError returns err, the error for a non-2xx response from a
+// datasource through the datasource proxy, as a tool error if the caller can
+// correct it: a bad request, such as an invalid query, or a response which
+// wasn't found, usually because the datasource UID is wrong.
+func proxyStatusError(statusCode int, err error) error {
+	if statusCode == http.StatusBadRequest || statusCode == http.StatusNotFound {
+		return mcpgrafana.NewToolError(err)
+	}
+	return err
+}
+
+type QueryDatasourceProxyParams struct {
+	DatasourceUID string            `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
+	Method        string            `json:"method,omitempty" jsonschema:"description=The HTTP method to use. Defaults to GET,enum=GET,enum=POST,enum=PUT,enum=PATCH,enum=DELETE"`
+	Path          string            `json:"path" jsonschema:"required,description=The path to request\\, relative to the datasource's URL. For example '/api/search' for Tempo"`
+	QueryParams   map[string]string `json:"queryParams,omitempty" jsonschema:"description=Optionally\\, query parameters to add to the request"`
+	Body          any               `json:"body,omitempty" jsonschema:"description=Optionally\\, a JSON body to send with the request"`
+}
+
+func (p QueryDatasourceProxyParams) validate() error {
+	if p.DatasourceUID == "" {
+		return fmt.Errorf("datasourceUid is required")
+	}
+	if p.Method != "" && !slices.Contains(allowedProxyMethods, strings.ToUpper(p.Method)) {
+		return fmt.Errorf("invalid method %q, must be one of %s", p.Method, strings.Join(allowedProxyMethods, ", "))
+	}
+	// Don't allow the path to escape the datasource proxy, including with
+	// percent-encoded segments such as '%2e%2e'.
+	for _, segment := range strings.Split(p.Path, "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %w", p.Path, err)
+		}
+		if unescaped == ".." {
+			return fmt.Errorf("invalid path %q: must not contain '..'", p.Path)
+		}
+	}
+	return nil
+}
+
+// proxyClient sends requests to a datasource through Grafana's datasource
+// proxy, for datasources which don't have a client of their own.
+type proxyClient struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func newProxyClient(ctx context.Context, uid string) *proxyClient {
+	return &proxyClient{
+		httpClient: &http.Client{
+			Transport: newDatasourceTransport(ctx),
+			Timeout:   clientTimeout(ctx),
+		},
+		baseURL: datasourceProxyURL(mcpgrafana.GrafanaURLFromContext(ctx), uid),
+	}
+}
+
+// getJSON sends a GET request to the given path of the datasource and decodes
+// the JSON response into result.
+func (c *proxyClient) getJSON(ctx context.Context, urlPath string, params url.Values, result any) error {
+	u := c.baseURL + urlPath
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	// Some datasources, such as Tempo, return protobuf unless JSON is asked
+	// for.
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024*48))
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return proxyStatusError(resp.StatusCode, fmt.Errorf("datasource returned status code %d: %s", resp.StatusCode, string(body)))
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("unmarshalling response: %w", err)
+	}
+	return nil
+}
+
+// queryDatasourceProxy sends a request to an arbitrary path of a datasource
+// through Grafana's datasource proxy and returns the response body as-is.
+func queryDatasourceProxy(ctx context.Context, args QueryDatasourceProxyParams) (string, error) {
+	if err := args.validate(); err != nil {
+		return "", mcpgrafana.NewToolError(err)
+	}
+	method := http.MethodGet
+	if args.Method != "" {
+		method = strings.ToUpper(args.Method)
+	}
+
+	client := newProxyClient(ctx, args.DatasourceUID)
+	u, err := url.Parse(client.baseURL + "/" + strings.TrimPrefix(args.Path, "/"))
+	if err != nil {
+		return "", fmt.Errorf("parsing URL: %w", err)
+	}
+	if len(args.QueryParams) > 0 {
+		params := url.Values{}
+		for k, v := range args.QueryParams {
+			params.Set(k, v)
+		}
+		u.RawQuery = params.Encode()
+	}
+
+	var reqBody io.Reader
+	if args.Body != nil {
+		b, err := json.Marshal(args.Body)
+		if err != nil {
+			return "", fmt.Errorf("marshalling request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	if args.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body with a limit to prevent memory issues
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024*48))
+	if err != nil {
+		return "", fmt.Errorf("reading response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", proxyStatusError(resp.StatusCode, fmt.Errorf("datasource returned status code %d: %s", resp.StatusCode, string(bodyBytes)))
+	}
+	return string(bytes.TrimSpace(bodyBytes)), nil
+}
+
+var QueryDatasourceProxy = mcpgrafana.MustTool(
+	"query_datasource_proxy",
+	"Send a request to an arbitrary path of a datasource through Grafana's datasource proxy and return the raw response body. Use this for datasources which don't have a dedicated tool, such as Tempo or InfluxDB; prefer the datasource-specific tools where they exist.",
+	queryDatasourceProxy,
+).AsMutating()
+
+type GetDatasourceProxyURLParams struct {
+	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource"`
+}
+
+type datasourceProxyURLResult struct {
+	// URL is the base URL of the datasource's proxy, which the datasource
+	// tools append the datasource's API paths to.
+	URL            string `json:"url"`
+	DatasourceName string `json:"datasourceName"`
+	DatasourceType string `json:"datasourceType"`
+}
+
+func getDatasourceProxyURL(ctx context.Context, args GetDatasourceProxyURLParams) (*datasourceProxyURLResult, error) {
+	ds, err := requireDatasource(ctx, args.DatasourceUID)
+	if err != nil {
+		return nil, err
+	}
+	return &datasourceProxyURLResult{
+		URL:            datasourceProxyURL(mcpgrafana.GrafanaURLFromContext(ctx), args.DatasourceUID),
+		DatasourceName: ds.Name,
+		DatasourceType: ds.Type,
+	}, nil
+}
+
+var GetDatasourceProxyURL = mcpgrafana.MustTool(
+	"get_datasource_proxy_url",
+	"Get the URL of Grafana's proxy for a datasource, which the datasource tools send their requests to, along with the datasource's name and type. Use this to debug connection failures",
+	getDatasourceProxyURL,
+)
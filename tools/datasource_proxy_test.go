@@ -0,0 +1,56 @@
+// Requires a Grafana instance running on localhost:3000,
+// with a Prometheus datasource provisioned.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasourceProxyTools(t *testing.T) {
+	t.Run("query datasource proxy", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{
+			DatasourceUID: "prometheus",
+			Path:          "/api/v1/query",
+			QueryParams:   map[string]string{"query": "up"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result, `"status":"success"`)
+	})
+
+	t.Run("query datasource proxy - post", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{
+			DatasourceUID: "prometheus",
+			Method:        "post",
+			Path:          "api/v1/labels",
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result, "__name__")
+	})
+
+	t.Run("query datasource proxy - invalid method", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{
+			DatasourceUID: "prometheus",
+			Method:        "TRACE",
+			Path:          "/api/v1/query",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("query datasource proxy - path traversal", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{
+			DatasourceUID: "prometheus",
+			Path:          "/../../../api/admin/settings",
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+func TestQueryDatasourceProxy(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/datasources/proxy/uid/prometheus/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.EscapedPath() + `"}`))
+	})
+
+	t.Run("request", func(t *testing.T) {
+		result, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{DatasourceUID: "prometheus", Path: "api/v1/labels"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"path":"/api/datasources/proxy/uid/prometheus/api/v1/labels"}`, result)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaTimeout(ctx, 50*time.Millisecond)
+		_, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{DatasourceUID: "prometheus", Path: "/slow"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Client.Timeout")
+	})
+
+	t.Run("uid is escaped", func(t *testing.T) {
+		result, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{DatasourceUID: "../../../api/admin", Path: "settings"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"path":"/api/datasources/proxy/uid/..%2F..%2F..%2Fapi%2Fadmin/settings"}`, result)
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		for _, path := range []string{"/../../api/admin/settings", "%2e%2e/%2E%2E/api/admin/settings", "api/%zz"} {
+			_, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{DatasourceUID: "prometheus", Path: path})
+			var toolErr *mcpgrafana.ToolError
+			assert.ErrorAs(t, err, &toolErr, path)
+		}
+	})
+
+	t.Run("invalid method", func(t *testing.T) {
+		_, err := queryDatasourceProxy(ctx, QueryDatasourceProxyParams{DatasourceUID: "prometheus", Method: "TRACE", Path: "/api/v1/query"})
+		var toolErr *mcpgrafana.ToolError
+		assert.ErrorAs(t, err, &toolErr)
+	})
+}
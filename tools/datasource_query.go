@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// DefaultQueryDatasourceMaxRows is the default maximum number of rows
+// returned for each frame by query_datasource.
+const DefaultQueryDatasourceMaxRows = 100
+
+type QueryDatasourceParams struct {
+	DatasourceUID string         `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
+	Query         map[string]any `json:"query" jsonschema:"required,description=The query model as used by Grafana's frontend for the datasource type\\, e.g. {\"expr\": \"up\"} for Prometheus or {\"rawSql\": \"SELECT 1\"\\, \"format\": \"table\"} for SQL datasources. The datasource and refId are set automatically"`
+	StartRFC3339  string         `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339    string         `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+	MaxRows       int            `json:"maxRows,omitempty" jsonschema:"description=Optionally\\, the maximum number of rows to return for each frame. Defaults to 100"`
+}
+
+// dataFrame is a data frame in the JSON format used by Grafana's
+// /api/ds/query endpoint, which stores the values of each field as a
+// separate array.
+type dataFrame struct {
+	Schema struct {
+		Name   string `json:"name"`
+		Fields []struct {
+			Name   string            `json:"name"`
+			Type   string            `json:"type"`
+			Labels map[string]string `json:"labels"`
+		} `json:"fields"`
+	} `json:"schema"`
+	Data struct {
+		Values [][]any `json:"values"`
+	} `json:"data"`
+}
+
+type queryDataResponse struct {
+	Results map[string]struct {
+		Status int         `json:"status"`
+		Error  string      `json:"error"`
+		Frames []dataFrame `json:"frames"`
+	} `json:"results"`
+}
+
+type frameField struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type frameSummary struct {
+	Name   string       `json:"name,omitempty"`
+	Fields []frameField `json:"fields"`
+	// Rows holds the values of each row, in the same order as Fields.
+	// Times are formatted in RFC3339.
+	Rows [][]any `json:"rows"`
+	// TotalRows is the number of rows in the frame, which may be more than
+	// were returned.
+	TotalRows int `json:"totalRows"`
+}
+
+type queryDatasourceResult struct {
+	Frames []frameSummary `json:"frames"`
+}
+
+// summarizeFrame converts a frame from columns to at most maxRows rows.
+func summarizeFrame(frame dataFrame, maxRows int) frameSummary {
+	summary := frameSummary{
+		Name:   frame.Schema.Name,
+		Fields: make([]frameField, 0, len(frame.Schema.Fields)),
+		Rows:   [][]any{},
+	}
+	for _, f := range frame.Schema.Fields {
+		summary.Fields = append(summary.Fields, frameField{Name: f.Name, Type: f.Type, Labels: f.Labels})
+	}
+	for _, values := range frame.Data.Values {
+		summary.TotalRows = max(summary.TotalRows, len(values))
+	}
+	for i := 0; i < min(summary.TotalRows, maxRows); i++ {
+		row := make([]any, len(frame.Data.Values))
+		for j, values := range frame.Data.Values {
+			if i >= len(values) {
+				continue
+			}
+			row[j] = values[i]
+			if j < len(summary.Fields) && summary.Fields[j].Type == "time" {
+				if ms, ok := values[i].(float64); ok {
+					row[j] = time.UnixMilli(int64(ms)).UTC().Format(time.RFC3339)
+				}
+			}
+		}
+		summary.Rows = append(summary.Rows, row)
+	}
+	return summary
+}
+
+// expressionDatasourceUID is the UID of the datasource for server-side
+// expressions, such as the reduce and threshold steps of alert rules.
+const expressionDatasourceUID = "__expr__"
+
+// readOnlyDatasourceTypes are the types of datasource whose queries can only
+// read data. Others, such as SQL datasources, will run any statement they're
+// given, so checkReadOnlyDatasources refuses to query them in read-only mode.
+var readOnlyDatasourceTypes = map[string]bool{
+	"prometheus":                   true,
+	"loki":                         true,
+	"tempo":                        true,
+	"grafana-pyroscope-datasource": true,
+	"elasticsearch":                true,
+	"graphite":                     true,
+	"jaeger":                       true,
+	"zipkin":                       true,
+	"cloudwatch":                   true,
+	"grafana-testdata-datasource":  true,
+}
+
+// checkReadOnlyDatasources returns a ToolError if the server is in read-only
+// mode and any of the datasources with the given UIDs aren't one of
+// readOnlyDatasourceTypes. Server-side expressions are always allowed.
+func checkReadOnlyDatasources(ctx context.Context, uids []string) error {
+	if !mcpgrafana.ReadOnlyFromContext(ctx) {
+		return nil
+	}
+	for _, uid := range uids {
+		if uid == expressionDatasourceUID {
+			continue
+		}
+		ds, err := getDatasourceByUID(ctx, GetDatasourceByUIDParams{UID: uid})
+		if err != nil {
+			return err
+		}
+		if !readOnlyDatasourceTypes[strings.ToLower(ds.Type)] {
+			return mcpgrafana.NewToolError(fmt.Errorf("datasource %s has type %s, which can't be queried in read-only mode", uid, ds.Type))
+		}
+	}
+	return nil
+}
+
+// postDatasourceQueries runs queries, each with a refId and datasource, over
+// the time range using Grafana's /api/ds/query endpoint. The result of each
+// query, including any error, is keyed by its refId.
+func postDatasourceQueries(ctx context.Context, queries []map[string]any, start, end time.Time) (*queryDataResponse, error) {
+	uids := make([]string, 0, len(queries))
+	for _, query := range queries {
+		uids = append(uids, datasourceUID(query["datasource"]))
+	}
+	if err := checkReadOnlyDatasources(ctx, uids); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"queries": queries,
+		"from":    strconv.FormatInt(start.UnixMilli(), 10),
+		"to":      strconv.FormatInt(end.UnixMilli(), 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
+
+	grafanaURL := strings.TrimRight(mcpgrafana.GrafanaURLFromContext(ctx), "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, grafanaURL+"/api/ds/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Transport: newAuthRoundTripper(ctx, newTransport(ctx)),
+		Timeout:   clientTimeout(ctx),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024*48))
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	// Grafana returns 207 Multi-Status if any of the queries failed, with
+	// the error in its result.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		return nil, proxyStatusError(resp.StatusCode, fmt.Errorf("query datasource returned status code %d: %s", resp.StatusCode, string(respBody)))
+	}
+	var data queryDataResponse
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		return nil, fmt.Errorf("unmarshalling response: %w", err)
+	}
+	return &data, nil
+}
+
+func queryDatasource(ctx context.Context, args QueryDatasourceParams) (*queryDatasourceResult, error) {
+	if len(args.Query) == 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("query is required"))
+	}
+	if args.MaxRows < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid maxRows: %d, must be greater than 0", args.MaxRows))
+	}
+	start, end, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+
+	query := make(map[string]any, len(args.Query)+2)
+	for k, v := range args.Query {
+		query[k] = v
+	}
+	query["refId"] = "A"
+	query["datasource"] = map[string]any{"uid": args.DatasourceUID}
+	data, err := postDatasourceQueries(ctx, []map[string]any{query}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := data.Results["A"]
+	if !ok {
+		return nil, fmt.Errorf("query datasource: no result in response")
+	}
+	if result.Error != "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("query datasource: %s", result.Error))
+	}
+
+	maxRows := DefaultQueryDatasourceMaxRows
+	if args.MaxRows > 0 {
+		maxRows = args.MaxRows
+	}
+	frames := make([]frameSummary, 0, len(result.Frames))
+	for _, frame := range result.Frames {
+		frames = append(frames, summarizeFrame(frame, maxRows))
+	}
+	return &queryDatasourceResult{Frames: frames}, nil
+}
+
+var QueryDatasource = mcpgrafana.MustTool(
+	"query_datasource",
+	"Run a query against any datasource using Grafana's /api/ds/query endpoint, with the same query model the Grafana frontend uses for the datasource type. Use this for datasources which don't have a dedicated tool, such as SQL databases or Graphite. Returns the resulting data frames, each with its fields (name, type and labels) and rows, truncated to maxRows",
+	queryDatasource,
+)
@@ -0,0 +1,39 @@
+// Requires a Grafana instance running on localhost:3000,
+// with a Prometheus datasource provisioned.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryDatasource(t *testing.T) {
+	t.Run("query prometheus", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := queryDatasource(ctx, QueryDatasourceParams{
+			DatasourceUID: "prometheus",
+			Query:         map[string]any{"expr": "up", "instant": true},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Frames)
+		assert.NotEmpty(t, result.Frames[0].Fields)
+		assert.NotEmpty(t, result.Frames[0].Rows)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := queryDatasource(ctx, QueryDatasourceParams{
+			DatasourceUID: "prometheus",
+			Query:         map[string]any{"expr": "up{", "instant": true},
+		})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
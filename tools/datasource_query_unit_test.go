@@ -0,0 +1,84 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeFrame(t *testing.T) {
+	var frame dataFrame
+	require.NoError(t, json.Unmarshal([]byte(`{
+	  "schema": {
+	    "name": "up",
+	    "fields": [
+	      {"name": "Time", "type": "time"},
+	      {"name": "Value", "type": "number", "labels": {"job": "grafana"}}
+	    ]
+	  },
+	  "data": {"values": [[1700000000000, 1700000060000, 1700000120000], [1, 0, 1]]}
+	}`), &frame))
+
+	t.Run("all rows", func(t *testing.T) {
+		summary := summarizeFrame(frame, 10)
+		assert.Equal(t, frameSummary{
+			Name: "up",
+			Fields: []frameField{
+				{Name: "Time", Type: "time"},
+				{Name: "Value", Type: "number", Labels: map[string]string{"job": "grafana"}},
+			},
+			Rows: [][]any{
+				{"2023-11-14T22:13:20Z", float64(1)},
+				{"2023-11-14T22:14:20Z", float64(0)},
+				{"2023-11-14T22:15:20Z", float64(1)},
+			},
+			TotalRows: 3,
+		}, summary)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		summary := summarizeFrame(frame, 1)
+		assert.Len(t, summary.Rows, 1)
+		assert.Equal(t, 3, summary.TotalRows)
+	})
+}
+
+func TestQueryDatasourceReadOnly(t *testing.T) {
+	queried := 0
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		queried++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results": {"A": {"frames": []}}}`))
+	})
+
+	t.Run("sql datasource", func(t *testing.T) {
+		args := QueryDatasourceParams{DatasourceUID: "postgres", Query: map[string]any{"rawSql": "SELECT 1"}}
+		_, err := queryDatasource(ctx, args)
+		require.NoError(t, err)
+		assert.Equal(t, 1, queried)
+
+		_, err = queryDatasource(mcpgrafana.WithReadOnly(ctx, true), args)
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "grafana-postgresql-datasource")
+		assert.Equal(t, 1, queried)
+	})
+
+	t.Run("prometheus", func(t *testing.T) {
+		_, err := queryDatasource(mcpgrafana.WithReadOnly(ctx, true), QueryDatasourceParams{DatasourceUID: "prometheus", Query: map[string]any{"expr": "up"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, queried)
+	})
+
+	t.Run("expressions", func(t *testing.T) {
+		require.NoError(t, checkReadOnlyDatasources(mcpgrafana.WithReadOnly(ctx, true), []string{"prometheus", expressionDatasourceUID}))
+	})
+}
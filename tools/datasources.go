@@ -2,17 +2,22 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/grafana/grafana-openapi-client-go/client/datasources"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	mcpgrafana "github.com/grafana/mcp-grafana"
 )
 
 type ListDatasourcesParams struct {
-	Type string `json:"type,omitempty" jsonschema:"descripton=The type of datasources to search for. For example, 'prometheus', 'loki', 'tempo', etc..."`
+	Type string `json:"type,omitempty" jsonschema:"description=Optionally\\, the type of datasources to return\\, matched exactly but case-insensitively. For example\\, 'prometheus'\\, 'loki'\\, 'tempo'\\, etc..."`
 }
 
 type dataSourceSummary struct {
@@ -24,25 +29,33 @@ type dataSourceSummary struct {
 }
 
 func listDatasources(ctx context.Context, args ListDatasourcesParams) ([]dataSourceSummary, error) {
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
-	resp, err := c.Datasources.GetDataSources()
+	all, err := cachedDatasourceLookup(ctx, "list", "", func() (models.DataSourceList, error) {
+		c, err := mcpgrafana.RequireGrafanaClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Datasources.GetDataSources()
+		if err != nil {
+			return nil, grafanaAPIError(err)
+		}
+		return resp.Payload, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("list datasources: %w", err)
 	}
-	datasources := filterDatasources(resp.Payload, args.Type)
+	datasources := filterDatasources(all, args.Type)
 	return summarizeDatasources(datasources), nil
 }
 
-// filterDatasources returns only datasources of the specified type `t`. If `t`
-// is an empty string no filtering is done.
+// filterDatasources returns only datasources of the specified type `t`,
+// ignoring case. If `t` is an empty string no filtering is done.
 func filterDatasources(datasources models.DataSourceList, t string) models.DataSourceList {
 	if t == "" {
 		return datasources
 	}
 	filtered := models.DataSourceList{}
-	t = strings.ToLower(t)
 	for _, ds := range datasources {
-		if strings.Contains(strings.ToLower(ds.Type), t) {
+		if strings.EqualFold(ds.Type, t) {
 			filtered = append(filtered, ds)
 		}
 	}
@@ -74,12 +87,51 @@ type GetDatasourceByUIDParams struct {
 }
 
 func getDatasourceByUID(ctx context.Context, args GetDatasourceByUIDParams) (*models.DataSource, error) {
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
-	datasource, err := c.Datasources.GetDataSourceByUID(args.UID)
+	datasource, err := cachedDatasourceLookup(ctx, "uid", args.UID, func() (*models.DataSource, error) {
+		c, err := mcpgrafana.RequireGrafanaClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Datasources.GetDataSourceByUID(args.UID)
+		if err != nil {
+			return nil, grafanaAPIError(err)
+		}
+		return resp.Payload, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("get datasource by uid %s: %w", args.UID, err)
 	}
-	return datasource.Payload, nil
+	return datasource, nil
+}
+
+// requireDatasource returns the datasource with the given UID, or a tool
+// error if it doesn't exist.
+func requireDatasource(ctx context.Context, uid string) (*models.DataSource, error) {
+	ds, err := getDatasourceByUID(ctx, GetDatasourceByUIDParams{UID: uid})
+	if err != nil {
+		var notFound *datasources.GetDataSourceByUIDNotFound
+		if errors.As(err, &notFound) {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("datasource with UID %s not found", uid))
+		}
+		return nil, err
+	}
+	return ds, nil
+}
+
+// checkDatasourceType returns a tool error if the datasource with the given
+// UID doesn't exist or isn't one of the given types, so that querying, say,
+// a Loki datasource with a Prometheus tool gives a clear error rather than a
+// confusing one from the datasource proxy. name is the kind of datasource
+// expected, e.g. "Prometheus".
+func checkDatasourceType(ctx context.Context, uid, name string, types ...string) error {
+	ds, err := requireDatasource(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(types, ds.Type) {
+		return mcpgrafana.NewToolError(fmt.Errorf("UID %s is a %s datasource, not %s", uid, ds.Type, name))
+	}
+	return nil
 }
 
 var GetDatasourceByUID = mcpgrafana.MustTool(
@@ -93,12 +145,21 @@ type GetDatasourceByNameParams struct {
 }
 
 func getDatasourceByName(ctx context.Context, args GetDatasourceByNameParams) (*models.DataSource, error) {
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
-	datasource, err := c.Datasources.GetDataSourceByName(args.Name)
+	datasource, err := cachedDatasourceLookup(ctx, "name", args.Name, func() (*models.DataSource, error) {
+		c, err := mcpgrafana.RequireGrafanaClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Datasources.GetDataSourceByName(args.Name)
+		if err != nil {
+			return nil, grafanaAPIError(err)
+		}
+		return resp.Payload, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("get datasource by name %s: %w", args.Name, err)
 	}
-	return datasource.Payload, nil
+	return datasource, nil
 }
 
 var GetDatasourceByName = mcpgrafana.MustTool(
@@ -107,8 +168,103 @@ var GetDatasourceByName = mcpgrafana.MustTool(
 	getDatasourceByName,
 )
 
-func AddDatasourceTools(mcp *server.MCPServer) {
-	ListDatasources.Register(mcp)
-	GetDatasourceByUID.Register(mcp)
-	GetDatasourceByName.Register(mcp)
+type GetDatasourceByIDParams struct {
+	ID int64 `json:"id" jsonschema:"required,description=The numeric id of the datasource. Prefer the uid where possible"`
+}
+
+func getDatasourceByID(ctx context.Context, args GetDatasourceByIDParams) (*models.DataSource, error) {
+	if args.ID <= 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("id must be a positive integer, got %d", args.ID))
+	}
+	id := strconv.FormatInt(args.ID, 10)
+	datasource, err := cachedDatasourceLookup(ctx, "id", id, func() (*models.DataSource, error) {
+		c, err := mcpgrafana.RequireGrafanaClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.Datasources.GetDataSourceByID(id)
+		if err != nil {
+			return nil, grafanaAPIError(err)
+		}
+		return resp.Payload, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get datasource by id %d: %w", args.ID, err)
+	}
+	return datasource, nil
+}
+
+var GetDatasourceByID = mcpgrafana.MustTool(
+	"get_datasource_by_id",
+	"Get datasource by numeric id. Prefer get_datasource_by_uid where possible",
+	getDatasourceByID,
+)
+
+type CheckDatasourceHealthParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The uid of the datasource"`
+}
+
+type datasourceHealth struct {
+	UID string `json:"uid"`
+	// Status is "OK" if the datasource is healthy and "ERROR" if Grafana
+	// could reach the datasource but it reported a problem.
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+// checkDatasourceHealth runs the datasource's health check through Grafana.
+//
+// An unhealthy datasource is reported in the result with status "ERROR"; an
+// error is only returned if the health check itself could not be run, for
+// example because Grafana was unreachable or the datasource does not exist.
+func checkDatasourceHealth(ctx context.Context, args CheckDatasourceHealthParams) (*datasourceHealth, error) {
+	if args.UID == "" {
+		return nil, fmt.Errorf("uid is required")
+	}
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check datasource health: %w", err)
+	}
+	params := datasources.NewCheckDatasourceHealthWithUIDParamsWithContext(ctx).WithUID(args.UID)
+	start := time.Now()
+	resp, err := c.Datasources.CheckDatasourceHealthWithUIDWithParams(params)
+	latency := time.Since(start).Milliseconds()
+
+	var unhealthy *datasources.CheckDatasourceHealthWithUIDBadRequest
+	switch {
+	case errors.As(err, &unhealthy):
+		health := &datasourceHealth{UID: args.UID, Status: "ERROR", LatencyMs: latency}
+		if unhealthy.Payload != nil && unhealthy.Payload.Message != nil {
+			health.Message = *unhealthy.Payload.Message
+		}
+		return health, nil
+	case err != nil:
+		return nil, fmt.Errorf("check datasource health %s: %w", args.UID, grafanaAPIError(err))
+	}
+	return &datasourceHealth{
+		UID:       args.UID,
+		Status:    "OK",
+		Message:   resp.Payload.Message,
+		LatencyMs: latency,
+	}, nil
+}
+
+var CheckDatasourceHealth = mcpgrafana.MustTool(
+	"check_datasource_health",
+	"Check whether a datasource is healthy and reachable from Grafana. Returns the status ('OK' or 'ERROR'), the message from the health check and the latency of the check in milliseconds. Fails if the health check itself could not be run, for example if the datasource does not exist.",
+	checkDatasourceHealth,
+)
+
+func AddDatasourceTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "datasources",
+		ListDatasources,
+		GetDatasourceByUID,
+		GetDatasourceByName,
+		GetDatasourceByID,
+		CheckDatasourceHealth,
+		QueryDatasourceProxy,
+		GetDatasourceProxyURL,
+		QueryDatasource,
+	)
 }
@@ -65,6 +65,16 @@ func TestDatasourcesTools(t *testing.T) {
 		assert.Len(t, result, 2)
 	})
 
+	t.Run("list datasources for type is case-insensitive", func(t *testing.T) {
+		ctx := newTestContext()
+		for _, typ := range []string{"loki", "LOKI", "Loki"} {
+			result, err := listDatasources(ctx, ListDatasourcesParams{Type: typ})
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+			assert.Equal(t, "loki", result[0].Type)
+		}
+	})
+
 	t.Run("get datasource by uid", func(t *testing.T) {
 		ctx := newTestContext()
 		result, err := getDatasourceByUID(ctx, GetDatasourceByUIDParams{
@@ -82,4 +92,47 @@ func TestDatasourcesTools(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "Prometheus", result.Name)
 	})
+
+	t.Run("get datasource by id", func(t *testing.T) {
+		ctx := newTestContext()
+		byUID, err := getDatasourceByUID(ctx, GetDatasourceByUIDParams{
+			UID: "prometheus",
+		})
+		require.NoError(t, err)
+
+		result, err := getDatasourceByID(ctx, GetDatasourceByIDParams{
+			ID: byUID.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Prometheus", result.Name)
+		assert.Equal(t, "prometheus", result.UID)
+	})
+
+	t.Run("get datasource by id - invalid id", func(t *testing.T) {
+		ctx := newTestContext()
+		for _, id := range []int64{0, -1} {
+			_, err := getDatasourceByID(ctx, GetDatasourceByIDParams{ID: id})
+			var toolErr *mcpgrafana.ToolError
+			require.ErrorAs(t, err, &toolErr)
+		}
+	})
+
+	t.Run("check datasource health", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := checkDatasourceHealth(ctx, CheckDatasourceHealthParams{
+			UID: "prometheus",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "prometheus", result.UID)
+		assert.Equal(t, "OK", result.Status)
+		assert.GreaterOrEqual(t, result.LatencyMs, int64(0))
+	})
+
+	t.Run("check datasource health - invalid uid", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := checkDatasourceHealth(ctx, CheckDatasourceHealthParams{
+			UID: "non-existent-uid",
+		})
+		require.Error(t, err)
+	})
 }
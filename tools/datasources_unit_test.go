@@ -0,0 +1,130 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDatasources are the datasources served by newTestGrafanaContext, by UID.
+var testDatasources = map[string]string{
+	"prometheus": "prometheus",
+	"loki":       "loki",
+	"postgres":   "grafana-postgresql-datasource",
+}
+
+// newTestGrafanaContext starts a fake Grafana server which serves the
+// testDatasources, passing all other requests to handler, and returns a
+// context with its URL and a client for it.
+func newTestGrafanaContext(t *testing.T, handler http.HandlerFunc) context.Context {
+	useTestDatasourceCache(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uid, ok := strings.CutPrefix(r.URL.Path, "/api/datasources/uid/")
+		if !ok {
+			handler(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		dsType, ok := testDatasources[uid]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Data source not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.DataSource{UID: uid, Name: uid, Type: dsType})
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	cfg := client.DefaultTransportConfig().WithHost(u.Host).WithSchemes([]string{"http"})
+	ctx := mcpgrafana.WithGrafanaURL(context.Background(), srv.URL)
+	return mcpgrafana.WithGrafanaClient(ctx, client.NewHTTPClientWithConfig(strfmt.Default, cfg))
+}
+
+func TestGetDatasourceProxyURL(t *testing.T) {
+	ctx := newTestGrafanaContext(t, http.NotFound)
+	ctx = mcpgrafana.WithGrafanaURL(ctx, "https://grafana.example.com/")
+
+	result, err := getDatasourceProxyURL(ctx, GetDatasourceProxyURLParams{DatasourceUID: "loki"})
+	require.NoError(t, err)
+	assert.Equal(t, &datasourceProxyURLResult{
+		URL:            "https://grafana.example.com/api/datasources/proxy/uid/loki",
+		DatasourceName: "loki",
+		DatasourceType: "loki",
+	}, result)
+
+	_, err = getDatasourceProxyURL(ctx, GetDatasourceProxyURLParams{DatasourceUID: "missing"})
+	var toolErr *mcpgrafana.ToolError
+	require.True(t, errors.As(err, &toolErr))
+	assert.EqualError(t, err, "datasource with UID missing not found")
+}
+
+func TestCheckDatasourceType(t *testing.T) {
+	ctx := newTestGrafanaContext(t, http.NotFound)
+
+	t.Run("matching type", func(t *testing.T) {
+		assert.NoError(t, checkDatasourceType(ctx, "prometheus", "Prometheus", "prometheus"))
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		err := checkDatasourceType(ctx, "loki", "Prometheus", "prometheus")
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.EqualError(t, err, "UID loki is a loki datasource, not Prometheus")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		err := checkDatasourceType(ctx, "missing", "Prometheus", "prometheus")
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.EqualError(t, err, "datasource with UID missing not found")
+	})
+
+	t.Run("prometheus client", func(t *testing.T) {
+		_, err := promClientFromContext(ctx, "loki")
+		assert.EqualError(t, err, "UID loki is a loki datasource, not Prometheus")
+	})
+
+	t.Run("loki client", func(t *testing.T) {
+		_, err := newLokiClient(ctx, "prometheus")
+		assert.EqualError(t, err, "UID prometheus is a prometheus datasource, not Loki")
+	})
+}
+
+func TestFilterDatasources(t *testing.T) {
+	datasources := models.DataSourceList{
+		{UID: "prom", Type: "prometheus"},
+		{UID: "amp", Type: "grafana-amazonprometheus-datasource"},
+		{UID: "mysql", Type: "mysql"},
+		{UID: "loki", Type: "loki"},
+	}
+	uids := func(dss models.DataSourceList) []string {
+		result := []string{}
+		for _, ds := range dss {
+			result = append(result, ds.UID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"prom", "amp", "mysql", "loki"}, uids(filterDatasources(datasources, "")))
+	assert.Equal(t, []string{"prom"}, uids(filterDatasources(datasources, "prometheus")))
+	assert.Equal(t, []string{"prom"}, uids(filterDatasources(datasources, "Prometheus")))
+	// Types are only matched in full.
+	assert.Empty(t, uids(filterDatasources(datasources, "sql")))
+}
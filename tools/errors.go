@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// statusCoder is implemented by the errors of the Grafana OpenAPI client,
+// both the typed responses such as dashboards.GetDashboardByUIDForbidden and
+// runtime.APIError for responses without a type.
+type statusCoder interface {
+	IsCode(code int) bool
+}
+
+// grafanaAPIError explains an error from the Grafana OpenAPI client if
+// Grafana rejected the request's credentials or permissions, using
+// grafanaStatusError. Other errors are returned unchanged.
+func grafanaAPIError(err error) error {
+	var sc statusCoder
+	if !errors.As(err, &sc) {
+		return err
+	}
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		if sc.IsCode(code) {
+			return grafanaStatusError(code, err)
+		}
+	}
+	return err
+}
+
+// grafanaStatusError returns err, the error for a response from the Grafana
+// API with the given status code, as a tool error with an explanation if the
+// status is 401 Unauthorized or 403 Forbidden, since these can only be fixed
+// by changing the server's credentials or their permissions.
+func grafanaStatusError(statusCode int, err error) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return mcpgrafana.NewToolError(fmt.Errorf("authentication failed: check GRAFANA_API_KEY / service account permissions: %w", err))
+	case http.StatusForbidden:
+		return mcpgrafana.NewToolError(fmt.Errorf("permission denied: check the permissions of the service account or user for GRAFANA_API_KEY: %w", err))
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+func TestGrafanaAPIError(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{"unauthorized", dashboards.NewGetDashboardByUIDUnauthorized(), "authentication failed: check GRAFANA_API_KEY / service account permissions"},
+		{"forbidden", dashboards.NewGetDashboardByUIDForbidden(), "permission denied"},
+		{"untyped unauthorized", runtime.NewAPIError("unknown error", nil, http.StatusUnauthorized), "authentication failed"},
+		{"wrapped forbidden", fmt.Errorf("wrapped: %w", dashboards.NewGetDashboardByUIDForbidden()), "permission denied"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := grafanaAPIError(tc.err)
+			var toolErr *mcpgrafana.ToolError
+			require.True(t, errors.As(err, &toolErr))
+			assert.Contains(t, err.Error(), tc.message)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		for _, err := range []error{
+			dashboards.NewGetDashboardByUIDNotFound(),
+			runtime.NewAPIError("unknown error", nil, http.StatusInternalServerError),
+			errors.New("connection refused"),
+		} {
+			assert.Equal(t, err, grafanaAPIError(err))
+		}
+	})
+}
+
+func TestGetDashboardByUIDUnauthorized(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"invalid API key"}`))
+	})
+
+	_, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: "abc"})
+	var toolErr *mcpgrafana.ToolError
+	require.True(t, errors.As(err, &toolErr))
+	assert.Contains(t, err.Error(), "authentication failed: check GRAFANA_API_KEY / service account permissions")
+}
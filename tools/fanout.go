@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"sync"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// requestSemaphores holds a semaphore for each configured limit on concurrent
+// requests. They are shared by all tool calls, so that several calls fanning
+// out at once don't multiply the load on Grafana.
+var requestSemaphores sync.Map // map[int]*semaphore.Weighted
+
+// requestSemaphore returns the shared semaphore for the limit on concurrent
+// requests in the context.
+func requestSemaphore(ctx context.Context) *semaphore.Weighted {
+	n := mcpgrafana.MaxConcurrentRequestsFromContext(ctx)
+	if sem, ok := requestSemaphores.Load(n); ok {
+		return sem.(*semaphore.Weighted)
+	}
+	sem, _ := requestSemaphores.LoadOrStore(n, semaphore.NewWeighted(int64(n)))
+	return sem.(*semaphore.Weighted)
+}
+
+// forEachConcurrently calls fn for each index in [0, n), with at most the
+// configured number of concurrent requests in flight across all tool calls.
+// fn should make a single request. As with errgroup, the context passed to fn
+// is cancelled once any call fails, and the first error is returned.
+func forEachConcurrently(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	sem := requestSemaphore(ctx)
+	g, gctx := errgroup.WithContext(ctx)
+	var acquireErr error
+	for i := range n {
+		if acquireErr = sem.Acquire(gctx, 1); acquireErr != nil {
+			break
+		}
+		g.Go(func() error {
+			defer sem.Release(1)
+			return fn(gctx, i)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return acquireErr
+}
@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachConcurrently(t *testing.T) {
+	t.Run("limits concurrency across calls", func(t *testing.T) {
+		ctx := mcpgrafana.WithMaxConcurrentRequests(context.Background(), 3)
+		var inFlight, maxInFlight atomic.Int32
+		fn := func(ctx context.Context, i int) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		}
+
+		done := make(chan error)
+		for range 2 {
+			go func() { done <- forEachConcurrently(ctx, 10, fn) }()
+		}
+		require.NoError(t, <-done)
+		require.NoError(t, <-done)
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	})
+
+	t.Run("calls fn for each index", func(t *testing.T) {
+		seen := make([]bool, 20)
+		err := forEachConcurrently(context.Background(), len(seen), func(ctx context.Context, i int) error {
+			seen[i] = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, seen, false)
+	})
+
+	t.Run("returns the first error", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := forEachConcurrently(context.Background(), 5, func(ctx context.Context, i int) error {
+			if i == 2 {
+				return errBoom
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errBoom)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx := mcpgrafana.WithMaxConcurrentRequests(context.Background(), 1)
+		ctx, cancel := context.WithCancel(ctx)
+		err := forEachConcurrently(ctx, 5, func(ctx context.Context, i int) error {
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/grafana/grafana-openapi-client-go/client/folders"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+type ListFoldersParams struct {
+	ParentUID string `json:"parentUid,omitempty" jsonschema:"description=Optionally\\, the UID of the parent folder to list the subfolders of. If not provided\\, the top-level folders are returned"`
+}
+
+type folderSummary struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUID string `json:"parentUid,omitempty"`
+}
+
+func listFolders(ctx context.Context, args ListFoldersParams) ([]folderSummary, error) {
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+	params := folders.NewGetFoldersParamsWithContext(ctx)
+	if args.ParentUID != "" {
+		params.SetParentUID(&args.ParentUID)
+	}
+	response, err := c.Folders.GetFolders(params)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+	return summarizeFolders(response.Payload), nil
+}
+
+func summarizeFolders(hits []*models.FolderSearchHit) []folderSummary {
+	result := make([]folderSummary, 0, len(hits))
+	for _, f := range hits {
+		if f == nil {
+			continue
+		}
+		result = append(result, folderSummary{
+			UID:       f.UID,
+			Title:     f.Title,
+			ParentUID: f.ParentUID,
+		})
+	}
+	return result
+}
+
+var ListFolders = mcpgrafana.MustTool(
+	"list_folders",
+	"List folders, returning the UID, title and parent UID of each. Use this to resolve a folder name to a UID",
+	listFolders,
+)
+
+func AddFolderTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "folder",
+		ListFolders,
+	)
+}
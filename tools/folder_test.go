@@ -0,0 +1,28 @@
+// Requires a Grafana instance running on localhost:3000,
+// with alert rules provisioned in the "Test Alerts" folder.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderTools(t *testing.T) {
+	t.Run("list folders", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listFolders(ctx, ListFoldersParams{})
+		require.NoError(t, err)
+
+		titles := make([]string, 0, len(result))
+		for _, f := range result {
+			assert.NotEmpty(t, f.UID)
+			titles = append(titles, f.Title)
+		}
+		assert.Contains(t, titles, "Test Alerts")
+	})
+}
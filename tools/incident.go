@@ -1,8 +1,16 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/grafana/incident-go"
 	mcpgrafana "github.com/grafana/mcp-grafana"
@@ -10,32 +18,97 @@ import (
 )
 
 type ListIncidentsParams struct {
-	Limit  int    `json:"limit" jsonschema:"description=The maximum number of incidents to return"`
-	Drill  bool   `json:"drill" jsonschema:"description=Whether to include drill incidents"`
-	Status string `json:"status" jsonschema:"description=The status of the incidents to include"`
+	Limit          int    `json:"limit" jsonschema:"description=The maximum number of incidents to return"`
+	Drill          bool   `json:"drill" jsonschema:"description=Whether to include drill incidents"`
+	Status         string `json:"status" jsonschema:"description=The status of the incidents to include,enum=active,enum=resolved"`
+	Page           int    `json:"page,omitempty" jsonschema:"description=Optionally\\, the page of results to return\\, starting at 1. Each page contains up to 'limit' incidents. Check 'cursor.hasMore' in the response to see if there are more pages"`
+	Query          string `json:"query,omitempty" jsonschema:"description=Optionally\\, an additional filter in the Grafana Incident query language which is ANDed with the other filters. Terms have the form 'key:value' and are combined with 'and'\\, for example 'label:service:api and severity:critical'. Supported keys include label\\, severity\\, status\\, title\\, isdrill\\, createdBy and started (e.g. 'started>2024-01-01')"`
+	OrderDirection string `json:"orderDirection,omitempty" jsonschema:"description=Optionally\\, the direction to order incidents in. Defaults to 'DESC'\\, newest first,enum=ASC,enum=DESC"`
+	OrderField     string `json:"orderField,omitempty" jsonschema:"description=Optionally\\, the field to order incidents by. Defaults to 'createdTime'"`
+	CreatedAfter   string `json:"createdAfter,omitempty" jsonschema:"description=Optionally\\, only return incidents created after this time in RFC3339 format"`
+	CreatedBefore  string `json:"createdBefore,omitempty" jsonschema:"description=Optionally\\, only return incidents created before this time in RFC3339 format"`
 }
 
-func listIncidents(ctx context.Context, args ListIncidentsParams) (*incident.QueryIncidentPreviewsResponse, error) {
-	c := mcpgrafana.IncidentClientFromContext(ctx)
-	is := incident.NewIncidentsService(c)
-	query := ""
+func (p ListIncidentsParams) validate() error {
+	if p.Page < 0 {
+		return fmt.Errorf("page must not be negative, got %d", p.Page)
+	}
+	if p.OrderDirection != "" && p.OrderDirection != "ASC" && p.OrderDirection != "DESC" {
+		return fmt.Errorf("invalid orderDirection: %s, must be 'ASC' or 'DESC'", p.OrderDirection)
+	}
+	return nil
+}
+
+// buildIncidentQuery builds a query string in the Grafana Incident query
+// language from the filters in `args`.
+func buildIncidentQuery(args ListIncidentsParams) (string, error) {
+	terms := []string{}
 	if !args.Drill {
-		query = "isdrill:false"
+		terms = append(terms, "isdrill:false")
 	}
 	if args.Status != "" {
-		query += fmt.Sprintf(" and status:%s", args.Status)
+		terms = append(terms, fmt.Sprintf("status:%s", args.Status))
+	}
+	for _, bound := range []struct{ name, op, value string }{
+		{"createdAfter", ">", args.CreatedAfter},
+		{"createdBefore", "<", args.CreatedBefore},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return "", fmt.Errorf("parsing %s: %w", bound.name, err)
+		}
+		terms = append(terms, fmt.Sprintf("started%s%s", bound.op, t.UTC().Format(time.RFC3339)))
+	}
+	if q := strings.TrimSpace(args.Query); q != "" {
+		if strings.ContainsFunc(q, unicode.IsControl) {
+			return "", fmt.Errorf("query must not contain control characters")
+		}
+		terms = append(terms, q)
+	}
+	return strings.Join(terms, " and "), nil
+}
+
+func listIncidents(ctx context.Context, args ListIncidentsParams) (*incident.QueryIncidentPreviewsResponse, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list incidents: %w", err))
+	}
+	query, err := buildIncidentQuery(args)
+	if err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list incidents: %w", err))
 	}
-	incidents, err := is.QueryIncidentPreviews(ctx, incident.QueryIncidentPreviewsRequest{
+	orderDirection := args.OrderDirection
+	if orderDirection == "" {
+		orderDirection = "DESC"
+	}
+	c := mcpgrafana.IncidentClientFromContext(ctx)
+	is := incident.NewIncidentsService(c)
+	request := incident.QueryIncidentPreviewsRequest{
 		Query: incident.IncidentPreviewsQuery{
 			QueryString:    query,
-			OrderDirection: "DESC",
+			OrderDirection: orderDirection,
+			OrderField:     args.OrderField,
 			Limit:          args.Limit,
 		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("list incidents: %w", err)
 	}
-	return incidents, nil
+	// The API is cursor-based, so earlier pages have to be fetched to find
+	// the cursor of the requested page.
+	for page := 1; ; page++ {
+		incidents, err := is.QueryIncidentPreviews(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("list incidents: %w", err)
+		}
+		if page >= args.Page {
+			return incidents, nil
+		}
+		if !incidents.Cursor.HasMore {
+			incidents.IncidentPreviews = []incident.IncidentPreview{}
+			return incidents, nil
+		}
+		request.Cursor = incidents.Cursor
+	}
 }
 
 var ListIncidents = mcpgrafana.MustTool(
@@ -46,10 +119,10 @@ var ListIncidents = mcpgrafana.MustTool(
 
 type CreateIncidentParams struct {
 	Title         string                   `json:"title" jsonschema:"description=The title of the incident"`
-	Severity      string                   `json:"severity" jsonschema:"description=The severity of the incident"`
+	Severity      string                   `json:"severity" jsonschema:"description=The severity of the incident. Use list_incident_severities to find the valid values"`
 	RoomPrefix    string                   `json:"roomPrefix" jsonschema:"description=The prefix of the room to create the incident in"`
 	IsDrill       bool                     `json:"isDrill" jsonschema:"description=Whether the incident is a drill incident"`
-	Status        string                   `json:"status" jsonschema:"description=The status of the incident"`
+	Status        string                   `json:"status" jsonschema:"description=The status of the incident,enum=active,enum=resolved"`
 	AttachCaption string                   `json:"attachCaption" jsonschema:"description=The caption of the attachment"`
 	AttachURL     string                   `json:"attachUrl" jsonschema:"description=The URL of the attachment"`
 	Labels        []incident.IncidentLabel `json:"labels" jsonschema:"description=The labels to add to the incident"`
@@ -78,20 +151,35 @@ var CreateIncident = mcpgrafana.MustTool(
 	"create_incident",
 	"Create an incident",
 	createIncident,
-)
+).AsMutating()
 
 type AddActivityToIncidentParams struct {
-	IncidentID string `json:"incidentId" jsonschema:"description=The ID of the incident to add the activity to"`
-	Body       string `json:"body" jsonschema:"description=The body of the activity. URLs will be parsed and attached as context"`
-	EventTime  string `json:"eventTime" jsonschema:"description=The time that the activity occurred. If not provided, the current time will be used"`
+	IncidentID   string `json:"incidentId" jsonschema:"description=The ID of the incident to add the activity to"`
+	Body         string `json:"body" jsonschema:"description=The body of the activity. URLs will be parsed and attached as context"`
+	EventTime    string `json:"eventTime" jsonschema:"description=The time that the activity occurred. If not provided, the current time will be used"`
+	ActivityKind string `json:"activityKind,omitempty" jsonschema:"description=Optionally\\, the kind of activity to add. Defaults to 'userNote',enum=userNote"`
+}
+
+// addableActivityKinds are the activity kinds which the incident API accepts
+// when adding activity to an incident. Other kinds, such as
+// incidentStatusChanged, are only recorded by the API itself.
+var addableActivityKinds = []string{
+	incident.Options.AddActivityRequestActivityKind.UserNote,
 }
 
 func addActivityToIncident(ctx context.Context, args AddActivityToIncidentParams) (*incident.ActivityItem, error) {
+	kind := args.ActivityKind
+	if kind == "" {
+		kind = incident.Options.AddActivityRequestActivityKind.UserNote
+	}
+	if !slices.Contains(addableActivityKinds, kind) {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("add activity to incident: invalid activityKind %q, must be one of %s", kind, strings.Join(addableActivityKinds, ", ")))
+	}
 	c := mcpgrafana.IncidentClientFromContext(ctx)
 	as := incident.NewActivityService(c)
 	activity, err := as.AddActivity(ctx, incident.AddActivityRequest{
 		IncidentID:   args.IncidentID,
-		ActivityKind: "userNote",
+		ActivityKind: kind,
 		Body:         args.Body,
 		EventTime:    args.EventTime,
 	})
@@ -105,10 +193,330 @@ var AddActivityToIncident = mcpgrafana.MustTool(
 	"add_activity_to_incident",
 	"Add an activity to an incident",
 	addActivityToIncident,
+).AsMutating()
+
+type GetIncidentParams struct {
+	IncidentID string `json:"incidentId" jsonschema:"required,description=The ID of the incident to retrieve"`
+}
+
+func getIncident(ctx context.Context, args GetIncidentParams) (*incident.Incident, error) {
+	if args.IncidentID == "" {
+		return nil, fmt.Errorf("incidentId is required")
+	}
+	c := mcpgrafana.IncidentClientFromContext(ctx)
+	is := incident.NewIncidentsService(c)
+	resp, err := is.GetIncident(ctx, incident.GetIncidentRequest{
+		IncidentID: args.IncidentID,
+	})
+	// The incident API reports errors as plain strings, so a missing incident
+	// can only be detected from the message or an empty response.
+	if (err != nil && strings.Contains(strings.ToLower(err.Error()), "not found")) ||
+		(err == nil && resp.Incident.IncidentID == "") {
+		return nil, fmt.Errorf("incident %s not found", args.IncidentID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get incident %s: %w", args.IncidentID, err)
+	}
+	return &resp.Incident, nil
+}
+
+var GetIncident = mcpgrafana.MustTool(
+	"get_incident",
+	"Get the full details of a single incident by ID, including its roles, severity, status, labels and timestamps",
+	getIncident,
+)
+
+type ResolveIncidentParams struct {
+	IncidentID string `json:"incidentId" jsonschema:"required,description=The ID of the incident to resolve"`
+	Summary    string `json:"summary,omitempty" jsonschema:"description=Optionally\\, a summary of the resolution. It is added to the incident's timeline before it is resolved"`
+}
+
+func resolveIncident(ctx context.Context, args ResolveIncidentParams) (*incident.Incident, error) {
+	if args.IncidentID == "" {
+		return nil, fmt.Errorf("incidentId is required")
+	}
+	c := mcpgrafana.IncidentClientFromContext(ctx)
+	if args.Summary != "" {
+		as := incident.NewActivityService(c)
+		_, err := as.AddActivity(ctx, incident.AddActivityRequest{
+			IncidentID:   args.IncidentID,
+			ActivityKind: "userNote",
+			Body:         args.Summary,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("add summary to incident: %w", err)
+		}
+	}
+	is := incident.NewIncidentsService(c)
+	resp, err := is.UpdateStatus(ctx, incident.UpdateStatusRequest{
+		IncidentID: args.IncidentID,
+		Status:     "resolved",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resolve incident: %w", err)
+	}
+	return &resp.Incident, nil
+}
+
+var ResolveIncident = mcpgrafana.MustTool(
+	"resolve_incident",
+	"Resolve an incident, optionally adding a summary of the resolution to its timeline",
+	resolveIncident,
+).AsMutating()
+
+type AssignIncidentRoleParams struct {
+	IncidentID string `json:"incidentId" jsonschema:"required,description=The ID of the incident"`
+	Role       string `json:"role" jsonschema:"required,description=The name of the role to assign\\, for example 'commander' or 'investigator'"`
+	UserID     string `json:"userId" jsonschema:"required,description=The ID of the user to assign the role to"`
+}
+
+type incidentRoleAssignment struct {
+	IncidentID string `json:"incidentId"`
+	Role       string `json:"role"`
+	// DidChange is false if the user already had the role.
+	DidChange bool `json:"didChange"`
+	// Assignees are all users holding the role after the assignment.
+	Assignees []incident.UserPreview `json:"assignees"`
+}
+
+func assignIncidentRole(ctx context.Context, args AssignIncidentRoleParams) (*incidentRoleAssignment, error) {
+	if args.IncidentID == "" || args.Role == "" || args.UserID == "" {
+		return nil, fmt.Errorf("incidentId, role and userId are required")
+	}
+	c := mcpgrafana.IncidentClientFromContext(ctx)
+	is := incident.NewIncidentsService(c)
+	resp, err := is.AssignRole(ctx, incident.AssignRoleRequest{
+		IncidentID: args.IncidentID,
+		UserID:     args.UserID,
+		Role:       args.Role,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assign role %s on incident %s: %w", args.Role, args.IncidentID, err)
+	}
+	result := &incidentRoleAssignment{
+		IncidentID: resp.Incident.IncidentID,
+		Role:       args.Role,
+		DidChange:  resp.DidChange,
+		Assignees:  []incident.UserPreview{},
+	}
+	for _, a := range resp.Incident.IncidentMembership.Assignments {
+		if strings.EqualFold(a.Role.Name, args.Role) {
+			result.Assignees = append(result.Assignees, a.User)
+		}
+	}
+	return result, nil
+}
+
+var AssignIncidentRole = mcpgrafana.MustTool(
+	"assign_incident_role",
+	"Assign a user to a role, such as commander or investigator, on an incident. Returns the users holding the role afterwards",
+	assignIncidentRole,
+).AsMutating()
+
+type ListIncidentAttachmentsParams struct {
+	IncidentID string `json:"incidentId" jsonschema:"required,description=The ID of the incident"`
+}
+
+type incidentAttachment struct {
+	ActivityItemID string `json:"activityItemId"`
+	// Caption is the text of the activity the attachment was added with.
+	Caption string `json:"caption,omitempty"`
+	URL     string `json:"url"`
+	// Type is "link" for a URL attached to the incident, or the type of an
+	// uploaded file: "file", "image", "video", "audio" or "screenshare".
+	Type         string `json:"type"`
+	FileName     string `json:"fileName,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+	AttachedBy   string `json:"attachedBy,omitempty"`
+	AttachedTime string `json:"attachedTime"`
+}
+
+type incidentAttachmentsResult struct {
+	Attachments []incidentAttachment `json:"attachments"`
+	// Truncated is set if the incident has more activity than was searched
+	// for attachments.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+const (
+	// incidentActivityPageSize is the number of activity items fetched at
+	// once when listing an incident's attachments.
+	incidentActivityPageSize = 100
+	// maxIncidentActivityPages is the number of pages of activity searched
+	// for attachments, to bound the time taken for very long incidents.
+	maxIncidentActivityPages = 10
+)
+
+// incidentAttachments returns the attachments of an activity item: the URL
+// attached as context, if it is one, and any uploaded files which haven't
+// been deleted.
+func incidentAttachments(item incident.ActivityItem) []incidentAttachment {
+	var attachments []incidentAttachment
+	if item.ActivityKind == incident.Options.ActivityItemActivityKind.ContextAttached && item.URL != "" {
+		attachments = append(attachments, incidentAttachment{
+			ActivityItemID: item.ActivityItemID,
+			Caption:        item.Body,
+			URL:            item.URL,
+			Type:           "link",
+			AttachedBy:     item.User.Name,
+			AttachedTime:   item.CreatedTime,
+		})
+	}
+	for _, file := range item.Attachments {
+		if file.DeletedTime != "" {
+			continue
+		}
+		url := file.DownloadURL
+		if file.UseSourceURL || url == "" {
+			url = file.SourceURL
+		}
+		attachments = append(attachments, incidentAttachment{
+			ActivityItemID: item.ActivityItemID,
+			Caption:        item.Body,
+			URL:            url,
+			Type:           file.FileType,
+			FileName:       file.Path,
+			ContentType:    file.ContentType,
+			AttachedBy:     item.User.Name,
+			AttachedTime:   file.UploadTime,
+		})
+	}
+	return attachments
+}
+
+func listIncidentAttachments(ctx context.Context, args ListIncidentAttachmentsParams) (*incidentAttachmentsResult, error) {
+	if args.IncidentID == "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("incidentId is required"))
+	}
+	c := mcpgrafana.IncidentClientFromContext(ctx)
+	as := incident.NewActivityService(c)
+	result := &incidentAttachmentsResult{Attachments: []incidentAttachment{}}
+	cursor := incident.Cursor{}
+	for page := 0; ; page++ {
+		if page == maxIncidentActivityPages {
+			result.Truncated = true
+			break
+		}
+		resp, err := as.QueryActivity(ctx, incident.QueryActivityRequest{
+			Query: incident.ActivityQuery{
+				IncidentID:     args.IncidentID,
+				Limit:          incidentActivityPageSize,
+				OrderDirection: "ASC",
+			},
+			Cursor: cursor,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list attachments of incident %s: %w", args.IncidentID, err)
+		}
+		for _, item := range resp.ActivityItems {
+			result.Attachments = append(result.Attachments, incidentAttachments(item)...)
+		}
+		if !resp.Cursor.HasMore {
+			break
+		}
+		cursor = resp.Cursor
+	}
+	return result, nil
+}
+
+var ListIncidentAttachments = mcpgrafana.MustTool(
+	"list_incident_attachments",
+	"List the attachments of an incident, oldest first: the links attached to it, such as the one given when it was created, and the files uploaded to its timeline. Returns the caption, URL and type of each",
+	listIncidentAttachments,
+)
+
+// incidentStatuses are the statuses an incident can have.
+var incidentStatuses = []string{"active", "resolved"}
+
+// callIncidentAPI calls an RPC method of the Grafana Incident API which isn't
+// covered by incident-go, following the same conventions as the generated
+// client: a JSON POST to the method name, with errors reported in an "error"
+// field of the response.
+func callIncidentAPI(ctx context.Context, c *incident.Client, method string, request, response any) error {
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshalling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.RemoteHost+method, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", incident.UserAgent)
+	if c.BeforeRequest != nil {
+		if err := c.BeforeRequest(req); err != nil {
+			return err
+		}
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024*48))
+	if err != nil {
+		return fmt.Errorf("%s: reading response body: %w", method, err)
+	}
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &apiErr); err != nil {
+		return fmt.Errorf("%s: (%d) %s", method, resp.StatusCode, string(respBody))
+	}
+	if apiErr.Error != "" {
+		return fmt.Errorf("%s: %s", method, apiErr.Error)
+	}
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return fmt.Errorf("%s: unmarshalling response: %w", method, err)
+	}
+	return nil
+}
+
+type ListIncidentSeveritiesParams struct{}
+
+type incidentSeverity struct {
+	SeverityID   string `json:"severityID"`
+	DisplayLabel string `json:"displayLabel"`
+	Level        int    `json:"level"`
+	Description  string `json:"description,omitempty"`
+}
+
+type incidentSeveritiesResult struct {
+	Severities []incidentSeverity `json:"severities"`
+	Statuses   []string           `json:"statuses"`
+}
+
+func listIncidentSeverities(ctx context.Context, args ListIncidentSeveritiesParams) (*incidentSeveritiesResult, error) {
+	c := mcpgrafana.IncidentClientFromContext(ctx)
+	var resp struct {
+		Severities []incidentSeverity `json:"severities"`
+	}
+	if err := callIncidentAPI(ctx, c, "SeveritiesService.GetOrgSeverities", struct{}{}, &resp); err != nil {
+		return nil, fmt.Errorf("list incident severities: %w", err)
+	}
+	return &incidentSeveritiesResult{
+		Severities: resp.Severities,
+		Statuses:   incidentStatuses,
+	}, nil
+}
+
+var ListIncidentSeverities = mcpgrafana.MustTool(
+	"list_incident_severities",
+	"List the incident severities configured for the organization, along with the valid incident statuses. Use the display label of a severity when creating an incident",
+	listIncidentSeverities,
 )
 
-func AddIncidentTools(mcp *server.MCPServer) {
-	ListIncidents.Register(mcp)
-	CreateIncident.Register(mcp)
-	AddActivityToIncident.Register(mcp)
+func AddIncidentTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "incident",
+		ListIncidents,
+		GetIncident,
+		CreateIncident,
+		AddActivityToIncident,
+		ResolveIncident,
+		AssignIncidentRole,
+		ListIncidentSeverities,
+		ListIncidentAttachments,
+	)
 }
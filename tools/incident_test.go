@@ -5,6 +5,9 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/grafana/incident-go"
@@ -28,6 +31,96 @@ func TestIncidentTools(t *testing.T) {
 		assert.Len(t, result.IncidentPreviews, 2)
 	})
 
+	t.Run("list incidents - page", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		result, err := listIncidents(ctx, ListIncidentsParams{
+			Limit: 2,
+			Page:  2,
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, result.IncidentPreviews)
+	})
+
+	t.Run("list incidents - negative page", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := listIncidents(ctx, ListIncidentsParams{
+			Page: -1,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("build incident query", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			args     ListIncidentsParams
+			expected string
+		}{
+			{name: "default", args: ListIncidentsParams{}, expected: "isdrill:false"},
+			{name: "drills", args: ListIncidentsParams{Drill: true}, expected: ""},
+			{name: "status", args: ListIncidentsParams{Status: "active"}, expected: "isdrill:false and status:active"},
+			{name: "drills and status", args: ListIncidentsParams{Drill: true, Status: "active"}, expected: "status:active"},
+			{
+				name:     "created time range",
+				args:     ListIncidentsParams{CreatedAfter: "2024-01-01T00:00:00Z", CreatedBefore: "2024-02-01T02:00:00+02:00"},
+				expected: "isdrill:false and started>2024-01-01T00:00:00Z and started<2024-02-01T00:00:00Z",
+			},
+			{
+				name:     "query",
+				args:     ListIncidentsParams{Status: "resolved", Query: "  label:service:api and severity:critical "},
+				expected: "isdrill:false and status:resolved and label:service:api and severity:critical",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				query, err := buildIncidentQuery(tc.args)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, query)
+			})
+		}
+
+		_, err := buildIncidentQuery(ListIncidentsParams{Query: "status:active\nlabel:x"})
+		require.Error(t, err)
+
+		_, err = buildIncidentQuery(ListIncidentsParams{CreatedAfter: "yesterday"})
+		require.Error(t, err)
+	})
+
+	t.Run("list incidents - invalid order direction", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := listIncidents(ctx, ListIncidentsParams{
+			OrderDirection: "up",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("list incidents - ascending", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		result, err := listIncidents(ctx, ListIncidentsParams{
+			Limit:          2,
+			OrderDirection: "ASC",
+			OrderField:     "createdTime",
+			CreatedAfter:   "2024-01-01T00:00:00Z",
+		})
+		require.NoError(t, err)
+		assert.Len(t, result.IncidentPreviews, 2)
+	})
+
+	t.Run("get incident", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		result, err := getIncident(ctx, GetIncidentParams{
+			IncidentID: "incident-123",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "incident-123", result.IncidentID)
+		assert.NotEmpty(t, result.Severity)
+		assert.NotEmpty(t, result.IncidentMembership.Assignments)
+	})
+
+	t.Run("get incident - missing id", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := getIncident(ctx, GetIncidentParams{})
+		require.Error(t, err)
+	})
+
 	t.Run("create incident", func(t *testing.T) {
 		ctx := newIncidentTestContext()
 		result, err := createIncident(ctx, CreateIncidentParams{
@@ -57,4 +150,132 @@ func TestIncidentTools(t *testing.T) {
 		assert.Equal(t, "The incident was created by user-123", result.Body)
 		assert.Equal(t, "2021-08-07T11:58:23Z", result.EventTime)
 	})
+
+	t.Run("add activity to incident - invalid kind", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := addActivityToIncident(ctx, AddActivityToIncidentParams{
+			IncidentID:   "123",
+			Body:         "Status changed",
+			ActivityKind: "incidentStatusChanged",
+		})
+		require.ErrorContains(t, err, "invalid activityKind")
+	})
+
+	t.Run("resolve incident", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		result, err := resolveIncident(ctx, ResolveIncidentParams{
+			IncidentID: "incident-123",
+			Summary:    "Rolled back the faulty deployment",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "incident-123", result.IncidentID)
+	})
+
+	t.Run("resolve incident - missing id", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := resolveIncident(ctx, ResolveIncidentParams{})
+		require.Error(t, err)
+	})
+
+	t.Run("assign incident role", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		result, err := assignIncidentRole(ctx, AssignIncidentRoleParams{
+			IncidentID: "incident-123",
+			Role:       "Commander",
+			UserID:     "user-123",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "incident-123", result.IncidentID)
+		require.NotEmpty(t, result.Assignees)
+		assert.Equal(t, "user-123", result.Assignees[0].UserID)
+	})
+
+	t.Run("assign incident role - missing user", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := assignIncidentRole(ctx, AssignIncidentRoleParams{
+			IncidentID: "incident-123",
+			Role:       "commander",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("list incident severities", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/SeveritiesService.GetOrgSeverities", r.URL.Path)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"severities":[{"severityID":"1","displayLabel":"Critical","level":1},{"severityID":"2","displayLabel":"Minor","level":3}]}`))
+		}))
+		defer server.Close()
+
+		ctx := mcpgrafana.WithIncidentClient(context.Background(), incident.NewClient(server.URL+"/", "token"))
+		result, err := listIncidentSeverities(ctx, ListIncidentSeveritiesParams{})
+		require.NoError(t, err)
+		assert.Equal(t, []incidentSeverity{
+			{SeverityID: "1", DisplayLabel: "Critical", Level: 1},
+			{SeverityID: "2", DisplayLabel: "Minor", Level: 3},
+		}, result.Severities)
+		assert.Equal(t, []string{"active", "resolved"}, result.Statuses)
+	})
+
+	t.Run("list incident severities - api error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"permission denied"}`))
+		}))
+		defer server.Close()
+
+		ctx := mcpgrafana.WithIncidentClient(context.Background(), incident.NewClient(server.URL+"/", "token"))
+		_, err := listIncidentSeverities(ctx, ListIncidentSeveritiesParams{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+	})
+
+	t.Run("list incident attachments", func(t *testing.T) {
+		var cursors []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/ActivityService.QueryActivity", r.URL.Path)
+			var req incident.QueryActivityRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "incident-123", req.Query.IncidentID)
+			cursors = append(cursors, req.Cursor.NextValue)
+			if req.Cursor.NextValue == "" {
+				_, _ = w.Write([]byte(`{"activityItems":[
+					{"activityItemID":"a1","activityKind":"contextAttached","body":"Dashboard","url":"https://grafana.example.com/d/abc","createdTime":"2024-01-01T00:00:00Z","user":{"name":"Morty"}},
+					{"activityItemID":"a2","activityKind":"userNote","body":"Just a note"}
+				],"cursor":{"hasMore":true,"nextValue":"page2"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"activityItems":[
+				{"activityItemID":"a3","activityKind":"userNote","body":"Screenshot of the error","user":{"name":"Rick"},"attachments":[
+					{"path":"error.png","fileType":"image","contentType":"image/png","downloadURL":"https://files.example.com/error.png","uploadTime":"2024-01-01T00:05:00Z"},
+					{"path":"old.png","fileType":"image","downloadURL":"https://files.example.com/old.png","deletedTime":"2024-01-01T00:06:00Z"}
+				]}
+			],"cursor":{"hasMore":false}}`))
+		}))
+		defer server.Close()
+
+		ctx := mcpgrafana.WithIncidentClient(context.Background(), incident.NewClient(server.URL+"/", "token"))
+		result, err := listIncidentAttachments(ctx, ListIncidentAttachmentsParams{IncidentID: "incident-123"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "page2"}, cursors)
+		assert.False(t, result.Truncated)
+		assert.Equal(t, []incidentAttachment{
+			{ActivityItemID: "a1", Caption: "Dashboard", URL: "https://grafana.example.com/d/abc", Type: "link", AttachedBy: "Morty", AttachedTime: "2024-01-01T00:00:00Z"},
+			{ActivityItemID: "a3", Caption: "Screenshot of the error", URL: "https://files.example.com/error.png", Type: "image", FileName: "error.png", ContentType: "image/png", AttachedBy: "Rick", AttachedTime: "2024-01-01T00:05:00Z"},
+		}, result.Attachments)
+	})
+
+	t.Run("list incident attachments - truncated", func(t *testing.T) {
+		// The test client always reports more activity.
+		ctx := newIncidentTestContext()
+		result, err := listIncidentAttachments(ctx, ListIncidentAttachmentsParams{IncidentID: "incident-123"})
+		require.NoError(t, err)
+		assert.True(t, result.Truncated)
+	})
+
+	t.Run("list incident attachments - missing id", func(t *testing.T) {
+		ctx := newIncidentTestContext()
+		_, err := listIncidentAttachments(ctx, ListIncidentAttachmentsParams{})
+		require.Error(t, err)
+	})
 }
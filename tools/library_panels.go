@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-openapi-client-go/client/library_elements"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// libraryPanelKind is the kind of library elements which are panels, rather
+// than variables.
+const libraryPanelKind = 1
+
+type ListLibraryPanelsParams struct {
+	Query string `json:"query,omitempty" jsonschema:"description=Optionally\\, only return library panels whose name or description contains this string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of library panels to return. Defaults to 100"`
+	Page  int    `json:"page,omitempty" jsonschema:"description=Optionally\\, the page number to return (1-based). The limit is used as the page size"`
+}
+
+func (p ListLibraryPanelsParams) validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	if p.Page < 0 {
+		return fmt.Errorf("invalid page: %d, must be greater than 0", p.Page)
+	}
+	return nil
+}
+
+type libraryPanelSummary struct {
+	UID                 string `json:"uid"`
+	Name                string `json:"name"`
+	Type                string `json:"type"`
+	Description         string `json:"description,omitempty"`
+	FolderUID           string `json:"folderUid,omitempty"`
+	FolderName          string `json:"folderName,omitempty"`
+	ConnectedDashboards int64  `json:"connectedDashboards"`
+}
+
+type listLibraryPanelsResult struct {
+	LibraryPanels []libraryPanelSummary `json:"libraryPanels"`
+	// TotalCount is the number of library panels matching the query across
+	// all pages.
+	TotalCount int64 `json:"totalCount"`
+	Page       int64 `json:"page"`
+}
+
+func summarizeLibraryPanel(element *models.LibraryElementDTO) libraryPanelSummary {
+	summary := libraryPanelSummary{
+		UID:         element.UID,
+		Name:        element.Name,
+		Type:        element.Type,
+		Description: element.Description,
+		FolderUID:   element.FolderUID,
+	}
+	if element.Meta != nil {
+		summary.FolderName = element.Meta.FolderName
+		summary.ConnectedDashboards = element.Meta.ConnectedDashboards
+	}
+	return summary
+}
+
+func listLibraryPanels(ctx context.Context, args ListLibraryPanelsParams) (*listLibraryPanelsResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("list library panels: %w", err))
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list library panels: %w", err)
+	}
+	kind := int64(libraryPanelKind)
+	params := library_elements.NewGetLibraryElementsParamsWithContext(ctx).WithKind(&kind)
+	if args.Query != "" {
+		params.SetSearchString(&args.Query)
+	}
+	if args.Limit > 0 {
+		perPage := int64(args.Limit)
+		params.SetPerPage(&perPage)
+	}
+	if args.Page > 0 {
+		page := int64(args.Page)
+		params.SetPage(&page)
+	}
+	response, err := c.LibraryElements.GetLibraryElements(params)
+	if err != nil {
+		return nil, fmt.Errorf("list library panels: %w", err)
+	}
+
+	result := &listLibraryPanelsResult{LibraryPanels: []libraryPanelSummary{}}
+	if response.Payload == nil || response.Payload.Result == nil {
+		return result, nil
+	}
+	for _, element := range response.Payload.Result.Elements {
+		if element == nil {
+			continue
+		}
+		result.LibraryPanels = append(result.LibraryPanels, summarizeLibraryPanel(element))
+	}
+	result.TotalCount = response.Payload.Result.TotalCount
+	result.Page = response.Payload.Result.Page
+	return result, nil
+}
+
+var ListLibraryPanels = mcpgrafana.MustTool(
+	"list_library_panels",
+	"List library panels, the panels which are shared between dashboards, optionally filtered by name or description. Returns the UID, name, type, folder and number of connected dashboards of each",
+	listLibraryPanels,
+)
+
+type GetLibraryPanelByUIDParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The UID of the library panel"`
+}
+
+type libraryPanel struct {
+	libraryPanelSummary
+	// Model is the panel's JSON, as used in dashboards.
+	Model any `json:"model"`
+}
+
+func getLibraryPanelByUID(ctx context.Context, args GetLibraryPanelByUIDParams) (*libraryPanel, error) {
+	if args.UID == "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get library panel: uid is required"))
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get library panel: %w", err)
+	}
+	response, err := c.LibraryElements.GetLibraryElementByUID(args.UID)
+	if err != nil {
+		var notFound *library_elements.GetLibraryElementByUIDNotFound
+		if errors.As(err, &notFound) {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("get library panel: library panel with uid %s not found", args.UID))
+		}
+		return nil, fmt.Errorf("get library panel by uid %s: %w", args.UID, err)
+	}
+	if response.Payload == nil || response.Payload.Result == nil {
+		return nil, fmt.Errorf("get library panel by uid %s: empty response", args.UID)
+	}
+	element := response.Payload.Result
+	if element.Kind != libraryPanelKind {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get library panel: library element %s is not a panel", args.UID))
+	}
+	return &libraryPanel{
+		libraryPanelSummary: summarizeLibraryPanel(element),
+		Model:               element.Model,
+	}, nil
+}
+
+var GetLibraryPanelByUID = mcpgrafana.MustTool(
+	"get_library_panel_by_uid",
+	"Get a library panel by UID, including its panel JSON and the number of dashboards it's connected to",
+	getLibraryPanelByUID,
+)
@@ -0,0 +1,70 @@
+// Requires a Grafana instance running on localhost:3000.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryPanelTools(t *testing.T) {
+	ctx := newTestContext()
+	c := mcpgrafana.GrafanaClientFromContext(ctx)
+	name := fmt.Sprintf("mcp-test-library-panel-%d", time.Now().UnixNano())
+	created, err := c.LibraryElements.CreateLibraryElement(&models.CreateLibraryElementCommand{
+		Kind: libraryPanelKind,
+		Name: name,
+		Model: map[string]any{
+			"type":  "stat",
+			"title": name,
+		},
+	})
+	require.NoError(t, err)
+	uid := created.Payload.Result.UID
+	t.Cleanup(func() {
+		_, _ = c.LibraryElements.DeleteLibraryElementByUID(uid)
+	})
+
+	t.Run("list library panels", func(t *testing.T) {
+		result, err := listLibraryPanels(ctx, ListLibraryPanelsParams{Query: name})
+		require.NoError(t, err)
+		require.Len(t, result.LibraryPanels, 1)
+		assert.Equal(t, int64(1), result.TotalCount)
+		assert.Equal(t, uid, result.LibraryPanels[0].UID)
+		assert.Equal(t, name, result.LibraryPanels[0].Name)
+		assert.Equal(t, "stat", result.LibraryPanels[0].Type)
+		assert.Equal(t, int64(0), result.LibraryPanels[0].ConnectedDashboards)
+	})
+
+	t.Run("list library panels with pagination", func(t *testing.T) {
+		result, err := listLibraryPanels(ctx, ListLibraryPanelsParams{Query: name, Limit: 1, Page: 2})
+		require.NoError(t, err)
+		assert.Empty(t, result.LibraryPanels)
+		assert.Equal(t, int64(1), result.TotalCount)
+	})
+
+	t.Run("get library panel by uid", func(t *testing.T) {
+		result, err := getLibraryPanelByUID(ctx, GetLibraryPanelByUIDParams{UID: uid})
+		require.NoError(t, err)
+		assert.Equal(t, name, result.Name)
+		assert.Equal(t, int64(0), result.ConnectedDashboards)
+		model, ok := result.Model.(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "stat", model["type"])
+	})
+
+	t.Run("get missing library panel", func(t *testing.T) {
+		_, err := getLibraryPanelByUID(ctx, GetLibraryPanelByUIDParams{UID: "no-such-library-panel"})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
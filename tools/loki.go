@@ -8,12 +8,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	mcpgrafana "github.com/grafana/mcp-grafana"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/prometheus/model/labels"
 )
 
 const (
@@ -22,6 +25,19 @@ const (
 
 	// MaxLokiLogLimit is the maximum number of log lines that can be requested
 	MaxLokiLogLimit = 100
+
+	// MaxLokiSplitQueries is the maximum number of queries a split query can
+	// be split into
+	MaxLokiSplitQueries = 100
+
+	// maxLokiErrorBodyBytes is the most of a response body which is
+	// included in an error, so that an error page doesn't flood the context.
+	maxLokiErrorBodyBytes = 512
+
+	// DefaultLokiSummaryLimit is the default and maximum number of log lines
+	// counted by a logs summary. It matches Loki's default
+	// max_entries_limit_per_query.
+	DefaultLokiSummaryLimit = 5000
 )
 
 type Client struct {
@@ -44,14 +60,15 @@ type Stats struct {
 }
 
 func newLokiClient(ctx context.Context, uid string) (*Client, error) {
-	grafanaURL, apiKey := mcpgrafana.GrafanaURLFromContext(ctx), mcpgrafana.GrafanaAPIKeyFromContext(ctx)
-	url := fmt.Sprintf("%s/api/datasources/proxy/uid/%s", strings.TrimRight(grafanaURL, "/"), uid)
+	if err := checkDatasourceType(ctx, uid, "Loki", "loki"); err != nil {
+		return nil, err
+	}
+	grafanaURL := mcpgrafana.GrafanaURLFromContext(ctx)
+	url := datasourceProxyURL(grafanaURL, uid)
 
 	client := &http.Client{
-		Transport: &authRoundTripper{
-			apiKey:     apiKey,
-			underlying: http.DefaultTransport,
-		},
+		Transport: newDatasourceTransport(ctx),
+		Timeout:   clientTimeout(ctx),
 	}
 
 	return &Client{
@@ -98,8 +115,8 @@ func (c *Client) makeRequest(ctx context.Context, method, urlPath string, params
 
 	// Check for non-200 status code
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Loki API returned status code %d: %s", resp.StatusCode, string(bodyBytes))
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+		return nil, proxyStatusError(resp.StatusCode, fmt.Errorf("Loki API returned status code %d: %s", resp.StatusCode, truncateBody(bodyBytes)))
 	}
 
 	// Read the response body with a limit to prevent memory issues
@@ -109,13 +126,35 @@ func (c *Client) makeRequest(ctx context.Context, method, urlPath string, params
 		return nil, fmt.Errorf("reading response body: %w", err)
 	}
 
-	// Check if the response is empty
+	// Trim any whitespace that might cause JSON parsing issues
+	bodyBytes = bytes.TrimSpace(bodyBytes)
+
+	// Check if the response is empty, or was only whitespace
 	if len(bodyBytes) == 0 {
 		return nil, fmt.Errorf("empty response from Loki API")
 	}
 
-	// Trim any whitespace that might cause JSON parsing issues
-	return bytes.TrimSpace(bodyBytes), nil
+	// Proxies in front of Loki may respond with an HTML error page, even
+	// with a 200 status code. All of Loki's responses are JSON objects.
+	if bodyBytes[0] != '{' {
+		return nil, fmt.Errorf("Loki returned non-JSON response (status %d, content type %q): %s", resp.StatusCode, resp.Header.Get("Content-Type"), truncateBody(bodyBytes))
+	}
+	return bodyBytes, nil
+}
+
+// truncateBody returns at most maxLokiErrorBodyBytes of a response body, to
+// be included in an error.
+func truncateBody(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) <= maxLokiErrorBodyBytes {
+		return string(body)
+	}
+	// Don't cut a multi-byte character in half.
+	cut := maxLokiErrorBodyBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", body[:cut], len(body)-cut)
 }
 
 // fetchData is a generic method to fetch data from Loki API
@@ -136,11 +175,11 @@ func (c *Client) fetchData(ctx context.Context, urlPath string, startRFC3339, en
 	var labelResponse LabelResponse
 	err = json.Unmarshal(bodyBytes, &labelResponse)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling response (content: %s): %w", string(bodyBytes), err)
+		return nil, fmt.Errorf("unmarshalling Loki response (content: %s): %w", truncateBody(bodyBytes), err)
 	}
 
 	if labelResponse.Status != "success" {
-		return nil, fmt.Errorf("Loki API returned unexpected response format: %s", string(bodyBytes))
+		return nil, fmt.Errorf("Loki API returned unexpected response format: %s", truncateBody(bodyBytes))
 	}
 
 	// Check if Data is nil or empty and handle it explicitly
@@ -156,29 +195,11 @@ func (c *Client) fetchData(ctx context.Context, urlPath string, startRFC3339, en
 	return labelResponse.Data, nil
 }
 
-type authRoundTripper struct {
-	apiKey     string
-	underlying http.RoundTripper
-}
-
-func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	if rt.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+rt.apiKey)
-	}
-
-	resp, err := rt.underlying.RoundTrip(req)
-	if err != nil {
-		return nil, err
-	}
-
-	return resp, nil
-}
-
 // ListLokiLabelNamesParams defines the parameters for listing Loki label names
 type ListLokiLabelNamesParams struct {
 	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
-	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally, the start time of the query in RFC3339 format (defaults to 1 hour ago)"`
-	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally, the end time of the query in RFC3339 format (defaults to now)"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to 1 hour before the end time unless the server is configured otherwise)"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to now)"`
 }
 
 // listLokiLabelNames lists all label names in a Loki datasource
@@ -188,7 +209,8 @@ func listLokiLabelNames(ctx context.Context, args ListLokiLabelNamesParams) ([]s
 		return nil, fmt.Errorf("creating Loki client: %w", err)
 	}
 
-	result, err := client.fetchData(ctx, "/loki/api/v1/labels", args.StartRFC3339, args.EndRFC3339)
+	startTime, endTime := getDefaultTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	result, err := client.fetchData(ctx, "/loki/api/v1/labels", startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
@@ -211,8 +233,8 @@ var ListLokiLabelNames = mcpgrafana.MustTool(
 type ListLokiLabelValuesParams struct {
 	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
 	LabelName     string `json:"labelName" jsonschema:"required,description=The name of the label to retrieve values for (e.g. 'app', 'env', 'pod')"`
-	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally, the start time of the query in RFC3339 format (defaults to 1 hour ago)"`
-	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally, the end time of the query in RFC3339 format (defaults to now)"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to 1 hour before the end time unless the server is configured otherwise)"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to now)"`
 }
 
 // listLokiLabelValues lists all values for a specific label in a Loki datasource
@@ -225,7 +247,8 @@ func listLokiLabelValues(ctx context.Context, args ListLokiLabelValuesParams) ([
 	// Use the client's fetchData method
 	urlPath := fmt.Sprintf("/loki/api/v1/label/%s/values", args.LabelName)
 
-	result, err := client.fetchData(ctx, urlPath, args.StartRFC3339, args.EndRFC3339)
+	startTime, endTime := getDefaultTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	result, err := client.fetchData(ctx, urlPath, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
@@ -261,20 +284,20 @@ type QueryRangeResponse struct {
 }
 
 // addTimeRangeParams adds start and end time parameters to the URL values
-// It handles conversion from RFC3339 to Unix nanoseconds
+// It handles conversion from RFC3339 or relative times to Unix nanoseconds
 func addTimeRangeParams(params url.Values, startRFC3339, endRFC3339 string) error {
 	if startRFC3339 != "" {
-		startTime, err := time.Parse(time.RFC3339, startRFC3339)
+		startTime, err := parseTime(startRFC3339, time.Now(), false)
 		if err != nil {
-			return fmt.Errorf("parsing start time: %w", err)
+			return mcpgrafana.NewToolError(fmt.Errorf("parsing start time: %w", err))
 		}
 		params.Add("start", fmt.Sprintf("%d", startTime.UnixNano()))
 	}
 
 	if endRFC3339 != "" {
-		endTime, err := time.Parse(time.RFC3339, endRFC3339)
+		endTime, err := parseTime(endRFC3339, time.Now(), true)
 		if err != nil {
-			return fmt.Errorf("parsing end time: %w", err)
+			return mcpgrafana.NewToolError(fmt.Errorf("parsing end time: %w", err))
 		}
 		params.Add("end", fmt.Sprintf("%d", endTime.UnixNano()))
 	}
@@ -282,20 +305,6 @@ func addTimeRangeParams(params url.Values, startRFC3339, endRFC3339 string) erro
 	return nil
 }
 
-// getDefaultTimeRange returns default start and end times if not provided
-// Returns start time (1 hour ago) and end time (now) in RFC3339 format
-func getDefaultTimeRange(startRFC3339, endRFC3339 string) (string, string) {
-	if startRFC3339 == "" {
-		// Default to 1 hour ago if not specified
-		startRFC3339 = time.Now().Add(-1 * time.Hour).Format(time.RFC3339)
-	}
-	if endRFC3339 == "" {
-		// Default to now if not specified
-		endRFC3339 = time.Now().Format(time.RFC3339)
-	}
-	return startRFC3339, endRFC3339
-}
-
 // fetchLogs is a method to fetch logs from Loki API
 func (c *Client) fetchLogs(ctx context.Context, query, startRFC3339, endRFC3339 string, limit int, direction string) ([]LogStream, error) {
 	params := url.Values{}
@@ -322,24 +331,69 @@ func (c *Client) fetchLogs(ctx context.Context, query, startRFC3339, endRFC3339
 	var queryResponse QueryRangeResponse
 	err = json.Unmarshal(bodyBytes, &queryResponse)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling response (content: %s): %w", string(bodyBytes), err)
+		return nil, fmt.Errorf("unmarshalling Loki response (content: %s): %w", truncateBody(bodyBytes), err)
 	}
 
 	if queryResponse.Status != "success" {
-		return nil, fmt.Errorf("Loki API returned unexpected response format: %s", string(bodyBytes))
+		return nil, fmt.Errorf("Loki API returned unexpected response format: %s", truncateBody(bodyBytes))
 	}
 
 	return queryResponse.Data.Result, nil
 }
 
+// splitTimeRange splits the range from start to end into consecutive
+// intervals no longer than interval, in the order they should be queried in
+// the given direction: newest first for backward queries.
+func splitTimeRange(start, end time.Time, interval time.Duration, direction string) [][2]time.Time {
+	var ranges [][2]time.Time
+	for s := start; s.Before(end); s = s.Add(interval) {
+		ranges = append(ranges, [2]time.Time{s, minTime(s.Add(interval), end)})
+	}
+	if direction == "backward" {
+		slices.Reverse(ranges)
+	}
+	return ranges
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// fetchLogsSplit fetches logs like fetchLogs, but splits the time range into
+// smaller intervals which are queried one at a time, so that queries over long
+// time ranges don't time out. It stops once limit entries have been fetched.
+func (c *Client) fetchLogsSplit(ctx context.Context, query string, start, end time.Time, interval time.Duration, limit int, direction string) ([]LogStream, error) {
+	var streams []LogStream
+	remaining := limit
+	for _, r := range splitTimeRange(start, end, interval, direction) {
+		result, err := c.fetchLogs(ctx, query, r[0].Format(time.RFC3339Nano), r[1].Format(time.RFC3339Nano), remaining, direction)
+		if err != nil {
+			return nil, err
+		}
+		for _, stream := range result {
+			remaining -= len(stream.Values)
+		}
+		streams = append(streams, result...)
+		if remaining <= 0 {
+			break
+		}
+	}
+	return streams, nil
+}
+
 // QueryLokiLogsParams defines the parameters for querying Loki logs
 type QueryLokiLogsParams struct {
-	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
-	LogQL         string `json:"logql" jsonschema:"required,description=The LogQL query to execute against Loki. This can be a simple label matcher or a complex query with filters, parsers, and expressions. Supports full LogQL syntax including label matchers, filter operators, pattern expressions, and pipeline operations."`
-	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally, the start time of the query in RFC3339 format"`
-	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally, the end time of the query in RFC3339 format"`
-	Limit         int    `json:"limit,omitempty" jsonschema:"description=Optionally, the maximum number of log lines to return (default: 10, max: 100)"`
-	Direction     string `json:"direction,omitempty" jsonschema:"description=Optionally, the direction of the query: 'forward' (oldest first) or 'backward' (newest first, default)"`
+	DatasourceUID        string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
+	LogQL                string `json:"logql" jsonschema:"required,description=The LogQL query to execute against Loki. This can be a simple label matcher or a complex query with filters, parsers, and expressions. Supports full LogQL syntax including label matchers, filter operators, pattern expressions, and pipeline operations."`
+	StartRFC3339         string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to 1 hour before the end time unless the server is configured otherwise)"`
+	EndRFC3339           string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to now)"`
+	SinceSeconds         int    `json:"sinceSeconds,omitempty" jsonschema:"description=Optionally\\, query the last this many seconds\\, e.g. 900 for the last 15 minutes\\, instead of giving startRfc3339 and endRfc3339. Ignored if either of those is given"`
+	Limit                int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of log lines to return (default: 10\\, max: 100)"`
+	Direction            string `json:"direction,omitempty" jsonschema:"description=Optionally\\, the direction of the query: 'forward' (oldest first) or 'backward' (newest first\\, default),enum=forward,enum=backward"`
+	SplitIntervalSeconds int    `json:"splitIntervalSeconds,omitempty" jsonschema:"description=Optionally\\, split the time range into intervals of this many seconds which are queried one at a time in the query's direction\\, stopping once the limit is reached. Use this for long time ranges which time out as a single query"`
 }
 
 // LogEntry represents a single log entry or metric sample with metadata
@@ -368,8 +422,12 @@ func queryLokiLogs(ctx context.Context, args QueryLokiLogsParams) ([]LogEntry, e
 		return nil, fmt.Errorf("creating Loki client: %w", err)
 	}
 
+	startTime, endTime, err := sinceTimeRange(args.SinceSeconds, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
 	// Get default time range if not provided
-	startTime, endTime := getDefaultTimeRange(args.StartRFC3339, args.EndRFC3339)
+	startTime, endTime = getDefaultTimeRange(ctx, startTime, endTime)
 
 	// Apply limit constraints
 	limit := enforceLogLimit(args.Limit)
@@ -380,7 +438,12 @@ func queryLokiLogs(ctx context.Context, args QueryLokiLogsParams) ([]LogEntry, e
 		direction = "backward" // Most recent logs first
 	}
 
-	streams, err := client.fetchLogs(ctx, args.LogQL, startTime, endTime, limit, direction)
+	var streams []LogStream
+	if args.SplitIntervalSeconds == 0 {
+		streams, err = client.fetchLogs(ctx, args.LogQL, startTime, endTime, limit, direction)
+	} else {
+		streams, err = querySplitLokiLogs(ctx, client, args, startTime, endTime, limit, direction)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -445,6 +508,23 @@ func queryLokiLogs(ctx context.Context, args QueryLokiLogsParams) ([]LogEntry, e
 	return entries, nil
 }
 
+// querySplitLokiLogs validates the split interval and time range of a split
+// query before fetching its logs.
+func querySplitLokiLogs(ctx context.Context, client *Client, args QueryLokiLogsParams, startRFC3339, endRFC3339 string, limit int, direction string) ([]LogStream, error) {
+	if args.SplitIntervalSeconds < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid splitIntervalSeconds: %d, must be greater than 0", args.SplitIntervalSeconds))
+	}
+	start, end, err := parseTimeRange(ctx, startRFC3339, endRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(args.SplitIntervalSeconds) * time.Second
+	if n := (end.Sub(start) + interval - 1) / interval; n > MaxLokiSplitQueries {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("splitIntervalSeconds %d would split the query into more than %d queries; use a larger interval", args.SplitIntervalSeconds, MaxLokiSplitQueries))
+	}
+	return client.fetchLogsSplit(ctx, args.LogQL, start, end, interval, limit, direction)
+}
+
 // QueryLokiLogs is a tool for querying logs from Loki
 var QueryLokiLogs = mcpgrafana.MustTool(
 	"query_loki_logs",
@@ -470,7 +550,7 @@ func (c *Client) fetchStats(ctx context.Context, query, startRFC3339, endRFC3339
 	var stats Stats
 	err = json.Unmarshal(bodyBytes, &stats)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling response (content: %s): %w", string(bodyBytes), err)
+		return nil, fmt.Errorf("unmarshalling Loki response (content: %s): %w", truncateBody(bodyBytes), err)
 	}
 
 	return &stats, nil
@@ -480,8 +560,9 @@ func (c *Client) fetchStats(ctx context.Context, query, startRFC3339, endRFC3339
 type QueryLokiStatsParams struct {
 	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
 	LogQL         string `json:"logql" jsonschema:"required,description=The LogQL matcher expression to execute. This parameter only accepts label matcher expressions and does not support full LogQL queries. Line filters, pattern operations, and metric aggregations are not supported by the stats API endpoint. Only simple label selectors can be used here."`
-	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally, the start time of the query in RFC3339 format"`
-	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally, the end time of the query in RFC3339 format"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to 1 hour before the end time unless the server is configured otherwise)"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to now)"`
+	SinceSeconds  int    `json:"sinceSeconds,omitempty" jsonschema:"description=Optionally\\, query the last this many seconds\\, e.g. 900 for the last 15 minutes\\, instead of giving startRfc3339 and endRfc3339. Ignored if either of those is given"`
 }
 
 // queryLokiStats queries stats from a Loki datasource using LogQL
@@ -491,8 +572,12 @@ func queryLokiStats(ctx context.Context, args QueryLokiStatsParams) (*Stats, err
 		return nil, fmt.Errorf("creating Loki client: %w", err)
 	}
 
+	startTime, endTime, err := sinceTimeRange(args.SinceSeconds, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
 	// Get default time range if not provided
-	startTime, endTime := getDefaultTimeRange(args.StartRFC3339, args.EndRFC3339)
+	startTime, endTime = getDefaultTimeRange(ctx, startTime, endTime)
 
 	stats, err := client.fetchStats(ctx, args.LogQL, startTime, endTime)
 	if err != nil {
@@ -509,10 +594,86 @@ var QueryLokiStats = mcpgrafana.MustTool(
 	queryLokiStats,
 )
 
+// QueryLokiLogsSummaryParams defines the parameters for summarizing the
+// results of a Loki log query
+type QueryLokiLogsSummaryParams struct {
+	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
+	LogQL         string `json:"logql" jsonschema:"required,description=The LogQL log query to summarize the results of"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to 1 hour before the end time unless the server is configured otherwise)"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h' (defaults to now)"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of log lines to count (default and max: 5000)"`
+}
+
+// LokiLogsSummary summarizes the log lines returned by a query
+type LokiLogsSummary struct {
+	TotalLines int `json:"totalLines"`
+	TotalBytes int `json:"totalBytes"`
+	Streams    int `json:"streams"`
+	// UniqueLabelSets is the number of distinct label sets among the
+	// streams, including labels extracted by parsers in the query.
+	UniqueLabelSets int `json:"uniqueLabelSets"`
+	// LimitReached is true if the query matched more lines than were
+	// counted, so the totals are lower bounds.
+	LimitReached bool `json:"limitReached"`
+}
+
+// summarizeLogStreams counts the lines, bytes and label sets in streams.
+func summarizeLogStreams(streams []LogStream, limit int) *LokiLogsSummary {
+	summary := &LokiLogsSummary{Streams: len(streams)}
+	labelSets := map[string]bool{}
+	for _, stream := range streams {
+		labelSets[labels.FromMap(stream.Stream).String()] = true
+		for _, value := range stream.Values {
+			var line string
+			if len(value) < 2 || json.Unmarshal(value[1], &line) != nil {
+				continue
+			}
+			summary.TotalLines++
+			summary.TotalBytes += len(line)
+		}
+	}
+	summary.UniqueLabelSets = len(labelSets)
+	summary.LimitReached = summary.TotalLines >= limit
+	return summary
+}
+
+// queryLokiLogsSummary summarizes the results of a Loki log query without
+// returning the lines
+func queryLokiLogsSummary(ctx context.Context, args QueryLokiLogsSummaryParams) (*LokiLogsSummary, error) {
+	if args.Limit < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid limit: %d, must be greater than 0", args.Limit))
+	}
+	client, err := newLokiClient(ctx, args.DatasourceUID)
+	if err != nil {
+		return nil, fmt.Errorf("creating Loki client: %w", err)
+	}
+	startTime, endTime := getDefaultTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	limit := DefaultLokiSummaryLimit
+	if args.Limit > 0 {
+		limit = min(args.Limit, DefaultLokiSummaryLimit)
+	}
+	streams, err := client.fetchLogs(ctx, args.LogQL, startTime, endTime, limit, "backward")
+	if err != nil {
+		return nil, err
+	}
+	return summarizeLogStreams(streams, limit), nil
+}
+
+// QueryLokiLogsSummary is a tool for summarizing the results of a Loki log
+// query
+var QueryLokiLogsSummary = mcpgrafana.MustTool(
+	"query_loki_logs_summary",
+	"Summarize the results of a LogQL log query without returning the lines: the number of lines and bytes, the number of streams and the number of distinct label sets. Up to 5000 lines are counted; 'limitReached' is true if there were more. Use this before `query_loki_logs` to decide whether to fetch the logs or narrow the query first",
+	queryLokiLogsSummary,
+)
+
 // AddLokiTools registers all Loki tools with the MCP server
-func AddLokiTools(mcp *server.MCPServer) {
-	ListLokiLabelNames.Register(mcp)
-	ListLokiLabelValues.Register(mcp)
-	QueryLokiStats.Register(mcp)
-	QueryLokiLogs.Register(mcp)
+func AddLokiTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "loki",
+		ListLokiLabelNames,
+		ListLokiLabelValues,
+		QueryLokiStats,
+		QueryLokiLogs,
+		QueryLokiLogsSummary,
+	)
 }
@@ -0,0 +1,195 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiClientErrors(t *testing.T) {
+	for _, tc := range []struct {
+		status    int
+		toolError bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusNotFound, true},
+		{http.StatusUnauthorized, false},
+		{http.StatusInternalServerError, false},
+	} {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "error", tc.status)
+			})
+			client, err := newLokiClient(ctx, "loki")
+			require.NoError(t, err)
+			_, err = client.makeRequest(ctx, http.MethodGet, "/loki/api/v1/labels", nil)
+			require.Error(t, err)
+			var toolErr *mcpgrafana.ToolError
+			assert.Equal(t, tc.toolError, errors.As(err, &toolErr))
+		})
+	}
+
+	t.Run("invalid time", func(t *testing.T) {
+		err := addTimeRangeParams(nil, "yesterday", "")
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestQueryLokiLogsSplit(t *testing.T) {
+	end := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	start := end.Add(-3 * time.Hour)
+
+	var ranges [][2]string
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/datasources/proxy/uid/loki/loki/api/v1/query_range", r.URL.Path)
+		q := r.URL.Query()
+		ranges = append(ranges, [2]string{q.Get("start"), q.Get("end")})
+		// Return one line per sub-query, at its start time.
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"test"},"values":[["%s","line %d"]]}]}}`, q.Get("start"), len(ranges))
+	})
+	nanos := func(t time.Time) string { return strconv.FormatInt(t.UnixNano(), 10) }
+
+	for _, tc := range []struct {
+		direction string
+		ranges    [][2]string
+	}{
+		{"backward", [][2]string{
+			{nanos(end.Add(-time.Hour)), nanos(end)},
+			{nanos(end.Add(-2 * time.Hour)), nanos(end.Add(-time.Hour))},
+		}},
+		{"forward", [][2]string{
+			{nanos(start), nanos(start.Add(time.Hour))},
+			{nanos(start.Add(time.Hour)), nanos(start.Add(2 * time.Hour))},
+		}},
+	} {
+		t.Run(tc.direction, func(t *testing.T) {
+			ranges = nil
+			entries, err := queryLokiLogs(ctx, QueryLokiLogsParams{
+				DatasourceUID:        "loki",
+				LogQL:                `{app="test"}`,
+				StartRFC3339:         start.Format(time.RFC3339),
+				EndRFC3339:           end.Format(time.RFC3339),
+				Limit:                2,
+				Direction:            tc.direction,
+				SplitIntervalSeconds: 3600,
+			})
+			require.NoError(t, err)
+			// The last interval isn't queried, since the limit was reached.
+			assert.Equal(t, tc.ranges, ranges)
+			require.Len(t, entries, 2)
+			assert.Equal(t, "line 1", entries[0].Line)
+			assert.Equal(t, "line 2", entries[1].Line)
+		})
+	}
+
+	t.Run("too many intervals", func(t *testing.T) {
+		_, err := queryLokiLogs(ctx, QueryLokiLogsParams{
+			DatasourceUID:        "loki",
+			LogQL:                `{app="test"}`,
+			StartRFC3339:         start.Format(time.RFC3339),
+			EndRFC3339:           end.Format(time.RFC3339),
+			SplitIntervalSeconds: 60,
+		})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestQueryLokiLogsSummary(t *testing.T) {
+	var limit string
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/datasources/proxy/uid/loki/loki/api/v1/query_range", r.URL.Path)
+		limit = r.URL.Query().Get("limit")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[
+			{"stream":{"app":"a","level":"info"},"values":[["1","one"],["2","three"]]},
+			{"stream":{"level":"info","app":"a"},"values":[["3","héllo"]]},
+			{"stream":{"app":"b"},"values":[["4",""]]}
+		]}}`))
+	})
+
+	summary, err := queryLokiLogsSummary(ctx, QueryLokiLogsSummaryParams{DatasourceUID: "loki", LogQL: `{app=~"a|b"}`})
+	require.NoError(t, err)
+	assert.Equal(t, "5000", limit)
+	assert.Equal(t, &LokiLogsSummary{
+		TotalLines:      4,
+		TotalBytes:      14,
+		Streams:         3,
+		UniqueLabelSets: 2,
+	}, summary)
+
+	summary, err = queryLokiLogsSummary(ctx, QueryLokiLogsSummaryParams{DatasourceUID: "loki", LogQL: `{app=~"a|b"}`, Limit: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "4", limit)
+	assert.True(t, summary.LimitReached)
+
+	_, err = queryLokiLogsSummary(ctx, QueryLokiLogsSummaryParams{DatasourceUID: "loki", LogQL: `{app="a"}`, Limit: -1})
+	var toolErr *mcpgrafana.ToolError
+	assert.True(t, errors.As(err, &toolErr))
+}
+
+func TestLokiClientNonJSONResponse(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("Bad Gateway ", 200) + "</body></html>"
+
+	t.Run("error status", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(page))
+		})
+		client, err := newLokiClient(ctx, "loki")
+		require.NoError(t, err)
+		_, err = client.fetchLogs(ctx, `{app="test"}`, "", "", 10, "backward")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Loki API returned status code 502: <html>")
+		assert.Contains(t, err.Error(), "[truncated")
+		assert.Less(t, len(err.Error()), 700)
+	})
+
+	t.Run("ok status", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(page))
+		})
+		client, err := newLokiClient(ctx, "loki")
+		require.NoError(t, err)
+		_, err = client.fetchLogs(ctx, `{app="test"}`, "", "", 10, "backward")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `Loki returned non-JSON response (status 200, content type "text/html"): <html>`)
+		assert.Less(t, len(err.Error()), 700)
+	})
+
+	t.Run("partial body", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[` + strings.Repeat(`{"stream":{},"values":[]},`, 100)))
+		})
+		client, err := newLokiClient(ctx, "loki")
+		require.NoError(t, err)
+		_, err = client.fetchLogs(ctx, `{app="test"}`, "", "", 10, "backward")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unmarshalling Loki response")
+		assert.Less(t, len(err.Error()), 700)
+	})
+
+	t.Run("whitespace body", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("\n \t\n"))
+		})
+		client, err := newLokiClient(ctx, "loki")
+		require.NoError(t, err)
+		_, err = client.fetchLogs(ctx, `{app="test"}`, "", "", 10, "backward")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "empty response from Loki API")
+	})
+}
@@ -3,15 +3,26 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	aapi "github.com/grafana/amixr-api-go-client"
 	mcpgrafana "github.com/grafana/mcp-grafana"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+var (
+	errOnCallPluginNotFound       = errors.New("the Grafana IRM plugin (grafana-irm-app) was not found")
+	errOnCallSettingsUnauthorized = errors.New("not authorized to read the Grafana IRM plugin (grafana-irm-app) settings")
+	errOnCallURLNotSet            = errors.New("OnCall API URL is not set in the Grafana IRM plugin settings")
+)
+
 // getOnCallURLFromSettings retrieves the OnCall API URL from the Grafana settings endpoint.
 // It makes a GET request to <grafana-url>/api/plugins/grafana-irm-app/settings and extracts
 // the OnCall URL from the jsonData.onCallApiUrl field in the response.
@@ -28,13 +39,23 @@ func getOnCallURLFromSettings(ctx context.Context, grafanaURL, grafanaAPIKey str
 		req.Header.Set("Authorization", "Bearer "+grafanaAPIKey)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	client := &http.Client{
+		Transport: newTransport(ctx),
+		Timeout:   clientTimeout(ctx),
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("fetching settings: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%w: make sure the grafana-irm-app plugin is installed and enabled in Grafana", errOnCallPluginNotFound)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("%w (status code %d): make sure the API key or service account token can read plugin settings", errOnCallSettingsUnauthorized, resp.StatusCode)
+	default:
 		return "", fmt.Errorf("unexpected status code from settings API: %d", resp.StatusCode)
 	}
 
@@ -49,27 +70,73 @@ func getOnCallURLFromSettings(ctx context.Context, grafanaURL, grafanaAPIKey str
 	}
 
 	if settings.JSONData.OnCallAPIURL == "" {
-		return "", fmt.Errorf("OnCall API URL is not set in settings")
+		return "", fmt.Errorf("%w: make sure the grafana-irm-app plugin is enabled and OnCall has been set up", errOnCallURLNotSet)
 	}
 
 	return settings.JSONData.OnCallAPIURL, nil
 }
 
+// onCallURLCacheTTL is how long a resolved OnCall API URL is reused before
+// the Grafana settings are fetched again.
+const onCallURLCacheTTL = 5 * time.Minute
+
+type onCallURLCacheEntry struct {
+	url     string
+	expires time.Time
+}
+
+// onCallURLCache caches OnCall API URLs by Grafana URL, so that each OnCall
+// tool call doesn't need an extra round trip to the Grafana settings API.
+var onCallURLCache = struct {
+	sync.Mutex
+	entries map[string]onCallURLCacheEntry
+}{entries: map[string]onCallURLCacheEntry{}}
+
+// getOnCallURL returns the OnCall API URL for the given Grafana instance,
+// using a cached value if there is one unless `refresh` is true.
+func getOnCallURL(ctx context.Context, grafanaURL, grafanaAPIKey string, refresh bool) (string, error) {
+	onCallURLCache.Lock()
+	entry, ok := onCallURLCache.entries[grafanaURL]
+	onCallURLCache.Unlock()
+	if ok && !refresh && time.Now().Before(entry.expires) {
+		return entry.url, nil
+	}
+
+	onCallURL, err := getOnCallURLFromSettings(ctx, grafanaURL, grafanaAPIKey)
+	if err != nil {
+		return "", err
+	}
+
+	onCallURLCache.Lock()
+	onCallURLCache.entries[grafanaURL] = onCallURLCacheEntry{
+		url:     onCallURL,
+		expires: time.Now().Add(onCallURLCacheTTL),
+	}
+	onCallURLCache.Unlock()
+	return onCallURL, nil
+}
+
 func oncallClientFromContext(ctx context.Context) (*aapi.Client, error) {
 	// Get the standard Grafana URL and API key
 	grafanaURL, grafanaAPIKey := mcpgrafana.GrafanaURLFromContext(ctx), mcpgrafana.GrafanaAPIKeyFromContext(ctx)
 
-	// Try to get OnCall URL from settings endpoint
-	grafanaOnCallURL, err := getOnCallURLFromSettings(ctx, grafanaURL, grafanaAPIKey)
+	// Get the OnCall URL from the cache or the settings endpoint
+	grafanaOnCallURL, err := getOnCallURL(ctx, grafanaURL, grafanaAPIKey, false)
 	if err != nil {
 		return nil, fmt.Errorf("getting OnCall URL from settings: %w", err)
 	}
 
-	grafanaOnCallURL = strings.TrimRight(grafanaOnCallURL, "/")
-
-	client, err := aapi.NewWithGrafanaURL(grafanaOnCallURL, grafanaAPIKey, grafanaURL)
+	client, err := aapi.NewWithGrafanaURL(strings.TrimRight(grafanaOnCallURL, "/"), grafanaAPIKey, grafanaURL)
 	if err != nil {
-		return nil, fmt.Errorf("creating OnCall client: %w", err)
+		// The cached URL may be stale, so refetch it and try again.
+		grafanaOnCallURL, err = getOnCallURL(ctx, grafanaURL, grafanaAPIKey, true)
+		if err != nil {
+			return nil, fmt.Errorf("getting OnCall URL from settings: %w", err)
+		}
+		client, err = aapi.NewWithGrafanaURL(strings.TrimRight(grafanaOnCallURL, "/"), grafanaAPIKey, grafanaURL)
+		if err != nil {
+			return nil, fmt.Errorf("creating OnCall client: %w", err)
+		}
 	}
 
 	return client, nil
@@ -179,15 +246,140 @@ var GetOnCallShift = mcpgrafana.MustTool(
 	getOnCallShift,
 )
 
+// Sources of the users in CurrentOnCallUsers.
+const (
+	onCallUsersSourceOnCallNow     = "on_call_now"
+	onCallUsersSourceFinalSchedule = "final_schedule"
+)
+
 // CurrentOnCallUsers represents the currently on-call users for a schedule
 type CurrentOnCallUsers struct {
-	ScheduleID   string   `json:"scheduleId" jsonschema:"description=The ID of the schedule"`
-	ScheduleName string   `json:"scheduleName" jsonschema:"description=The name of the schedule"`
-	Users        []string `json:"users" jsonschema:"description=List of user IDs currently on call"`
+	ScheduleID   string               `json:"scheduleId" jsonschema:"description=The ID of the schedule"`
+	ScheduleName string               `json:"scheduleName" jsonschema:"description=The name of the schedule"`
+	Users        []string             `json:"users" jsonschema:"description=List of user IDs currently on call"`
+	Source       string               `json:"source" jsonschema:"description=How the users were determined: 'on_call_now' if taken from the schedule itself or 'final_schedule' if resolved from the schedule's final shifts"`
+	UserDetails  []*OnCallUserSummary `json:"userDetails,omitempty" jsonschema:"description=Details of the users currently on call\\, if requested"`
+}
+
+// OnCallUserSummary represents the identifying details of an OnCall user
+type OnCallUserSummary struct {
+	ID       string `json:"id" jsonschema:"description=The ID of the user"`
+	Username string `json:"username" jsonschema:"description=The username of the user"`
+	Email    string `json:"email" jsonschema:"description=The email address of the user"`
 }
 
 type GetCurrentOnCallUsersParams struct {
-	ScheduleID string `json:"scheduleId" jsonschema:"required,description=The ID of the schedule to get current on-call users for"`
+	ScheduleID         string `json:"scheduleId" jsonschema:"required,description=The ID of the schedule to get current on-call users for"`
+	IncludeUserDetails bool   `json:"includeUserDetails,omitempty" jsonschema:"description=Whether to also return the username and email of each user. If false\\, only user IDs are returned"`
+}
+
+// getOnCallUserSummaries looks up the details of the users with the given
+// IDs, fetching each distinct user only once.
+func getOnCallUserSummaries(client *aapi.Client, userIDs []string) ([]*OnCallUserSummary, error) {
+	userService := aapi.NewUserService(client)
+	cache := map[string]*OnCallUserSummary{}
+	summaries := make([]*OnCallUserSummary, 0, len(userIDs))
+	for _, id := range userIDs {
+		summary, ok := cache[id]
+		if !ok {
+			user, _, err := userService.GetUser(id, &aapi.GetUserOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("getting OnCall user %s: %w", id, err)
+			}
+			summary = &OnCallUserSummary{
+				ID:       user.ID,
+				Username: user.Username,
+				Email:    user.Email,
+			}
+			cache[id] = summary
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// finalShift is a shift in the final schedule, after rotations and overrides
+// have been applied.
+type finalShift struct {
+	UserPK       string    `json:"user_pk"`
+	UserUsername string    `json:"user_username"`
+	UserEmail    string    `json:"user_email"`
+	ShiftStart   time.Time `json:"shift_start"`
+	ShiftEnd     time.Time `json:"shift_end"`
+}
+
+type paginatedFinalShiftsResponse struct {
+	aapi.PaginatedResponse
+	FinalShifts []finalShift `json:"results"`
+}
+
+type finalShiftsOptions struct {
+	aapi.ListOptions
+	StartDate string `url:"start_date"`
+	EndDate   string `url:"end_date"`
+}
+
+// listFinalShifts returns the shifts of the final schedule of the schedule
+// with the given ID between the given dates, in YYYY-MM-DD format.
+func listFinalShifts(client *aapi.Client, scheduleID, startDate, endDate string) ([]finalShift, error) {
+	opts := &finalShiftsOptions{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	shifts := []finalShift{}
+	for page := 1; ; page++ {
+		opts.Page = page
+		req, err := client.NewRequest("GET", fmt.Sprintf("schedules/%s/final_shifts", scheduleID), opts)
+		if err != nil {
+			return nil, fmt.Errorf("creating final shifts request: %w", err)
+		}
+		var response paginatedFinalShiftsResponse
+		if _, err := client.Do(req, &response); err != nil {
+			return nil, fmt.Errorf("getting final shifts for schedule %s: %w", scheduleID, err)
+		}
+		shifts = append(shifts, response.FinalShifts...)
+		if response.Next == nil {
+			return shifts, nil
+		}
+	}
+}
+
+// getFinalScheduleUsers returns the IDs of the users on call at `now`
+// according to the final schedule of the schedule with the given ID.
+func getFinalScheduleUsers(client *aapi.Client, scheduleID string, now time.Time) ([]string, error) {
+	// The endpoint takes dates rather than times, so fetch the surrounding
+	// days and find the shifts covering `now`.
+	shifts, err := listFinalShifts(client, scheduleID,
+		now.AddDate(0, 0, -1).UTC().Format(time.DateOnly),
+		now.AddDate(0, 0, 1).UTC().Format(time.DateOnly),
+	)
+	if err != nil {
+		return nil, err
+	}
+	users := []string{}
+	seen := map[string]bool{}
+	for _, shift := range shifts {
+		if !shift.ShiftStart.After(now) && shift.ShiftEnd.After(now) && !seen[shift.UserPK] {
+			seen[shift.UserPK] = true
+			users = append(users, shift.UserPK)
+		}
+	}
+	return users, nil
+}
+
+// getScheduleOnCallUsers returns the IDs of the users on call at `now` in
+// the schedule, and where they were found: the schedule's OnCallNow, or its
+// final schedule if that is empty, since it can be for calendar-backed
+// schedules even when someone is on call.
+func getScheduleOnCallUsers(client *aapi.Client, schedule *aapi.Schedule, now time.Time) ([]string, string, error) {
+	if len(schedule.OnCallNow) > 0 {
+		return schedule.OnCallNow, onCallUsersSourceOnCallNow, nil
+	}
+	users, err := getFinalScheduleUsers(client, schedule.ID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	return users, onCallUsersSourceFinalSchedule, nil
 }
 
 func getCurrentOnCallUsers(ctx context.Context, args GetCurrentOnCallUsersParams) (*CurrentOnCallUsers, error) {
@@ -202,19 +394,94 @@ func getCurrentOnCallUsers(ctx context.Context, args GetCurrentOnCallUsersParams
 		return nil, fmt.Errorf("getting schedule %s: %w", args.ScheduleID, err)
 	}
 
-	return &CurrentOnCallUsers{
+	users, source, err := getScheduleOnCallUsers(client, schedule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	result := &CurrentOnCallUsers{
 		ScheduleID:   schedule.ID,
 		ScheduleName: schedule.Name,
-		Users:        schedule.OnCallNow,
-	}, nil
+		Users:        users,
+		Source:       source,
+	}
+	if args.IncludeUserDetails {
+		result.UserDetails, err = getOnCallUserSummaries(client, result.Users)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 var GetCurrentOnCallUsers = mcpgrafana.MustTool(
 	"get_current_oncall_users",
-	"Get users currently on-call for a specific schedule. A schedule is a calendar-based system defining when team members are on-call",
+	"Get users currently on-call for a specific schedule. A schedule is a calendar-based system defining when team members are on-call. Optionally include each user's username and email",
 	getCurrentOnCallUsers,
 )
 
+// maxFinalScheduleDays is the longest window get_oncall_schedule_final can
+// return shifts for, to keep responses to a manageable size.
+const maxFinalScheduleDays = 31
+
+type GetOnCallScheduleFinalParams struct {
+	ScheduleID string `json:"scheduleId" jsonschema:"required,description=The ID of the schedule to get the final shifts for"`
+	StartDate  string `json:"startDate" jsonschema:"required,description=The first day to return shifts for\\, in YYYY-MM-DD format"`
+	EndDate    string `json:"endDate" jsonschema:"required,description=The last day to return shifts for\\, in YYYY-MM-DD format. At most 31 days after the start date"`
+}
+
+// FinalShiftSummary represents a shift in the final schedule of an OnCall schedule
+type FinalShiftSummary struct {
+	UserID     string    `json:"userId" jsonschema:"description=The ID of the user on call"`
+	Username   string    `json:"username" jsonschema:"description=The username of the user on call"`
+	Email      string    `json:"email" jsonschema:"description=The email address of the user on call"`
+	ShiftStart time.Time `json:"shiftStart" jsonschema:"description=When the shift starts"`
+	ShiftEnd   time.Time `json:"shiftEnd" jsonschema:"description=When the shift ends"`
+}
+
+func getOnCallScheduleFinal(ctx context.Context, args GetOnCallScheduleFinalParams) ([]*FinalShiftSummary, error) {
+	start, err := time.Parse(time.DateOnly, args.StartDate)
+	if err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid startDate %q, expected YYYY-MM-DD: %w", args.StartDate, err))
+	}
+	end, err := time.Parse(time.DateOnly, args.EndDate)
+	if err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid endDate %q, expected YYYY-MM-DD: %w", args.EndDate, err))
+	}
+	if end.Before(start) {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("endDate %s is before startDate %s", args.EndDate, args.StartDate))
+	}
+	if end.Sub(start) > maxFinalScheduleDays*24*time.Hour {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("the window between startDate and endDate must be at most %d days", maxFinalScheduleDays))
+	}
+
+	client, err := oncallClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting OnCall client: %w", err)
+	}
+
+	shifts, err := listFinalShifts(client, args.ScheduleID, args.StartDate, args.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*FinalShiftSummary, 0, len(shifts))
+	for _, shift := range shifts {
+		summaries = append(summaries, &FinalShiftSummary{
+			UserID:     shift.UserPK,
+			Username:   shift.UserUsername,
+			Email:      shift.UserEmail,
+			ShiftStart: shift.ShiftStart,
+			ShiftEnd:   shift.ShiftEnd,
+		})
+	}
+	return summaries, nil
+}
+
+var GetOnCallScheduleFinal = mcpgrafana.MustTool(
+	"get_oncall_schedule_final",
+	"Get who is on call, and when, for a schedule between two dates. The shifts are taken from the schedule's final schedule, with rotations and overrides applied. The window can be at most 31 days",
+	getOnCallScheduleFinal,
+)
+
 type ListOnCallTeamsParams struct {
 	Page int `json:"page,omitempty" jsonschema:"description=The page number to return"`
 }
@@ -290,10 +557,375 @@ var ListOnCallUsers = mcpgrafana.MustTool(
 	listOnCallUsers,
 )
 
-func AddOnCallTools(mcp *server.MCPServer) {
-	ListOnCallSchedules.Register(mcp)
-	GetOnCallShift.Register(mcp)
-	GetCurrentOnCallUsers.Register(mcp)
-	ListOnCallTeams.Register(mcp)
-	ListOnCallUsers.Register(mcp)
+type ListOnCallEscalationChainsParams struct {
+	TeamID string `json:"teamId,omitempty" jsonschema:"description=Optionally\\, the ID of the team to list escalation chains for"`
+	Page   int    `json:"page,omitempty" jsonschema:"description=The page number to return (1-based)"`
+}
+
+// EscalationChainSummary represents a simplified view of an OnCall escalation chain
+type EscalationChainSummary struct {
+	ID     string `json:"id" jsonschema:"description=The unique identifier of the escalation chain"`
+	Name   string `json:"name" jsonschema:"description=The name of the escalation chain"`
+	TeamID string `json:"teamId" jsonschema:"description=The ID of the team this escalation chain belongs to"`
+}
+
+func listOnCallEscalationChains(ctx context.Context, args ListOnCallEscalationChainsParams) ([]*EscalationChainSummary, error) {
+	client, err := oncallClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting OnCall client: %w", err)
+	}
+
+	listOptions := &aapi.ListEscalationChainOptions{}
+	if args.Page > 0 {
+		listOptions.Page = args.Page
+	}
+
+	escalationChainService := aapi.NewEscalationChainService(client)
+	response, _, err := escalationChainService.ListEscalationChains(listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("listing OnCall escalation chains: %w", err)
+	}
+
+	summaries := make([]*EscalationChainSummary, 0, len(response.EscalationChains))
+	for _, chain := range response.EscalationChains {
+		// Filter by team ID if provided. Note: We filter here because the API doesn't support
+		// filtering by team ID directly in the ListEscalationChains endpoint.
+		if args.TeamID != "" && chain.TeamId != args.TeamID {
+			continue
+		}
+		summaries = append(summaries, &EscalationChainSummary{
+			ID:     chain.ID,
+			Name:   chain.Name,
+			TeamID: chain.TeamId,
+		})
+	}
+
+	return summaries, nil
+}
+
+var ListOnCallEscalationChains = mcpgrafana.MustTool(
+	"list_oncall_escalation_chains",
+	"List OnCall escalation chains. An escalation chain defines who is notified about an alert group, and when",
+	listOnCallEscalationChains,
+)
+
+// alertGroupStates are the states an OnCall alert group can be in.
+var alertGroupStates = []string{"new", "acknowledged", "resolved", "silenced"}
+
+// alertGroup is an OnCall alert group as returned by the OnCall API. The
+// amixr client doesn't have an alert group service, so requests are made with
+// the client's generic request methods.
+type alertGroup struct {
+	ID             string  `json:"id"`
+	Title          string  `json:"title"`
+	State          string  `json:"state"`
+	AlertsCount    int     `json:"alerts_count"`
+	CreatedAt      string  `json:"created_at"`
+	AcknowledgedAt *string `json:"acknowledged_at"`
+	ResolvedAt     *string `json:"resolved_at"`
+}
+
+type paginatedAlertGroupsResponse struct {
+	aapi.PaginatedResponse
+	AlertGroups []*alertGroup `json:"results"`
+}
+
+type listAlertGroupOptions struct {
+	aapi.ListOptions
+	State  string `url:"state,omitempty"`
+	TeamID string `url:"team_id,omitempty"`
+}
+
+// AlertGroupSummary represents a simplified view of an OnCall alert group
+type AlertGroupSummary struct {
+	ID             string `json:"id" jsonschema:"description=The unique identifier of the alert group"`
+	Title          string `json:"title" jsonschema:"description=The title of the alert group"`
+	State          string `json:"state" jsonschema:"description=The state of the alert group: new\\, acknowledged\\, resolved or silenced"`
+	AlertsCount    int    `json:"alertsCount" jsonschema:"description=The number of alerts in the alert group"`
+	CreatedAt      string `json:"createdAt" jsonschema:"description=When the alert group was created"`
+	Acknowledged   bool   `json:"acknowledged" jsonschema:"description=Whether the alert group has been acknowledged"`
+	AcknowledgedAt string `json:"acknowledgedAt,omitempty" jsonschema:"description=When the alert group was acknowledged"`
+	Resolved       bool   `json:"resolved" jsonschema:"description=Whether the alert group has been resolved"`
+	ResolvedAt     string `json:"resolvedAt,omitempty" jsonschema:"description=When the alert group was resolved"`
+}
+
+func summarizeAlertGroup(ag *alertGroup) *AlertGroupSummary {
+	summary := &AlertGroupSummary{
+		ID:          ag.ID,
+		Title:       ag.Title,
+		State:       ag.State,
+		AlertsCount: ag.AlertsCount,
+		CreatedAt:   ag.CreatedAt,
+	}
+	if ag.AcknowledgedAt != nil {
+		summary.Acknowledged = true
+		summary.AcknowledgedAt = *ag.AcknowledgedAt
+	}
+	if ag.ResolvedAt != nil {
+		summary.Resolved = true
+		summary.ResolvedAt = *ag.ResolvedAt
+	}
+	return summary
+}
+
+type ListOnCallAlertGroupsParams struct {
+	State  string `json:"state,omitempty" jsonschema:"description=Optionally\\, the state of the alert groups to return,enum=new,enum=acknowledged,enum=resolved,enum=silenced"`
+	TeamID string `json:"teamId,omitempty" jsonschema:"description=Optionally\\, the ID of the team to list alert groups for"`
+	Page   int    `json:"page,omitempty" jsonschema:"description=The page number to return (1-based)"`
+}
+
+func listOnCallAlertGroups(ctx context.Context, args ListOnCallAlertGroupsParams) ([]*AlertGroupSummary, error) {
+	if args.State != "" && !slices.Contains(alertGroupStates, args.State) {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid state %q, must be one of %s", args.State, strings.Join(alertGroupStates, ", ")))
+	}
+
+	client, err := oncallClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting OnCall client: %w", err)
+	}
+
+	listOptions := &listAlertGroupOptions{
+		State:  args.State,
+		TeamID: args.TeamID,
+	}
+	if args.Page > 0 {
+		listOptions.Page = args.Page
+	}
+
+	req, err := client.NewRequest("GET", "alert_groups/", listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("creating OnCall alert groups request: %w", err)
+	}
+	var response paginatedAlertGroupsResponse
+	if _, err := client.Do(req, &response); err != nil {
+		return nil, fmt.Errorf("listing OnCall alert groups: %w", err)
+	}
+
+	summaries := make([]*AlertGroupSummary, 0, len(response.AlertGroups))
+	for _, ag := range response.AlertGroups {
+		summaries = append(summaries, summarizeAlertGroup(ag))
+	}
+	return summaries, nil
+}
+
+var ListOnCallAlertGroups = mcpgrafana.MustTool(
+	"list_oncall_alert_groups",
+	"List OnCall alert groups, most recent first. An alert group is a set of related alerts which notifies the on-call team. Optionally filter by state or team",
+	listOnCallAlertGroups,
+)
+
+type GetOnCallAlertGroupParams struct {
+	AlertGroupID string `json:"alertGroupId" jsonschema:"required,description=The ID of the alert group to get details for"`
+}
+
+// validateAlertGroupID checks that `id` looks like an OnCall public API ID,
+// such as "I68T24C13IFW1".
+func validateAlertGroupID(id string) error {
+	if id == "" {
+		return fmt.Errorf("alertGroupId is required")
+	}
+	if !alertGroupIDRegexp.MatchString(id) {
+		return fmt.Errorf("invalid alert group ID %q: must only contain letters and digits", id)
+	}
+	return nil
+}
+
+var alertGroupIDRegexp = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+func getAlertGroup(client *aapi.Client, id string) (*alertGroup, error) {
+	req, err := client.NewRequest("GET", fmt.Sprintf("alert_groups/%s/", id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating OnCall alert group request: %w", err)
+	}
+	var ag alertGroup
+	if _, err := client.Do(req, &ag); err != nil {
+		return nil, fmt.Errorf("getting OnCall alert group %s: %w", id, err)
+	}
+	return &ag, nil
+}
+
+func getOnCallAlertGroup(ctx context.Context, args GetOnCallAlertGroupParams) (*AlertGroupSummary, error) {
+	if err := validateAlertGroupID(args.AlertGroupID); err != nil {
+		return nil, err
+	}
+
+	client, err := oncallClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting OnCall client: %w", err)
+	}
+
+	ag, err := getAlertGroup(client, args.AlertGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeAlertGroup(ag), nil
+}
+
+var GetOnCallAlertGroup = mcpgrafana.MustTool(
+	"get_oncall_alert_group",
+	"Get details for a specific OnCall alert group, including its state and when it was acknowledged or resolved",
+	getOnCallAlertGroup,
+)
+
+// updateAlertGroup performs `action` (e.g. "acknowledge" or "resolve") on an
+// alert group and returns the alert group's new state.
+func updateAlertGroup(ctx context.Context, id, action string) (*AlertGroupSummary, error) {
+	if err := validateAlertGroupID(id); err != nil {
+		return nil, err
+	}
+
+	client, err := oncallClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting OnCall client: %w", err)
+	}
+
+	req, err := client.NewRequest("POST", fmt.Sprintf("alert_groups/%s/%s/", id, action), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating OnCall alert group %s request: %w", action, err)
+	}
+	if _, err := client.Do(req, nil); err != nil {
+		return nil, fmt.Errorf("performing %s on OnCall alert group %s: %w", action, id, err)
+	}
+
+	ag, err := getAlertGroup(client, id)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeAlertGroup(ag), nil
+}
+
+type AcknowledgeOnCallAlertGroupParams struct {
+	AlertGroupID string `json:"alertGroupId" jsonschema:"required,description=The ID of the alert group to acknowledge"`
+}
+
+func acknowledgeOnCallAlertGroup(ctx context.Context, args AcknowledgeOnCallAlertGroupParams) (*AlertGroupSummary, error) {
+	return updateAlertGroup(ctx, args.AlertGroupID, "acknowledge")
+}
+
+var AcknowledgeOnCallAlertGroup = mcpgrafana.MustTool(
+	"acknowledge_oncall_alert_group",
+	"Acknowledge an OnCall alert group, stopping its escalation. Returns the updated alert group",
+	acknowledgeOnCallAlertGroup,
+).AsMutating()
+
+type ResolveOnCallAlertGroupParams struct {
+	AlertGroupID string `json:"alertGroupId" jsonschema:"required,description=The ID of the alert group to resolve"`
+}
+
+func resolveOnCallAlertGroup(ctx context.Context, args ResolveOnCallAlertGroupParams) (*AlertGroupSummary, error) {
+	return updateAlertGroup(ctx, args.AlertGroupID, "resolve")
+}
+
+var ResolveOnCallAlertGroup = mcpgrafana.MustTool(
+	"resolve_oncall_alert_group",
+	"Resolve an OnCall alert group. Returns the updated alert group",
+	resolveOnCallAlertGroup,
+).AsMutating()
+
+type GetUserOnCallStatusParams struct {
+	UserID   string `json:"userId,omitempty" jsonschema:"description=The ID of the OnCall user. Either this or username is required"`
+	Username string `json:"username,omitempty" jsonschema:"description=The username of the OnCall user. Either this or userId is required"`
+}
+
+// OnCallScheduleRef identifies a schedule a user is on call in
+type OnCallScheduleRef struct {
+	ID     string `json:"id" jsonschema:"description=The ID of the schedule"`
+	Name   string `json:"name" jsonschema:"description=The name of the schedule"`
+	Source string `json:"source" jsonschema:"description=How the user was found to be on call: 'on_call_now' or 'final_schedule'\\, as for get_current_oncall_users"`
+}
+
+// UserOnCallStatus represents whether a user is on call right now
+type UserOnCallStatus struct {
+	UserID    string              `json:"userId" jsonschema:"description=The ID of the user"`
+	Username  string              `json:"username" jsonschema:"description=The username of the user"`
+	OnCall    bool                `json:"onCall" jsonschema:"description=Whether the user is on call in any schedule"`
+	Schedules []OnCallScheduleRef `json:"schedules" jsonschema:"description=The schedules the user is on call in"`
+}
+
+// findOnCallUser returns the user with the given ID, or else the given
+// username.
+func findOnCallUser(client *aapi.Client, userID, username string) (*aapi.User, error) {
+	userService := aapi.NewUserService(client)
+	if userID != "" {
+		user, _, err := userService.GetUser(userID, &aapi.GetUserOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("getting OnCall user %s: %w", userID, err)
+		}
+		return user, nil
+	}
+	response, _, err := userService.ListUsers(&aapi.ListUserOptions{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("listing OnCall users: %w", err)
+	}
+	for _, user := range response.Users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, mcpgrafana.NewToolError(fmt.Errorf("no OnCall user with username %s", username))
+}
+
+func getUserOnCallStatus(ctx context.Context, args GetUserOnCallStatusParams) (*UserOnCallStatus, error) {
+	if args.UserID == "" && args.Username == "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("either userId or username is required"))
+	}
+	client, err := oncallClientFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting OnCall client: %w", err)
+	}
+	user, err := findOnCallUser(client, args.UserID, args.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserOnCallStatus{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Schedules: []OnCallScheduleRef{},
+	}
+	scheduleService := aapi.NewScheduleService(client)
+	now := time.Now()
+	for page := 1; ; page++ {
+		response, _, err := scheduleService.ListSchedules(&aapi.ListScheduleOptions{ListOptions: aapi.ListOptions{Page: page}})
+		if err != nil {
+			return nil, fmt.Errorf("listing OnCall schedules: %w", err)
+		}
+		for _, schedule := range response.Schedules {
+			users, source, err := getScheduleOnCallUsers(client, schedule, now)
+			if err != nil {
+				return nil, err
+			}
+			if slices.Contains(users, user.ID) {
+				result.Schedules = append(result.Schedules, OnCallScheduleRef{ID: schedule.ID, Name: schedule.Name, Source: source})
+			}
+		}
+		if response.Next == nil {
+			break
+		}
+	}
+	result.OnCall = len(result.Schedules) > 0
+	return result, nil
+}
+
+var GetUserOnCallStatus = mcpgrafana.MustTool(
+	"get_user_oncall_status",
+	"Check whether an OnCall user, given by ID or username, is on call right now in any schedule, returning the schedules they are on call in. Use this to answer 'am I on call?' without checking each schedule",
+	getUserOnCallStatus,
+)
+
+func AddOnCallTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "oncall",
+		ListOnCallSchedules,
+		GetOnCallShift,
+		GetCurrentOnCallUsers,
+		GetUserOnCallStatus,
+		GetOnCallScheduleFinal,
+		ListOnCallTeams,
+		ListOnCallUsers,
+		ListOnCallEscalationChains,
+		ListOnCallAlertGroups,
+		GetOnCallAlertGroup,
+		AcknowledgeOnCallAlertGroup,
+		ResolveOnCallAlertGroup,
+	)
 }
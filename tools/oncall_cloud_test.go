@@ -16,6 +16,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	mcpgrafana "github.com/grafana/mcp-grafana"
 	"github.com/stretchr/testify/assert"
@@ -153,6 +154,36 @@ func TestCloudGetCurrentOnCallUsers(t *testing.T) {
 		assert.Equal(t, scheduleID, result.ScheduleID, "Should return the correct schedule")
 		assert.NotEmpty(t, result.ScheduleName, "Schedule should have a name")
 		assert.NotNil(t, result.Users, "Users field should be present")
+		assert.Contains(t, []string{onCallUsersSourceOnCallNow, onCallUsersSourceFinalSchedule}, result.Source, "Source should be set")
+	})
+
+	t.Run("get current on-call users for schedule with shift", func(t *testing.T) {
+		// Only the schedule with a team assigned has a shift.
+		var scheduleWithShift *ScheduleSummary
+		for _, schedule := range schedules {
+			if schedule.TeamID != "" {
+				scheduleWithShift = schedule
+			}
+		}
+		require.NotNil(t, scheduleWithShift, "Should have a schedule with a team assigned")
+
+		result, err := getCurrentOnCallUsers(ctx, GetCurrentOnCallUsersParams{
+			ScheduleID: scheduleWithShift.ID,
+		})
+		require.NoError(t, err, "Should not error when getting current on-call users")
+		assert.NotEmpty(t, result.Users, "Someone should be on call for the schedule with a shift")
+		assert.Empty(t, result.UserDetails, "User details should only be returned when requested")
+
+		detailed, err := getCurrentOnCallUsers(ctx, GetCurrentOnCallUsersParams{
+			ScheduleID:         scheduleWithShift.ID,
+			IncludeUserDetails: true,
+		})
+		require.NoError(t, err, "Should not error when getting current on-call users with details")
+		require.Len(t, detailed.UserDetails, len(detailed.Users), "Should return details for each user")
+		for i, user := range detailed.UserDetails {
+			assert.Equal(t, detailed.Users[i], user.ID, "User details should be in the same order as the IDs")
+			assert.NotEmpty(t, user.Username, "User should have a username")
+		}
 	})
 
 	t.Run("get current on-call users with invalid schedule ID", func(t *testing.T) {
@@ -163,6 +194,73 @@ func TestCloudGetCurrentOnCallUsers(t *testing.T) {
 	})
 }
 
+func TestCloudGetUserOnCallStatus(t *testing.T) {
+	ctx := createOnCallCloudTestContext(t)
+
+	users, err := listOnCallUsers(ctx, ListOnCallUsersParams{})
+	require.NoError(t, err, "Should not error when listing users")
+	require.NotEmpty(t, users, "Should have at least one user to test with")
+
+	t.Run("get user on-call status by username", func(t *testing.T) {
+		result, err := getUserOnCallStatus(ctx, GetUserOnCallStatusParams{Username: users[0].Username})
+		require.NoError(t, err, "Should not error when getting the user's on-call status")
+		assert.Equal(t, users[0].ID, result.UserID, "Should return the correct user")
+		assert.Equal(t, len(result.Schedules) > 0, result.OnCall, "The user should be on call if they are in any schedule")
+	})
+
+	t.Run("get on-call status of unknown username", func(t *testing.T) {
+		_, err := getUserOnCallStatus(ctx, GetUserOnCallStatusParams{Username: "no-such-user"})
+		assert.Error(t, err, "Should error for an unknown username")
+	})
+}
+
+func TestCloudGetOnCallScheduleFinal(t *testing.T) {
+	ctx := createOnCallCloudTestContext(t)
+
+	schedules, err := listOnCallSchedules(ctx, ListOnCallSchedulesParams{})
+	require.NoError(t, err, "Should not error when listing schedules")
+	require.NotEmpty(t, schedules, "Should have at least one schedule to test with")
+
+	t.Run("get final schedule for the next week", func(t *testing.T) {
+		now := time.Now().UTC()
+		result, err := getOnCallScheduleFinal(ctx, GetOnCallScheduleFinalParams{
+			ScheduleID: schedules[0].ID,
+			StartDate:  now.Format(time.DateOnly),
+			EndDate:    now.AddDate(0, 0, 7).Format(time.DateOnly),
+		})
+		require.NoError(t, err, "Should not error when getting the final schedule")
+		for _, shift := range result {
+			assert.NotEmpty(t, shift.UserID, "Shift should have a user")
+			assert.True(t, shift.ShiftEnd.After(shift.ShiftStart), "Shift should end after it starts")
+		}
+	})
+
+	t.Run("get final schedule with too long a window", func(t *testing.T) {
+		_, err := getOnCallScheduleFinal(ctx, GetOnCallScheduleFinalParams{
+			ScheduleID: schedules[0].ID,
+			StartDate:  "2025-01-01",
+			EndDate:    "2025-03-01",
+		})
+		assert.Error(t, err, "Should error when the window is longer than the maximum")
+	})
+
+	t.Run("get final schedule with invalid dates", func(t *testing.T) {
+		_, err := getOnCallScheduleFinal(ctx, GetOnCallScheduleFinalParams{
+			ScheduleID: schedules[0].ID,
+			StartDate:  "2025-01-08",
+			EndDate:    "2025-01-01",
+		})
+		assert.Error(t, err, "Should error when the end date is before the start date")
+
+		_, err = getOnCallScheduleFinal(ctx, GetOnCallScheduleFinalParams{
+			ScheduleID: schedules[0].ID,
+			StartDate:  "next monday",
+			EndDate:    "2025-01-01",
+		})
+		assert.Error(t, err, "Should error when a date is not in YYYY-MM-DD format")
+	})
+}
+
 func TestCloudOnCallTeams(t *testing.T) {
 	ctx := createOnCallCloudTestContext(t)
 
@@ -265,3 +363,97 @@ func TestCloudOnCallUsers(t *testing.T) {
 		assert.Empty(t, result, "Should return empty result set for invalid username")
 	})
 }
+
+func TestCloudOnCallEscalationChains(t *testing.T) {
+	ctx := createOnCallCloudTestContext(t)
+
+	t.Run("list escalation chains", func(t *testing.T) {
+		result, err := listOnCallEscalationChains(ctx, ListOnCallEscalationChainsParams{})
+		require.NoError(t, err, "Should not error when listing escalation chains")
+		assert.NotNil(t, result, "Result should not be nil")
+
+		for _, chain := range result {
+			assert.NotEmpty(t, chain.ID, "Escalation chain should have an ID")
+			assert.NotEmpty(t, chain.Name, "Escalation chain should have a name")
+		}
+	})
+
+	t.Run("list escalation chains by team ID", func(t *testing.T) {
+		result, err := listOnCallEscalationChains(ctx, ListOnCallEscalationChainsParams{
+			TeamID: "non-existent-team-id",
+		})
+		require.NoError(t, err, "Should not error when listing escalation chains by team ID")
+		assert.Empty(t, result, "Should return no escalation chains for an unknown team")
+	})
+}
+
+func TestCloudOnCallAlertGroups(t *testing.T) {
+	ctx := createOnCallCloudTestContext(t)
+
+	t.Run("list alert groups", func(t *testing.T) {
+		result, err := listOnCallAlertGroups(ctx, ListOnCallAlertGroupsParams{})
+		require.NoError(t, err, "Should not error when listing alert groups")
+		assert.NotNil(t, result, "Result should not be nil")
+
+		for _, ag := range result {
+			assert.NotEmpty(t, ag.ID, "Alert group should have an ID")
+			assert.Contains(t, alertGroupStates, ag.State, "Alert group should have a valid state")
+		}
+	})
+
+	t.Run("list alert groups by state", func(t *testing.T) {
+		result, err := listOnCallAlertGroups(ctx, ListOnCallAlertGroupsParams{
+			State: "resolved",
+		})
+		require.NoError(t, err, "Should not error when listing resolved alert groups")
+		for _, ag := range result {
+			assert.Equal(t, "resolved", ag.State, "Should only return resolved alert groups")
+			assert.True(t, ag.Resolved, "Resolved alert groups should be marked as resolved")
+		}
+	})
+
+	t.Run("list alert groups with invalid state", func(t *testing.T) {
+		_, err := listOnCallAlertGroups(ctx, ListOnCallAlertGroupsParams{
+			State: "firing",
+		})
+		assert.Error(t, err, "Should error when listing alert groups with an invalid state")
+	})
+
+	t.Run("get alert group", func(t *testing.T) {
+		alertGroups, err := listOnCallAlertGroups(ctx, ListOnCallAlertGroupsParams{})
+		require.NoError(t, err, "Should not error when listing alert groups")
+		if len(alertGroups) == 0 {
+			t.Skip("No alert groups available for testing")
+		}
+
+		result, err := getOnCallAlertGroup(ctx, GetOnCallAlertGroupParams{
+			AlertGroupID: alertGroups[0].ID,
+		})
+		require.NoError(t, err, "Should not error when getting alert group")
+		assert.Equal(t, alertGroups[0].ID, result.ID, "Should return the correct alert group")
+	})
+
+	t.Run("get alert group with invalid ID", func(t *testing.T) {
+		_, err := getOnCallAlertGroup(ctx, GetOnCallAlertGroupParams{
+			AlertGroupID: "invalid-alert-group-id",
+		})
+		assert.Error(t, err, "Should error when getting alert group with invalid ID")
+	})
+
+	t.Run("acknowledge alert group with invalid ID", func(t *testing.T) {
+		_, err := acknowledgeOnCallAlertGroup(ctx, AcknowledgeOnCallAlertGroupParams{
+			AlertGroupID: "../users",
+		})
+		assert.Error(t, err, "Should error when acknowledging alert group with invalid ID")
+
+		_, err = acknowledgeOnCallAlertGroup(ctx, AcknowledgeOnCallAlertGroupParams{})
+		assert.Error(t, err, "Should error when acknowledging alert group without ID")
+	})
+
+	t.Run("resolve alert group with invalid ID", func(t *testing.T) {
+		_, err := resolveOnCallAlertGroup(ctx, ResolveOnCallAlertGroupParams{
+			AlertGroupID: "../users",
+		})
+		assert.Error(t, err, "Should error when resolving alert group with invalid ID")
+	})
+}
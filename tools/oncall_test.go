@@ -0,0 +1,171 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOnCallURL(t *testing.T) {
+	t.Run("caches the OnCall URL per Grafana URL", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			assert.Equal(t, "/api/plugins/grafana-irm-app/settings", r.URL.Path)
+			_, _ = w.Write([]byte(`{"jsonData":{"onCallApiUrl":"https://oncall.example.com"}}`))
+		}))
+		defer server.Close()
+
+		ctx := context.Background()
+		for i := 0; i < 3; i++ {
+			url, err := getOnCallURL(ctx, server.URL, "token", false)
+			require.NoError(t, err)
+			assert.Equal(t, "https://oncall.example.com", url)
+		}
+		assert.Equal(t, int32(1), requests.Load(), "settings should only be fetched once")
+
+		_, err := getOnCallURL(ctx, server.URL, "token", true)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), requests.Load(), "settings should be refetched when refreshing")
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			_, _ = w.Write([]byte(`{"jsonData":{}}`))
+		}))
+		defer server.Close()
+
+		ctx := context.Background()
+		for i := 0; i < 2; i++ {
+			_, err := getOnCallURL(ctx, server.URL, "token", false)
+			require.Error(t, err)
+		}
+		assert.Equal(t, int32(2), requests.Load())
+	})
+}
+
+func TestGetOnCallURLFromSettings(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{name: "plugin not found", status: http.StatusNotFound, body: `{"message":"Plugin not found"}`, expected: errOnCallPluginNotFound},
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"message":"Unauthorized"}`, expected: errOnCallSettingsUnauthorized},
+		{name: "forbidden", status: http.StatusForbidden, body: `{"message":"Forbidden"}`, expected: errOnCallSettingsUnauthorized},
+		{name: "url not set", status: http.StatusOK, body: `{"jsonData":{}}`, expected: errOnCallURLNotSet},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			_, err := getOnCallURLFromSettings(context.Background(), server.URL, "token")
+			require.ErrorIs(t, err, tc.expected)
+			assert.Contains(t, err.Error(), "grafana-irm-app")
+		})
+	}
+}
+
+func TestGetUserOnCallStatus(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/plugins/grafana-irm-app/settings":
+			_, _ = fmt.Fprintf(w, `{"jsonData":{"onCallApiUrl":%q}}`, server.URL)
+		case strings.HasSuffix(r.URL.Path, "/users/U1/"):
+			_, _ = w.Write([]byte(`{"id":"U1","username":"alice"}`))
+		case strings.HasSuffix(r.URL.Path, "/users/"):
+			assert.Equal(t, "bob", r.URL.Query().Get("username"))
+			_, _ = w.Write([]byte(`{"count":1,"results":[{"id":"U2","username":"bob"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/schedules/"):
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = w.Write([]byte(`{"count":3,"results":[{"id":"S3","name":"Calendar","on_call_now":[]}]}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"count":3,"next":"%s/api/v1/schedules/?page=2","results":[
+				{"id":"S1","name":"Primary","on_call_now":["U1"]},
+				{"id":"S2","name":"Secondary","on_call_now":["U2"]}
+			]}`, server.URL)
+		case strings.HasSuffix(r.URL.Path, "/schedules/S3/final_shifts"):
+			now := time.Now().UTC()
+			_, _ = fmt.Fprintf(w, `{"results":[{"user_pk":"U1","user_username":"alice","shift_start":%q,"shift_end":%q}]}`,
+				now.Add(-time.Hour).Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := mcpgrafana.WithGrafanaURL(context.Background(), server.URL)
+
+	t.Run("by user ID", func(t *testing.T) {
+		result, err := getUserOnCallStatus(ctx, GetUserOnCallStatusParams{UserID: "U1"})
+		require.NoError(t, err)
+		assert.Equal(t, &UserOnCallStatus{
+			UserID:   "U1",
+			Username: "alice",
+			OnCall:   true,
+			Schedules: []OnCallScheduleRef{
+				{ID: "S1", Name: "Primary", Source: onCallUsersSourceOnCallNow},
+				{ID: "S3", Name: "Calendar", Source: onCallUsersSourceFinalSchedule},
+			},
+		}, result)
+	})
+
+	t.Run("by username", func(t *testing.T) {
+		result, err := getUserOnCallStatus(ctx, GetUserOnCallStatusParams{Username: "bob"})
+		require.NoError(t, err)
+		assert.True(t, result.OnCall)
+		assert.Equal(t, []OnCallScheduleRef{{ID: "S2", Name: "Secondary", Source: onCallUsersSourceOnCallNow}}, result.Schedules)
+	})
+
+	t.Run("no user", func(t *testing.T) {
+		_, err := getUserOnCallStatus(ctx, GetUserOnCallStatusParams{})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestGetOnCallScheduleFinalInvalidDates(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		start, end string
+		message    string
+	}{
+		{"rfc3339 start", "2025-01-01T00:00:00Z", "2025-01-02", "invalid startDate"},
+		{"invalid end", "2025-01-01", "tomorrow", "invalid endDate"},
+		{"end before start", "2025-01-02", "2025-01-01", "is before startDate"},
+		{"window too long", "2025-01-01", "2025-03-01", "at most 31 days"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := getOnCallScheduleFinal(context.Background(), GetOnCallScheduleFinalParams{
+				ScheduleID: "S1",
+				StartDate:  tc.start,
+				EndDate:    tc.end,
+			})
+			var toolErr *mcpgrafana.ToolError
+			require.ErrorAs(t, err, &toolErr)
+			assert.Contains(t, err.Error(), tc.message)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"fmt"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// paginate returns the given page (1-based) of items, with limit items per
+// page. A limit of 0 returns all items, and a page of 0 returns the first
+// page; callers should apply their own default limit before calling it. It
+// returns an empty slice if the page is past the end of the items, and a tool
+// error if limit or page is negative.
+func paginate[T any](items []T, limit, page int) ([]T, error) {
+	if limit < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid limit: %d, must be greater than 0", limit))
+	}
+	if page < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid page: %d, must be greater than 0", page))
+	}
+	if limit == 0 {
+		limit = len(items)
+	}
+	if page == 0 {
+		page = 1
+	}
+
+	// Compare the page index rather than computing the start offset
+	// directly, so that huge pages can't overflow.
+	if limit == 0 || page-1 >= (len(items)+limit-1)/limit {
+		return []T{}, nil
+	}
+	start := (page - 1) * limit
+	end := min(start+limit, len(items))
+	return items[start:end], nil
+}
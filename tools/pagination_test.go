@@ -0,0 +1,59 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	for _, tc := range []struct {
+		name     string
+		items    []int
+		limit    int
+		page     int
+		expected []int
+	}{
+		{"defaults", items, 0, 0, []int{1, 2, 3, 4, 5}},
+		{"first page", items, 2, 1, []int{1, 2}},
+		{"default page", items, 2, 0, []int{1, 2}},
+		{"middle page", items, 2, 2, []int{3, 4}},
+		{"partial last page", items, 2, 3, []int{5}},
+		{"full last page", items, 5, 1, []int{1, 2, 3, 4, 5}},
+		{"past the end", items, 2, 4, []int{}},
+		{"far past the end", items, 2, math.MaxInt, []int{}},
+		{"limit larger than items", items, 10, 1, []int{1, 2, 3, 4, 5}},
+		{"limit larger than items, second page", items, 10, 2, []int{}},
+		{"no items", nil, 2, 1, []int{}},
+		{"no items, no limit", nil, 0, 0, []int{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := paginate(tc.items, tc.limit, tc.page)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+
+	for _, tc := range []struct {
+		name  string
+		limit int
+		page  int
+	}{
+		{"negative limit", -1, 1},
+		{"negative page", 1, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := paginate(items, tc.limit, tc.page)
+			var toolErr *mcpgrafana.ToolError
+			assert.True(t, errors.As(err, &toolErr))
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// templateVariablePattern matches references to dashboard template variables
+// in the three syntaxes Grafana supports: $var, ${var} (with optional field
+// path and format, e.g. ${var:csv}) and the deprecated [[var]].
+var templateVariablePattern = regexp.MustCompile(`\$(\w+)|\$\{(\w+)(?:\.[^:}]+)?(?::[^}]+)?\}|\[\[(\w+)(?::\w+)?\]\]`)
+
+type GetDashboardPanelDataParams struct {
+	DashboardUID string            `json:"dashboardUid" jsonschema:"required,description=The UID of the dashboard"`
+	PanelID      int               `json:"panelId" jsonschema:"required,description=The ID of the panel. Use get_dashboard_panel_queries to find it"`
+	StartRFC3339 string            `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339   string            `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+	Variables    map[string]string `json:"variables,omitempty" jsonschema:"description=Optionally\\, values for the dashboard's template variables used by the panel's queries\\, e.g. {\"namespace\": \"prod\"}. Variables which aren't given use their saved value if it is a single value"`
+	MaxRows      int               `json:"maxRows,omitempty" jsonschema:"description=Optionally\\, the maximum number of rows to return for each frame. Defaults to 100"`
+}
+
+type panelQueryResult struct {
+	RefID         string `json:"refId"`
+	DatasourceUID string `json:"datasourceUid"`
+	// Query is the query expression after template variables were
+	// substituted.
+	Query  string         `json:"query,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Frames []frameSummary `json:"frames"`
+}
+
+type panelData struct {
+	Title   string             `json:"title"`
+	Type    string             `json:"type"`
+	Results []panelQueryResult `json:"results"`
+}
+
+// findPanel returns the panel with the given ID in the dashboard, or nil.
+func findPanel(db map[string]any, id int) map[string]any {
+	var found map[string]any
+	walkPanels(db, func(panel map[string]any) {
+		if found == nil && intField(panel, "id") == id {
+			found = panel
+		}
+	})
+	return found
+}
+
+// variableInterpolator substitutes a dashboard's template variables into
+// panel queries.
+type variableInterpolator struct {
+	// values holds the value of each of the dashboard's template variables
+	// which has one.
+	values map[string]string
+	// declared holds the names of all of the dashboard's template variables.
+	declared map[string]bool
+	// missing collects the names of declared variables with no value which
+	// were referenced.
+	missing map[string]bool
+}
+
+// newVariableInterpolator returns an interpolator for the dashboard's
+// template variables. Their saved values are used unless overridden, but only
+// if they have a single value: variables set to 'All' or to several values
+// are left out, since their value depends on how the datasource formats them.
+func newVariableInterpolator(db map[string]any, overrides map[string]string) *variableInterpolator {
+	vi := &variableInterpolator{
+		values:   map[string]string{},
+		declared: map[string]bool{},
+		missing:  map[string]bool{},
+	}
+	templating, _ := db["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+	for _, v := range list {
+		variable, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := stringField(variable, "name")
+		vi.declared[name] = true
+		current, _ := variable["current"].(map[string]any)
+		value := current["value"]
+		if multi, ok := value.([]any); ok && len(multi) == 1 {
+			value = multi[0]
+		}
+		if s, ok := value.(string); ok && s != "$__all" {
+			vi.values[name] = s
+		}
+	}
+	for name, value := range overrides {
+		vi.declared[name] = true
+		vi.values[name] = value
+	}
+	return vi
+}
+
+// interpolate replaces template variables in all of the strings in v, which
+// is a decoded JSON value. As in Grafana, references to names which aren't
+// template variables, such as $1 in a Prometheus label_replace, are left
+// alone, as are Grafana's built-in variables such as $__interval, which
+// Grafana replaces itself.
+func (vi *variableInterpolator) interpolate(v any) any {
+	switch v := v.(type) {
+	case string:
+		return templateVariablePattern.ReplaceAllStringFunc(v, func(ref string) string {
+			m := templateVariablePattern.FindStringSubmatch(ref)
+			name := m[1] + m[2] + m[3]
+			if strings.HasPrefix(name, "__") || !vi.declared[name] {
+				return ref
+			}
+			value, ok := vi.values[name]
+			if !ok {
+				vi.missing[name] = true
+				return ref
+			}
+			return value
+		})
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = vi.interpolate(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = vi.interpolate(item)
+		}
+		return result
+	}
+	return v
+}
+
+// defaultDatasourceUID returns the UID of the org's default datasource.
+func defaultDatasourceUID(ctx context.Context) (string, error) {
+	datasources, err := listDatasources(ctx, ListDatasourcesParams{})
+	if err != nil {
+		return "", err
+	}
+	for _, ds := range datasources {
+		if ds.IsDefault {
+			return ds.UID, nil
+		}
+	}
+	return "", mcpgrafana.NewToolError(fmt.Errorf("the panel uses the default datasource, but there is none"))
+}
+
+// panelTargets returns the visible targets of a panel with template
+// variables substituted and the datasource of each resolved, ready to send to
+// /api/ds/query.
+func panelTargets(ctx context.Context, panel map[string]any, vi *variableInterpolator) ([]map[string]any, error) {
+	targets, _ := panel["targets"].([]any)
+	queries := []map[string]any{}
+	defaultUID := ""
+	for i, t := range targets {
+		target, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		if hidden, _ := target["hide"].(bool); hidden {
+			continue
+		}
+		query := vi.interpolate(target).(map[string]any)
+		if stringField(query, "refId") == "" {
+			query["refId"] = string(rune('A' + i%26))
+		}
+
+		// Targets of panels with the mixed datasource have their own.
+		uid := datasourceUID(query["datasource"])
+		if uid == "" || uid == "-- Mixed --" {
+			uid = datasourceUID(vi.interpolate(panel["datasource"]))
+		}
+		switch uid {
+		case "-- Mixed --", "-- Dashboard --", "grafana":
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("query %s uses the %s datasource, which can't be queried directly", stringField(query, "refId"), uid))
+		case "", "default":
+			if defaultUID == "" {
+				var err error
+				if defaultUID, err = defaultDatasourceUID(ctx); err != nil {
+					return nil, err
+				}
+			}
+			uid = defaultUID
+		}
+		query["datasource"] = map[string]any{"uid": uid}
+		queries = append(queries, query)
+	}
+	if len(vi.missing) > 0 {
+		names := make([]string, 0, len(vi.missing))
+		for name := range vi.missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("the panel's queries use template variables with no value: %s. Give their values in 'variables'", strings.Join(names, ", ")))
+	}
+	return queries, nil
+}
+
+func getDashboardPanelData(ctx context.Context, args GetDashboardPanelDataParams) (*panelData, error) {
+	if args.MaxRows < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid maxRows: %d, must be greater than 0", args.MaxRows))
+	}
+	start, end, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	dashboard, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: args.DashboardUID})
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard panel data: %w", err)
+	}
+	db, ok := dashboard.Dashboard.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("get dashboard panel data: dashboard %s is not a JSON object", args.DashboardUID)
+	}
+	panel := findPanel(db, args.PanelID)
+	if panel == nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get dashboard panel data: dashboard %s has no panel with ID %d", args.DashboardUID, args.PanelID))
+	}
+
+	queries, err := panelTargets(ctx, panel, newVariableInterpolator(db, args.Variables))
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard panel data: %w", err)
+	}
+	result := &panelData{
+		Title:   stringField(panel, "title"),
+		Type:    stringField(panel, "type"),
+		Results: []panelQueryResult{},
+	}
+	if len(queries) == 0 {
+		return result, nil
+	}
+
+	data, err := postDatasourceQueries(ctx, queries, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("get dashboard panel data: %w", err)
+	}
+	maxRows := DefaultQueryDatasourceMaxRows
+	if args.MaxRows > 0 {
+		maxRows = args.MaxRows
+	}
+	for _, query := range queries {
+		refID := stringField(query, "refId")
+		r := panelQueryResult{
+			RefID:         refID,
+			DatasourceUID: datasourceUID(query["datasource"]),
+			Query:         targetExpression(query),
+			Frames:        []frameSummary{},
+		}
+		res, ok := data.Results[refID]
+		if !ok {
+			r.Error = "no result in response"
+		}
+		if res.Error != "" {
+			r.Error = res.Error
+		}
+		for _, frame := range res.Frames {
+			r.Frames = append(r.Frames, summarizeFrame(frame, maxRows))
+		}
+		result.Results = append(result.Results, r)
+	}
+	return result, nil
+}
+
+var GetDashboardPanelData = mcpgrafana.MustTool(
+	"get_dashboard_panel_data",
+	"Run the queries of a dashboard panel over a time range and return the resulting data frames, so you can see what the panel currently shows. Template variables used by the queries take their values from 'variables' or their saved value, and the tool fails with the names of any which have neither",
+	getDashboardPanelData,
+)
@@ -0,0 +1,144 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPanelDashboard = `{
+  "dashboard": {
+    "uid": "svc",
+    "title": "Service",
+    "templating": {"list": [
+      {"name": "ds", "current": {"value": "prometheus"}},
+      {"name": "job", "current": {"value": ["api"]}},
+      {"name": "instance", "current": {"value": "$__all"}}
+    ]},
+    "panels": [
+      {"type": "row", "title": "Overview", "collapsed": true, "panels": [
+        {
+          "id": 4,
+          "type": "timeseries",
+          "title": "Requests",
+          "datasource": {"type": "prometheus", "uid": "${ds}"},
+          "targets": [
+            {"refId": "A", "expr": "sum(rate(http_requests_total{job=\"$job\"}[$__rate_interval]))"},
+            {"refId": "B", "expr": "label_replace(up{job=\"[[job]]\"}, \"x\", \"$1\", \"job\", \"(.*)\")", "hide": true}
+          ]
+        },
+        {
+          "id": 5,
+          "type": "stat",
+          "title": "Per instance",
+          "datasource": {"uid": "prometheus"},
+          "targets": [{"refId": "A", "expr": "up{instance=~\"$instance\"}"}]
+        }
+      ]}
+    ]
+  },
+  "meta": {}
+}`
+
+func TestGetDashboardPanelData(t *testing.T) {
+	var sent []map[string]any
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/dashboards/uid/svc":
+			_, _ = w.Write([]byte(testPanelDashboard))
+		case "/api/ds/query":
+			var body struct {
+				Queries []map[string]any `json:"queries"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			sent = body.Queries
+			_, _ = w.Write([]byte(`{"results": {"A": {"status": 200, "frames": [{
+			  "schema": {"fields": [{"name": "Time", "type": "time"}, {"name": "Value", "type": "number"}]},
+			  "data": {"values": [[1700000000000], [42]]}
+			}]}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	t.Run("runs the visible queries with variables substituted", func(t *testing.T) {
+		result, err := getDashboardPanelData(ctx, GetDashboardPanelDataParams{DashboardUID: "svc", PanelID: 4})
+		require.NoError(t, err)
+		require.Len(t, sent, 1)
+		assert.Equal(t, `sum(rate(http_requests_total{job="api"}[$__rate_interval]))`, sent[0]["expr"])
+		assert.Equal(t, map[string]any{"uid": "prometheus"}, sent[0]["datasource"])
+
+		assert.Equal(t, "Requests", result.Title)
+		require.Len(t, result.Results, 1)
+		assert.Equal(t, "A", result.Results[0].RefID)
+		assert.Equal(t, "prometheus", result.Results[0].DatasourceUID)
+		require.Len(t, result.Results[0].Frames, 1)
+		assert.Equal(t, [][]any{{"2023-11-14T22:13:20Z", float64(42)}}, result.Results[0].Frames[0].Rows)
+	})
+
+	t.Run("explicit variables override saved values", func(t *testing.T) {
+		_, err := getDashboardPanelData(ctx, GetDashboardPanelDataParams{
+			DashboardUID: "svc",
+			PanelID:      5,
+			Variables:    map[string]string{"instance": "host-1"},
+		})
+		require.NoError(t, err)
+		require.Len(t, sent, 1)
+		assert.Equal(t, `up{instance=~"host-1"}`, sent[0]["expr"])
+	})
+
+	t.Run("variables with no value", func(t *testing.T) {
+		_, err := getDashboardPanelData(ctx, GetDashboardPanelDataParams{DashboardUID: "svc", PanelID: 5})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.ErrorContains(t, err, "template variables with no value: instance")
+	})
+
+	t.Run("read-only mode checks the substituted datasource", func(t *testing.T) {
+		sent = nil
+		ctx := mcpgrafana.WithReadOnly(ctx, true)
+		_, err := getDashboardPanelData(ctx, GetDashboardPanelDataParams{DashboardUID: "svc", PanelID: 4})
+		require.NoError(t, err)
+
+		sent = nil
+		_, err = getDashboardPanelData(ctx, GetDashboardPanelDataParams{
+			DashboardUID: "svc",
+			PanelID:      4,
+			Variables:    map[string]string{"ds": "postgres"},
+		})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.ErrorContains(t, err, "can't be queried in read-only mode")
+		assert.Nil(t, sent)
+	})
+
+	t.Run("unknown panel", func(t *testing.T) {
+		_, err := getDashboardPanelData(ctx, GetDashboardPanelDataParams{DashboardUID: "svc", PanelID: 99})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestVariableInterpolator(t *testing.T) {
+	vi := newVariableInterpolator(map[string]any{}, map[string]string{"job": "api"})
+	for _, tc := range []struct{ in, expected string }{
+		{`up{job="$job"}`, `up{job="api"}`},
+		{`up{job="${job}"}`, `up{job="api"}`},
+		{`up{job=~"${job:regex}"}`, `up{job=~"api"}`},
+		{`up{job="[[job]]"}`, `up{job="api"}`},
+		{`label_replace(up, "x", "$1", "job", "(.*)")`, `label_replace(up, "x", "$1", "job", "(.*)")`},
+		{`rate(x[$__rate_interval])`, `rate(x[$__rate_interval])`},
+	} {
+		assert.Equal(t, tc.expected, vi.interpolate(tc.in))
+	}
+	assert.Empty(t, vi.missing)
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/grafana/grafana-openapi-client-go/client/playlists"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+type ListPlaylistsParams struct {
+	Query string `json:"query,omitempty" jsonschema:"description=Optionally\\, only return playlists whose name contains this string"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of playlists to return"`
+}
+
+type playlistSummary struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	// Interval is how long each dashboard is shown for, e.g. "5m".
+	Interval string `json:"interval"`
+}
+
+func listPlaylists(ctx context.Context, args ListPlaylistsParams) ([]playlistSummary, error) {
+	if args.Limit < 0 {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid limit: %d, must be greater than 0", args.Limit))
+	}
+	params := playlists.NewSearchPlaylistsParamsWithContext(ctx)
+	if args.Query != "" {
+		params.SetQuery(&args.Query)
+	}
+	if args.Limit > 0 {
+		limit := int64(args.Limit)
+		params.SetLimit(&limit)
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list playlists: %w", err)
+	}
+	response, err := c.Playlists.SearchPlaylists(params)
+	if err != nil {
+		return nil, fmt.Errorf("list playlists: %w", grafanaAPIError(err))
+	}
+	result := make([]playlistSummary, 0, len(response.Payload))
+	for _, p := range response.Payload {
+		if p != nil {
+			result = append(result, playlistSummary{UID: p.UID, Name: p.Name, Interval: p.Interval})
+		}
+	}
+	return result, nil
+}
+
+var ListPlaylists = mcpgrafana.MustTool(
+	"list_playlists",
+	"List playlists, which rotate through dashboards on wall displays, returning the UID, name and interval of each. Use get_playlist_by_uid to see the dashboards in a playlist",
+	listPlaylists,
+)
+
+type GetPlaylistByUIDParams struct {
+	UID string `json:"uid" jsonschema:"required,description=The UID of the playlist"`
+}
+
+type playlistItem struct {
+	// Type is "dashboard_by_uid" for a single dashboard, or
+	// "dashboard_by_tag" for all of the dashboards with a tag.
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Title string `json:"title,omitempty"`
+}
+
+type playlistDetails struct {
+	playlistSummary
+	// Items are the dashboards shown, in order.
+	Items []playlistItem `json:"items"`
+}
+
+func getPlaylistByUID(ctx context.Context, args GetPlaylistByUIDParams) (*playlistDetails, error) {
+	if args.UID == "" {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("get playlist: uid is required"))
+	}
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get playlist: %w", err)
+	}
+	playlist, err := c.Playlists.GetPlaylistWithParams(playlists.NewGetPlaylistParamsWithContext(ctx).WithUID(args.UID))
+	if err != nil {
+		var notFound *playlists.GetPlaylistNotFound
+		if errors.As(err, &notFound) {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("get playlist: playlist with uid %s not found", args.UID))
+		}
+		return nil, fmt.Errorf("get playlist %s: %w", args.UID, grafanaAPIError(err))
+	}
+	// The generated client drops the items from the playlist, so fetch them
+	// separately.
+	items, err := c.Playlists.GetPlaylistItemsWithParams(playlists.NewGetPlaylistItemsParamsWithContext(ctx).WithUID(args.UID))
+	if err != nil {
+		return nil, fmt.Errorf("get playlist %s items: %w", args.UID, grafanaAPIError(err))
+	}
+	return summarizePlaylist(playlist.Payload, items.Payload), nil
+}
+
+func summarizePlaylist(playlist *models.Playlist, items []*models.PlaylistItem) *playlistDetails {
+	details := &playlistDetails{Items: []playlistItem{}}
+	if playlist != nil {
+		details.playlistSummary = playlistSummary{UID: playlist.UID, Name: playlist.Name, Interval: playlist.Interval}
+	}
+	sorted := make([]*models.PlaylistItem, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			sorted = append(sorted, item)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Order < sorted[j].Order })
+	for _, item := range sorted {
+		details.Items = append(details.Items, playlistItem{Type: item.Type, Value: item.Value, Title: item.Title})
+	}
+	return details
+}
+
+var GetPlaylistByUID = mcpgrafana.MustTool(
+	"get_playlist_by_uid",
+	"Get a playlist by UID, returning its name, the interval each dashboard is shown for, and the dashboards it shows in order. Each item is either a dashboard UID ('dashboard_by_uid') or a tag, showing all dashboards with it ('dashboard_by_tag')",
+	getPlaylistByUID,
+)
+
+// AddPlaylistTools registers all playlist tools with the MCP server
+func AddPlaylistTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "playlists",
+		ListPlaylists,
+		GetPlaylistByUID,
+	)
+}
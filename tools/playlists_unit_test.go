@@ -0,0 +1,61 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+func TestPlaylistTools(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/playlists":
+			assert.Equal(t, "noc", r.URL.Query().Get("query"))
+			_, _ = w.Write([]byte(`[{"id":1,"uid":"noc","name":"NOC wall","interval":"5m"}]`))
+		case "/api/playlists/noc":
+			_, _ = w.Write([]byte(`{"id":1,"uid":"noc","name":"NOC wall","interval":"5m"}`))
+		case "/api/playlists/noc/items":
+			_, _ = w.Write([]byte(`[
+				{"type":"dashboard_by_tag","value":"wall","order":2},
+				{"type":"dashboard_by_uid","value":"overview","title":"Overview","order":1}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Playlist not found"}`))
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		result, err := listPlaylists(ctx, ListPlaylistsParams{Query: "noc"})
+		require.NoError(t, err)
+		assert.Equal(t, []playlistSummary{{UID: "noc", Name: "NOC wall", Interval: "5m"}}, result)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		result, err := getPlaylistByUID(ctx, GetPlaylistByUIDParams{UID: "noc"})
+		require.NoError(t, err)
+		assert.Equal(t, &playlistDetails{
+			playlistSummary: playlistSummary{UID: "noc", Name: "NOC wall", Interval: "5m"},
+			Items: []playlistItem{
+				{Type: "dashboard_by_uid", Value: "overview", Title: "Overview"},
+				{Type: "dashboard_by_tag", Value: "wall"},
+			},
+		}, result)
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		_, err := getPlaylistByUID(ctx, GetPlaylistByUIDParams{UID: "missing"})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.EqualError(t, err, "get playlist: playlist with uid missing not found")
+	})
+}
@@ -2,8 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,7 +18,6 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/prometheus/client_golang/api"
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
-	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 )
@@ -26,23 +32,68 @@ var (
 	}
 )
 
+// promClientFromContext returns a client for the Prometheus datasource with
+// the given UID. Requests go through Grafana's datasource proxy, unless the
+// datasource is configured to be queried at its own URL with
+// GRAFANA_PROM_DIRECT_URL, which avoids the proxy's overhead but also skips
+// the credentials Grafana adds for the datasource. Datasources using basic
+// auth therefore can't be queried directly.
 func promClientFromContext(ctx context.Context, uid string) (promv1.API, error) {
-	grafanaURL, apiKey := mcpgrafana.GrafanaURLFromContext(ctx), mcpgrafana.GrafanaAPIKeyFromContext(ctx)
-	url := fmt.Sprintf("%s/api/datasources/proxy/uid/%s", strings.TrimRight(grafanaURL, "/"), uid)
-	rt := api.DefaultRoundTripper
-	if apiKey != "" {
-		rt = config.NewAuthorizationCredentialsRoundTripper(
-			"Bearer", config.NewInlineSecret(apiKey), rt,
-		)
+	c, err := promAPIClientFromContext(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return promv1.NewAPI(c), nil
+}
+
+// promAPIClientFromContext returns the HTTP client underlying
+// promClientFromContext, for requests promv1.API doesn't support.
+func promAPIClientFromContext(ctx context.Context, uid string) (api.Client, error) {
+	if err := checkDatasourceType(ctx, uid, "Prometheus", "prometheus"); err != nil {
+		return nil, err
+	}
+	address := datasourceProxyURL(mcpgrafana.GrafanaURLFromContext(ctx), uid)
+	transport := newDatasourceTransport(ctx)
+	if mcpgrafana.PromDirectURLFromContext(ctx, uid) {
+		ds, err := requireDatasource(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		if ds.URL == "" {
+			return nil, fmt.Errorf("Prometheus datasource %s has no URL to query directly", uid)
+		}
+		if ds.BasicAuth {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("Prometheus datasource %s uses basic auth, so it can't be queried directly: remove it from GRAFANA_PROM_DIRECT_URL to query it through Grafana", uid))
+		}
+		address = ds.URL
+		transport = newDirectDatasourceTransport(ctx)
 	}
 	c, err := api.NewClient(api.Config{
-		Address:      url,
-		RoundTripper: rt,
+		Address: address,
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   clientTimeout(ctx),
+		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("creating Prometheus client: %w", err)
 	}
-	return promv1.NewAPI(c), nil
+	return c, nil
+}
+
+// prometheusError marks errors from the Prometheus API which the caller can
+// correct as tool errors: invalid queries, and requests which weren't found,
+// usually because the datasource UID is wrong.
+func prometheusError(err error) error {
+	var apiErr *promv1.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	notFound := apiErr.Type == promv1.ErrClient && apiErr.Msg == fmt.Sprintf("client error: %d", http.StatusNotFound)
+	if apiErr.Type == promv1.ErrBadData || notFound {
+		return mcpgrafana.NewToolError(err)
+	}
+	return err
 }
 
 type ListPrometheusMetricMetadataParams struct {
@@ -65,7 +116,7 @@ func listPrometheusMetricMetadata(ctx context.Context, args ListPrometheusMetric
 
 	metadata, err := promClient.Metadata(ctx, args.Metric, fmt.Sprintf("%d", limit))
 	if err != nil {
-		return nil, fmt.Errorf("listing Prometheus metric metadata: %w", err)
+		return nil, prometheusError(fmt.Errorf("listing Prometheus metric metadata: %w", err))
 	}
 	return metadata, nil
 }
@@ -79,68 +130,209 @@ var ListPrometheusMetricMetadata = mcpgrafana.MustTool(
 type QueryPrometheusParams struct {
 	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
 	Expr          string `json:"expr" jsonschema:"required,description=The PromQL expression to query"`
-	StartRFC3339  string `json:"startRfc3339" jsonschema:"required,description=The start time in RFC3339 format"`
-	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=The end time in RFC3339 format. Ignored if queryType is 'instant'"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=The start time in RFC3339 format or relative to now\\, e.g. 'now-1h'\\, or the time to evaluate an instant query at. Defaults to 1 hour before the end time for range queries unless the server is configured otherwise\\, and now for instant queries"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=The end time in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now. Ignored if queryType is 'instant'"`
 	StepSeconds   int    `json:"stepSeconds,omitempty" jsonschema:"description=The time series step size in seconds. Ignored if queryType is 'instant'"`
-	QueryType     string `json:"queryType,omitempty" jsonschema:"description=The type of query to use. Either 'range' or 'instant'"`
+	QueryType     string `json:"queryType,omitempty" jsonschema:"description=The type of query to use. Defaults to 'range',enum=range,enum=instant"`
+	IncludeStats  bool   `json:"includeStats,omitempty" jsonschema:"description=Optionally\\, include statistics about the query\\, such as how long each stage took and how many samples it touched\\, to help understand why it is slow"`
 }
 
-func queryPrometheus(ctx context.Context, args QueryPrometheusParams) (model.Value, error) {
-	promClient, err := promClientFromContext(ctx, args.DatasourceUID)
+// prometheusQueryStatsResult is the result of a Prometheus query run with
+// includeStats.
+type prometheusQueryStatsResult struct {
+	Result model.Value `json:"result"`
+	// Stats is passed through as returned by Prometheus, since its fields
+	// vary between versions and compatible backends.
+	Stats json.RawMessage `json:"stats,omitempty"`
+	// Warning explains why there are no stats.
+	Warning string `json:"warning,omitempty"`
+}
+
+// formatPrometheusTime formats a time as the Prometheus API expects, in
+// seconds since the epoch.
+func formatPrometheusTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.Unix())+float64(t.Nanosecond())/1e9, 'f', -1, 64)
+}
+
+// decodePrometheusValue decodes the result of a query, which is encoded
+// according to its type.
+func decodePrometheusValue(resultType model.ValueType, raw json.RawMessage) (model.Value, error) {
+	switch resultType {
+	case model.ValScalar:
+		v := new(model.Scalar)
+		return v, json.Unmarshal(raw, v)
+	case model.ValString:
+		v := new(model.String)
+		return v, json.Unmarshal(raw, v)
+	case model.ValVector:
+		var v model.Vector
+		return v, json.Unmarshal(raw, &v)
+	case model.ValMatrix:
+		var v model.Matrix
+		return v, json.Unmarshal(raw, &v)
+	}
+	return nil, fmt.Errorf("unexpected result type %q", resultType)
+}
+
+// queryPrometheusWithStats runs a query with stats=all. promv1.API drops the
+// stats from the response, so this makes the request itself, with params
+// holding the query and its time range. Backends which don't support the
+// stats parameter ignore it, and the result has a warning instead.
+func queryPrometheusWithStats(ctx context.Context, c api.Client, path string, params url.Values) (*prometheusQueryStatsResult, error) {
+	params.Set("stats", "all")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(path, nil).String(), strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, body, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Status    string           `json:"status"`
+		ErrorType promv1.ErrorType `json:"errorType"`
+		Error     string           `json:"error"`
+		Data      struct {
+			ResultType model.ValueType `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+			Stats      json.RawMessage `json:"stats"`
+		} `json:"data"`
+	}
+	jsonErr := json.Unmarshal(body, &response)
+	if response.Status == "error" {
+		return nil, &promv1.Error{Type: response.ErrorType, Msg: response.Error}
+	}
+	// Like promv1.API, report other failed requests by their status code.
+	if resp.StatusCode/100 == 4 {
+		return nil, &promv1.Error{Type: promv1.ErrClient, Msg: fmt.Sprintf("client error: %d", resp.StatusCode), Detail: string(body)}
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, &promv1.Error{Type: promv1.ErrServer, Msg: fmt.Sprintf("server error: %d", resp.StatusCode), Detail: string(body)}
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("unmarshalling response: %w", jsonErr)
+	}
+
+	value, err := decodePrometheusValue(response.Data.ResultType, response.Data.Result)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling result: %w", err)
+	}
+	result := &prometheusQueryStatsResult{Result: value, Stats: response.Data.Stats}
+	if len(result.Stats) == 0 || string(result.Stats) == "null" {
+		result.Stats = nil
+		result.Warning = "the datasource returned no query stats, so it may not support them"
+	}
+	return result, nil
+}
+
+// queryPrometheus returns a model.Value, or a prometheusQueryStatsResult if
+// includeStats is set.
+func queryPrometheus(ctx context.Context, args QueryPrometheusParams) (any, error) {
+	c, err := promAPIClientFromContext(ctx, args.DatasourceUID)
 	if err != nil {
 		return nil, fmt.Errorf("getting Prometheus client: %w", err)
 	}
+	promClient := promv1.NewAPI(c)
 
 	queryType := args.QueryType
 	if queryType == "" {
 		queryType = "range"
 	}
 
-	startTime, err := time.Parse(time.RFC3339, args.StartRFC3339)
-	if err != nil {
-		return nil, fmt.Errorf("parsing start time: %w", err)
-	}
-
 	if queryType == "range" {
-		if args.EndRFC3339 == "" || args.StepSeconds == 0 {
-			return nil, fmt.Errorf("endRfc3339 and stepSeconds must be provided when queryType is 'range'")
+		if args.StepSeconds == 0 {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("stepSeconds must be provided when queryType is 'range'"))
 		}
 
-		endTime, err := time.Parse(time.RFC3339, args.EndRFC3339)
+		startTime, endTime, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
 		if err != nil {
-			return nil, fmt.Errorf("parsing end time: %w", err)
+			return nil, err
 		}
 
 		step := time.Duration(args.StepSeconds) * time.Second
+		if args.IncludeStats {
+			result, err := queryPrometheusWithStats(ctx, c, "/api/v1/query_range", url.Values{
+				"query": {args.Expr},
+				"start": {formatPrometheusTime(startTime)},
+				"end":   {formatPrometheusTime(endTime)},
+				"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+			})
+			if err != nil {
+				return nil, prometheusError(fmt.Errorf("querying Prometheus range: %w", err))
+			}
+			return result, nil
+		}
 		result, _, err := promClient.QueryRange(ctx, args.Expr, promv1.Range{
 			Start: startTime,
 			End:   endTime,
 			Step:  step,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("querying Prometheus range: %w", err)
+			return nil, prometheusError(fmt.Errorf("querying Prometheus range: %w", err))
 		}
 		return result, nil
 	} else if queryType == "instant" {
-		result, _, err := promClient.Query(ctx, args.Expr, startTime)
+		evalTime := time.Now()
+		if args.StartRFC3339 != "" {
+			if evalTime, err = parseTime(args.StartRFC3339, evalTime, false); err != nil {
+				return nil, mcpgrafana.NewToolError(fmt.Errorf("parsing start time: %w", err))
+			}
+		}
+		if args.IncludeStats {
+			result, err := queryPrometheusWithStats(ctx, c, "/api/v1/query", url.Values{
+				"query": {args.Expr},
+				"time":  {formatPrometheusTime(evalTime)},
+			})
+			if err != nil {
+				return nil, prometheusError(fmt.Errorf("querying Prometheus instant: %w", err))
+			}
+			return result, nil
+		}
+		result, _, err := promClient.Query(ctx, args.Expr, evalTime)
 		if err != nil {
-			return nil, fmt.Errorf("querying Prometheus instant: %w", err)
+			return nil, prometheusError(fmt.Errorf("querying Prometheus instant: %w", err))
 		}
 		return result, nil
 	}
 
-	return nil, fmt.Errorf("invalid query type: %s", queryType)
+	return nil, mcpgrafana.NewToolError(fmt.Errorf("invalid query type: %s", queryType))
 }
 
 var QueryPrometheus = mcpgrafana.MustTool(
 	"query_prometheus",
-	"Query Prometheus using a range or instant request",
+	"Query Prometheus using a range or instant request. With includeStats, the result is returned as {result, stats}, where stats shows how long the query took and how many samples it touched",
 	queryPrometheus,
 )
 
+// metricNameCancellationInterval is how many metric names are filtered
+// between checks for a cancelled context.
+const metricNameCancellationInterval = 1000
+
+// filterMetricNames returns the metric names matching re, or all of them if
+// re is nil. There can be hundreds of thousands of metric names, so it stops
+// early if the context is cancelled, e.g. because the client went away.
+func filterMetricNames(ctx context.Context, names model.LabelValues, re *regexp.Regexp) ([]string, error) {
+	matches := []string{}
+	for i, name := range names {
+		if i%metricNameCancellationInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("filtering metric names: %w", err)
+			}
+		}
+		if re == nil || re.MatchString(string(name)) {
+			matches = append(matches, string(name))
+		}
+	}
+	return matches, nil
+}
+
 type ListPrometheusMetricNamesParams struct {
 	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
 	Regex         string `json:"regex" jsonschema:"description=The regex to match against the metric names"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, only return metrics with samples after this time in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, only return metrics with samples before this time in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
 	Limit         int    `json:"limit,omitempty" jsonschema:"description=The maximum number of results to return"`
 	Page          int    `json:"page,omitempty" jsonschema:"description=The page number to return"`
 }
@@ -156,52 +348,35 @@ func listPrometheusMetricNames(ctx context.Context, args ListPrometheusMetricNam
 		limit = 10
 	}
 
-	page := args.Page
-	if page == 0 {
-		page = 1
+	startTime, endTime, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get all metric names by querying for __name__ label values
-	labelValues, _, err := promClient.LabelValues(ctx, "__name__", nil, time.Time{}, time.Time{})
+	labelValues, _, err := promClient.LabelValues(ctx, "__name__", nil, startTime, endTime)
 	if err != nil {
-		return nil, fmt.Errorf("listing Prometheus metric names: %w", err)
+		return nil, prometheusError(fmt.Errorf("listing Prometheus metric names: %w", err))
 	}
 
-	// Filter by regex if provided
-	matches := []string{}
+	var re *regexp.Regexp
 	if args.Regex != "" {
-		re, err := regexp.Compile(args.Regex)
+		re, err = regexp.Compile(args.Regex)
 		if err != nil {
-			return nil, fmt.Errorf("compiling regex: %w", err)
-		}
-		for _, val := range labelValues {
-			if re.MatchString(string(val)) {
-				matches = append(matches, string(val))
-			}
-		}
-	} else {
-		for _, val := range labelValues {
-			matches = append(matches, string(val))
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("compiling regex: %w", err))
 		}
 	}
-
-	// Apply pagination
-	start := (page - 1) * limit
-	end := start + limit
-	if start >= len(matches) {
-		matches = []string{}
-	} else if end > len(matches) {
-		matches = matches[start:]
-	} else {
-		matches = matches[start:end]
+	matches, err := filterMetricNames(ctx, labelValues, re)
+	if err != nil {
+		return nil, err
 	}
 
-	return matches, nil
+	return paginate(matches, limit, args.Page)
 }
 
 var ListPrometheusMetricNames = mcpgrafana.MustTool(
 	"list_prometheus_metric_names",
-	"List metric names in a Prometheus datasource that match the given regex",
+	"List metric names in a Prometheus datasource that match the given regex. Only metrics with samples in the time range are returned, which is the last hour unless given or configured otherwise",
 	listPrometheusMetricNames,
 )
 
@@ -238,12 +413,12 @@ func (s Selector) Matches(lbls labels.Labels) (bool, error) {
 	for _, filter := range s.Filters {
 		matchType, ok := matchTypeMap[filter.Type]
 		if !ok {
-			return false, fmt.Errorf("invalid matcher type: %s", filter.Type)
+			return false, mcpgrafana.NewToolError(fmt.Errorf("invalid matcher type: %s", filter.Type))
 		}
 
 		matcher, err := labels.NewMatcher(matchType, filter.Name, filter.Value)
 		if err != nil {
-			return false, fmt.Errorf("creating matcher: %w", err)
+			return false, mcpgrafana.NewToolError(fmt.Errorf("creating matcher: %w", err))
 		}
 
 		matchers = append(matchers, matcher)
@@ -255,8 +430,8 @@ func (s Selector) Matches(lbls labels.Labels) (bool, error) {
 type ListPrometheusLabelNamesParams struct {
 	DatasourceUID string     `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
 	Matches       []Selector `json:"matches,omitempty" jsonschema:"description=Optionally, a list of label matchers to filter the results by"`
-	StartRFC3339  string     `json:"startRfc3339,omitempty" jsonschema:"description=Optionally, the start time of the time range to filter the results by"`
-	EndRFC3339    string     `json:"endRfc3339,omitempty" jsonschema:"description=Optionally, the end time of the time range to filter the results by"`
+	StartRFC3339  string     `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the time range to filter the results by in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339    string     `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the time range to filter the results by in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
 	Limit         int        `json:"limit,omitempty" jsonschema:"description=Optionally, the maximum number of results to return"`
 }
 
@@ -271,16 +446,9 @@ func listPrometheusLabelNames(ctx context.Context, args ListPrometheusLabelNames
 		limit = 100
 	}
 
-	var startTime, endTime time.Time
-	if args.StartRFC3339 != "" {
-		if startTime, err = time.Parse(time.RFC3339, args.StartRFC3339); err != nil {
-			return nil, fmt.Errorf("parsing start time: %w", err)
-		}
-	}
-	if args.EndRFC3339 != "" {
-		if endTime, err = time.Parse(time.RFC3339, args.EndRFC3339); err != nil {
-			return nil, fmt.Errorf("parsing end time: %w", err)
-		}
+	startTime, endTime, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
 	}
 
 	var matchers []string
@@ -290,7 +458,7 @@ func listPrometheusLabelNames(ctx context.Context, args ListPrometheusLabelNames
 
 	labelNames, _, err := promClient.LabelNames(ctx, matchers, startTime, endTime)
 	if err != nil {
-		return nil, fmt.Errorf("listing Prometheus label names: %w", err)
+		return nil, prometheusError(fmt.Errorf("listing Prometheus label names: %w", err))
 	}
 
 	// Apply limit
@@ -311,8 +479,8 @@ type ListPrometheusLabelValuesParams struct {
 	DatasourceUID string     `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
 	LabelName     string     `json:"labelName" jsonschema:"required,description=The name of the label to query"`
 	Matches       []Selector `json:"matches,omitempty" jsonschema:"description=Optionally, a list of selectors to filter the results by"`
-	StartRFC3339  string     `json:"startRfc3339,omitempty" jsonschema:"description=Optionally, the start time of the query"`
-	EndRFC3339    string     `json:"endRfc3339,omitempty" jsonschema:"description=Optionally, the end time of the query"`
+	StartRFC3339  string     `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339    string     `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the query in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
 	Limit         int        `json:"limit,omitempty" jsonschema:"description=Optionally, the maximum number of results to return"`
 }
 
@@ -327,16 +495,9 @@ func listPrometheusLabelValues(ctx context.Context, args ListPrometheusLabelValu
 		limit = 100
 	}
 
-	var startTime, endTime time.Time
-	if args.StartRFC3339 != "" {
-		if startTime, err = time.Parse(time.RFC3339, args.StartRFC3339); err != nil {
-			return nil, fmt.Errorf("parsing start time: %w", err)
-		}
-	}
-	if args.EndRFC3339 != "" {
-		if endTime, err = time.Parse(time.RFC3339, args.EndRFC3339); err != nil {
-			return nil, fmt.Errorf("parsing end time: %w", err)
-		}
+	startTime, endTime, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
 	}
 
 	var matchers []string
@@ -346,7 +507,7 @@ func listPrometheusLabelValues(ctx context.Context, args ListPrometheusLabelValu
 
 	labelValues, _, err := promClient.LabelValues(ctx, args.LabelName, matchers, startTime, endTime)
 	if err != nil {
-		return nil, fmt.Errorf("listing Prometheus label values: %w", err)
+		return nil, prometheusError(fmt.Errorf("listing Prometheus label values: %w", err))
 	}
 
 	// Apply limit
@@ -363,10 +524,182 @@ var ListPrometheusLabelValues = mcpgrafana.MustTool(
 	listPrometheusLabelValues,
 )
 
-func AddPrometheusTools(mcp *server.MCPServer) {
-	ListPrometheusMetricMetadata.Register(mcp)
-	QueryPrometheus.Register(mcp)
-	ListPrometheusMetricNames.Register(mcp)
-	ListPrometheusLabelNames.Register(mcp)
-	ListPrometheusLabelValues.Register(mcp)
+type ListPrometheusMetricValuesParams struct {
+	DatasourceUID string     `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
+	Metric        string     `json:"metric" jsonschema:"required,description=The name of the metric to get the values of"`
+	Matches       []Selector `json:"matches,omitempty" jsonschema:"description=Optionally\\, a list of selectors to filter the series by. Series matching any of the selectors are returned"`
+	TimeRFC3339   string     `json:"timeRfc3339,omitempty" jsonschema:"description=Optionally\\, the time to get the values at in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+	Limit         int        `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of series to return. Defaults to 100"`
+}
+
+type metricValue struct {
+	Labels map[string]string `json:"labels"`
+	Value  model.SampleValue `json:"value"`
+}
+
+type listPrometheusMetricValuesResult struct {
+	Values []metricValue `json:"values"`
+	// TotalSeries is the number of series returned by the query, which may
+	// be more than were returned.
+	TotalSeries int `json:"totalSeries"`
+}
+
+// metricValuesQuery returns an instant query selecting the series of the
+// given metric which match any of the selectors.
+func metricValuesQuery(metric string, selectors []Selector) (string, error) {
+	// The name is used as-is in the query, so it can't need quoting.
+	if !model.IsValidLegacyMetricName(metric) {
+		return "", fmt.Errorf("invalid metric name: %q", metric)
+	}
+	if len(selectors) == 0 {
+		return metric, nil
+	}
+	queries := make([]string, 0, len(selectors))
+	for _, s := range selectors {
+		for _, f := range s.Filters {
+			if _, ok := matchTypeMap[f.Type]; !ok {
+				return "", fmt.Errorf("invalid matcher type: %s", f.Type)
+			}
+		}
+		queries = append(queries, metric+s.String())
+	}
+	return strings.Join(queries, " or "), nil
+}
+
+func listPrometheusMetricValues(ctx context.Context, args ListPrometheusMetricValuesParams) (*listPrometheusMetricValuesResult, error) {
+	query, err := metricValuesQuery(args.Metric, args.Matches)
+	if err != nil {
+		return nil, mcpgrafana.NewToolError(err)
+	}
+	ts := time.Now()
+	if args.TimeRFC3339 != "" {
+		if ts, err = parseTime(args.TimeRFC3339, ts, false); err != nil {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("parsing time: %w", err))
+		}
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	promClient, err := promClientFromContext(ctx, args.DatasourceUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting Prometheus client: %w", err)
+	}
+	result, _, err := promClient.Query(ctx, query, ts)
+	if err != nil {
+		return nil, prometheusError(fmt.Errorf("querying Prometheus instant: %w", err))
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %s for query %s", result.Type(), query)
+	}
+
+	values := make([]metricValue, 0, len(vector))
+	for _, sample := range vector {
+		labels := make(map[string]string, len(sample.Metric))
+		for k, v := range sample.Metric {
+			labels[string(k)] = string(v)
+		}
+		values = append(values, metricValue{Labels: labels, Value: sample.Value})
+	}
+	// Sort by value, largest first, with NaNs last.
+	sort.SliceStable(values, func(i, j int) bool {
+		a, b := float64(values[i].Value), float64(values[j].Value)
+		return a > b || (!math.IsNaN(a) && math.IsNaN(b))
+	})
+	total := len(values)
+	if len(values) > limit {
+		values = values[:limit]
+	}
+	return &listPrometheusMetricValuesResult{Values: values, TotalSeries: total}, nil
+}
+
+var ListPrometheusMetricValues = mcpgrafana.MustTool(
+	"list_prometheus_metric_values",
+	"Get the current values of a Prometheus metric without writing PromQL, optionally filtered by label selectors or at a given time. Returns the labels and value of each series, largest value first",
+	listPrometheusMetricValues,
+)
+
+type GetPrometheusLabelCardinalityParams struct {
+	DatasourceUID string     `json:"datasourceUid" jsonschema:"required,description=The UID of the datasource to query"`
+	LabelNames    []string   `json:"labelNames,omitempty" jsonschema:"description=Optionally\\, the names of the labels to count the values of. Defaults to all labels"`
+	Matches       []Selector `json:"matches,omitempty" jsonschema:"description=Optionally\\, a list of selectors to only count the values of labels of matching series"`
+	StartRFC3339  string     `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start time of the time range to count values in\\, in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339    string     `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end time of the time range to count values in\\, in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+	Limit         int        `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of labels to return. Defaults to 20"`
+}
+
+type labelCardinality struct {
+	Label  string `json:"label"`
+	Values int    `json:"values"`
+}
+
+func getPrometheusLabelCardinality(ctx context.Context, args GetPrometheusLabelCardinalityParams) ([]labelCardinality, error) {
+	promClient, err := promClientFromContext(ctx, args.DatasourceUID)
+	if err != nil {
+		return nil, fmt.Errorf("getting Prometheus client: %w", err)
+	}
+
+	limit := args.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	startTime, endTime, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchers []string
+	for _, m := range args.Matches {
+		matchers = append(matchers, m.String())
+	}
+
+	labelNames := args.LabelNames
+	if len(labelNames) == 0 {
+		if labelNames, _, err = promClient.LabelNames(ctx, matchers, startTime, endTime); err != nil {
+			return nil, prometheusError(fmt.Errorf("listing Prometheus label names: %w", err))
+		}
+	}
+
+	cardinality := make([]labelCardinality, len(labelNames))
+	err = forEachConcurrently(ctx, len(labelNames), func(ctx context.Context, i int) error {
+		name := labelNames[i]
+		values, _, err := promClient.LabelValues(ctx, name, matchers, startTime, endTime)
+		if err != nil {
+			return prometheusError(fmt.Errorf("listing Prometheus label values for %s: %w", name, err))
+		}
+		cardinality[i] = labelCardinality{Label: name, Values: len(values)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cardinality, func(i, j int) bool {
+		return cardinality[i].Values > cardinality[j].Values
+	})
+	if len(cardinality) > limit {
+		cardinality = cardinality[:limit]
+	}
+	return cardinality, nil
+}
+
+var GetPrometheusLabelCardinality = mcpgrafana.MustTool(
+	"get_prometheus_label_cardinality",
+	"Count the number of distinct values of each label in a Prometheus datasource, or of the given labels, optionally only for series matching selectors. Returns the labels with the most values first, to find the labels driving high series cardinality",
+	getPrometheusLabelCardinality,
+)
+
+func AddPrometheusTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "prometheus",
+		ListPrometheusMetricMetadata,
+		QueryPrometheus,
+		ListPrometheusMetricNames,
+		ListPrometheusLabelNames,
+		ListPrometheusLabelValues,
+		ListPrometheusMetricValues,
+		GetPrometheusLabelCardinality,
+	)
 }
@@ -70,6 +70,38 @@ func TestPrometheusTools(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, result, 1)
 	})
+
+	t.Run("list prometheus metric values", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listPrometheusMetricValues(ctx, ListPrometheusMetricValuesParams{
+			DatasourceUID: "prometheus",
+			Metric:        "up",
+			Matches: []Selector{
+				{
+					Filters: []LabelMatcher{
+						{Name: "job", Value: "prometheus"},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Values, 1)
+		assert.Equal(t, "prometheus", result.Values[0].Labels["job"])
+		assert.Equal(t, model.SampleValue(1), result.Values[0].Value)
+	})
+
+	t.Run("get prometheus label cardinality", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := getPrometheusLabelCardinality(ctx, GetPrometheusLabelCardinalityParams{
+			DatasourceUID: "prometheus",
+			LabelNames:    []string{"job", "__name__"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		// There are many more metric names than jobs.
+		assert.Equal(t, "__name__", result[0].Label)
+		assert.Greater(t, result[0].Values, result[1].Values)
+	})
 }
 
 func TestSelectorMatches(t *testing.T) {
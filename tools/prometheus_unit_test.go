@@ -0,0 +1,339 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMetricNames(t *testing.T) {
+	names := model.LabelValues{"up", "node_load1", "node_load5", "go_goroutines"}
+
+	t.Run("no regex", func(t *testing.T) {
+		matches, err := filterMetricNames(context.Background(), names, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"up", "node_load1", "node_load5", "go_goroutines"}, matches)
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		matches, err := filterMetricNames(context.Background(), names, regexp.MustCompile("^node_"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"node_load1", "node_load5"}, matches)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := filterMetricNames(ctx, names, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestPrometheusError(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		err       error
+		toolError bool
+	}{
+		{"bad data", &promv1.Error{Type: promv1.ErrBadData, Msg: "parse error"}, true},
+		{"not found", &promv1.Error{Type: promv1.ErrClient, Msg: "client error: 404"}, true},
+		{"forbidden", &promv1.Error{Type: promv1.ErrClient, Msg: "client error: 403"}, false},
+		{"server error", &promv1.Error{Type: promv1.ErrServer, Msg: "server error: 500"}, false},
+		{"other", errors.New("connection refused"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := prometheusError(fmt.Errorf("querying Prometheus: %w", tc.err))
+			var toolErr *mcpgrafana.ToolError
+			assert.Equal(t, tc.toolError, errors.As(err, &toolErr))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestMetricValuesQuery(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		selectors []Selector
+		expected  string
+	}{
+		{"no selectors", nil, "up"},
+		{"one selector", []Selector{{Filters: []LabelMatcher{{Name: "job", Value: "api", Type: "="}}}}, "up{job='api'}"},
+		{
+			"several selectors",
+			[]Selector{
+				{Filters: []LabelMatcher{{Name: "job", Value: "api"}}},
+				{Filters: []LabelMatcher{{Name: "instance", Value: "db.*", Type: "=~"}}},
+			},
+			"up{job='api'} or up{instance=~'db.*'}",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := metricValuesQuery("up", tc.selectors)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, query)
+		})
+	}
+
+	t.Run("invalid metric name", func(t *testing.T) {
+		_, err := metricValuesQuery("up{job='api'}", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid matcher type", func(t *testing.T) {
+		_, err := metricValuesQuery("up", []Selector{{Filters: []LabelMatcher{{Name: "job", Value: "api", Type: "=="}}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestListPrometheusMetricValues(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/datasources/proxy/uid/prometheus/api/v1/query", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "up", r.Form.Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"up","job":"a"},"value":[1700000000,"1"]},
+			{"metric":{"__name__":"up","job":"b"},"value":[1700000000,"NaN"]},
+			{"metric":{"__name__":"up","job":"c"},"value":[1700000000,"3"]},
+			{"metric":{"__name__":"up","job":"d"},"value":[1700000000,"2"]}
+		]}}`))
+	})
+
+	result, err := listPrometheusMetricValues(ctx, ListPrometheusMetricValuesParams{
+		DatasourceUID: "prometheus",
+		Metric:        "up",
+		Limit:         3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.TotalSeries)
+	require.Len(t, result.Values, 3)
+	for i, job := range []string{"c", "d", "a"} {
+		assert.Equal(t, job, result.Values[i].Labels["job"])
+	}
+	assert.Equal(t, model.SampleValue(3), result.Values[0].Value)
+}
+
+func TestQueryPrometheusIncludeStats(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Form.Get("query") {
+		case "up":
+			assert.Equal(t, "/api/datasources/proxy/uid/prometheus/api/v1/query_range", r.URL.Path)
+			assert.Equal(t, "all", r.Form.Get("stats"))
+			assert.Equal(t, "60", r.Form.Get("step"))
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"__name__":"up"},"values":[[1700000000,"1"]]}
+			],"stats":{"timings":{"evalTotalTime":0.01},"samples":{"totalQueryableSamples":1}}}}`))
+		case "no_stats":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"2"]}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		}
+	})
+
+	t.Run("with stats", func(t *testing.T) {
+		result, err := queryPrometheus(ctx, QueryPrometheusParams{
+			DatasourceUID: "prometheus",
+			Expr:          "up",
+			StartRFC3339:  "now-1h",
+			StepSeconds:   60,
+			IncludeStats:  true,
+		})
+		require.NoError(t, err)
+		r := result.(*prometheusQueryStatsResult)
+		matrix := r.Result.(model.Matrix)
+		require.Len(t, matrix, 1)
+		assert.Equal(t, model.SampleValue(1), matrix[0].Values[0].Value)
+		assert.JSONEq(t, `{"timings":{"evalTotalTime":0.01},"samples":{"totalQueryableSamples":1}}`, string(r.Stats))
+		assert.Empty(t, r.Warning)
+	})
+
+	t.Run("backend without stats", func(t *testing.T) {
+		result, err := queryPrometheus(ctx, QueryPrometheusParams{
+			DatasourceUID: "prometheus",
+			Expr:          "no_stats",
+			QueryType:     "instant",
+			IncludeStats:  true,
+		})
+		require.NoError(t, err)
+		r := result.(*prometheusQueryStatsResult)
+		assert.Equal(t, model.SampleValue(2), r.Result.(*model.Scalar).Value)
+		assert.Nil(t, r.Stats)
+		assert.NotEmpty(t, r.Warning)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := queryPrometheus(ctx, QueryPrometheusParams{
+			DatasourceUID: "prometheus",
+			Expr:          "up{",
+			QueryType:     "instant",
+			IncludeStats:  true,
+		})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "parse error")
+	})
+}
+
+func TestGetPrometheusLabelCardinality(t *testing.T) {
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/datasources/proxy/uid/prometheus/api/v1/labels":
+			_, _ = w.Write([]byte(`{"status":"success","data":["instance","job","pod"]}`))
+		case "/api/datasources/proxy/uid/prometheus/api/v1/label/instance/values":
+			_, _ = w.Write([]byte(`{"status":"success","data":["a","b"]}`))
+		case "/api/datasources/proxy/uid/prometheus/api/v1/label/job/values":
+			_, _ = w.Write([]byte(`{"status":"success","data":["a"]}`))
+		case "/api/datasources/proxy/uid/prometheus/api/v1/label/pod/values":
+			_, _ = w.Write([]byte(`{"status":"success","data":["a","b","c"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	t.Run("all labels", func(t *testing.T) {
+		result, err := getPrometheusLabelCardinality(ctx, GetPrometheusLabelCardinalityParams{
+			DatasourceUID: "prometheus",
+			Limit:         2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []labelCardinality{{Label: "pod", Values: 3}, {Label: "instance", Values: 2}}, result)
+	})
+
+	t.Run("given labels", func(t *testing.T) {
+		result, err := getPrometheusLabelCardinality(ctx, GetPrometheusLabelCardinalityParams{
+			DatasourceUID: "prometheus",
+			LabelNames:    []string{"job", "instance"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []labelCardinality{{Label: "instance", Values: 2}, {Label: "job", Values: 1}}, result)
+	})
+
+	t.Run("unknown label", func(t *testing.T) {
+		_, err := getPrometheusLabelCardinality(ctx, GetPrometheusLabelCardinalityParams{
+			DatasourceUID: "prometheus",
+			LabelNames:    []string{"missing"},
+		})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestPromClientDirectURL(t *testing.T) {
+	useTestDatasourceCache(t)
+	var promAuth, promTenant string
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		promAuth = r.Header.Get("Authorization")
+		promTenant = r.Header.Get("X-Scope-OrgID")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":["job"]}`))
+	}))
+	t.Cleanup(prom.Close)
+	grafana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/datasources/uid/direct":
+			_ = json.NewEncoder(w).Encode(models.DataSource{UID: "direct", Type: "prometheus", URL: prom.URL})
+		case "/api/datasources/uid/basic":
+			_ = json.NewEncoder(w).Encode(models.DataSource{UID: "basic", Type: "prometheus", URL: prom.URL, BasicAuth: true})
+		default:
+			t.Errorf("unexpected request to Grafana: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(grafana.Close)
+
+	u, err := url.Parse(grafana.URL)
+	require.NoError(t, err)
+	cfg := client.DefaultTransportConfig().WithHost(u.Host).WithSchemes([]string{"http"})
+	ctx := mcpgrafana.WithGrafanaURL(context.Background(), grafana.URL)
+	ctx = mcpgrafana.WithGrafanaClient(ctx, client.NewHTTPClientWithConfig(strfmt.Default, cfg))
+	ctx = mcpgrafana.WithGrafanaAPIKey(ctx, "secret")
+	ctx = mcpgrafana.WithGrafanaTenantID(ctx, "tenant")
+
+	t.Run("direct", func(t *testing.T) {
+		ctx := mcpgrafana.WithPromDirectURL(ctx, []string{"direct"})
+		promClient, err := promClientFromContext(ctx, "direct")
+		require.NoError(t, err)
+		names, _, err := promClient.LabelNames(ctx, nil, time.Now().Add(-time.Hour), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"job"}, names)
+		assert.Empty(t, promAuth, "Grafana's API key must not be sent to the datasource")
+		assert.Equal(t, "tenant", promTenant)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		ctx := mcpgrafana.WithPromDirectURL(ctx, []string{mcpgrafana.PromDirectURLAll})
+		promClient, err := promClientFromContext(ctx, "direct")
+		require.NoError(t, err)
+		_, _, err = promClient.LabelNames(ctx, nil, time.Now().Add(-time.Hour), time.Now())
+		require.NoError(t, err)
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		ctx := mcpgrafana.WithPromDirectURL(ctx, []string{"basic"})
+		_, err := promClientFromContext(ctx, "basic")
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "uses basic auth")
+	})
+}
+
+func TestListPrometheusMetricNamesTimeRange(t *testing.T) {
+	var start, end string
+	ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "/api/datasources/proxy/uid/prometheus/api/v1/label/__name__/values", r.URL.Path)
+		start, end = r.Form.Get("start"), r.Form.Get("end")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":["up","go_goroutines"]}`))
+	})
+	ctx = mcpgrafana.WithDefaultTimeRange(ctx, 6*time.Hour)
+	parse := func(t *testing.T, s string) time.Time {
+		f, err := strconv.ParseFloat(s, 64)
+		require.NoError(t, err)
+		return time.Unix(int64(f), 0)
+	}
+
+	t.Run("default time range", func(t *testing.T) {
+		names, err := listPrometheusMetricNames(ctx, ListPrometheusMetricNamesParams{DatasourceUID: "prometheus"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"up", "go_goroutines"}, names)
+		assert.WithinDuration(t, time.Now(), parse(t, end), time.Minute)
+		assert.Equal(t, 6*time.Hour, parse(t, end).Sub(parse(t, start)))
+	})
+
+	t.Run("explicit time range", func(t *testing.T) {
+		_, err := listPrometheusMetricNames(ctx, ListPrometheusMetricNamesParams{
+			DatasourceUID: "prometheus",
+			StartRFC3339:  "2025-01-01T00:00:00Z",
+			EndRFC3339:    "2025-01-02T00:00:00Z",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), parse(t, start).Unix())
+		assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC).Unix(), parse(t, end).Unix())
+	})
+}
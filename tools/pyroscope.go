@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+const (
+	// DefaultPyroscopeTopFunctions is the default number of functions
+	// returned in a profile summary.
+	DefaultPyroscopeTopFunctions = 10
+
+	// DefaultPyroscopeMaxNodes is the default maximum number of nodes in a
+	// returned flame graph.
+	DefaultPyroscopeMaxNodes = 100
+
+	// MaxPyroscopeMaxNodes is the largest flame graph which can be requested.
+	MaxPyroscopeMaxNodes = 1000
+
+	// pyroscopeSummaryMaxNodes is the number of nodes requested from
+	// Pyroscope to compute a summary. Pyroscope merges the smallest nodes
+	// into 'other', so this only needs to be large enough for the top
+	// functions to be accurate.
+	pyroscopeSummaryMaxNodes = 4096
+)
+
+type QueryPyroscopeProfileParams struct {
+	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the Pyroscope datasource to query"`
+	ProfileType   string `json:"profileType" jsonschema:"required,description=The profile type to query\\, e.g. 'process_cpu:cpu:nanoseconds:cpu:nanoseconds' or 'memory:alloc_space:bytes:space:bytes'"`
+	Matchers      string `json:"matchers,omitempty" jsonschema:"description=Optionally\\, a label selector to choose the app to profile\\, e.g. '{service_name=\"api\"}'. Defaults to all profiles of the type"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+	Format        string `json:"format,omitempty" jsonschema:"description=Optionally\\, whether to return a summary of the functions using the most resources ('summary'\\, the default) or the flame graph as a tree of calls ('flamegraph'),enum=summary,enum=flamegraph"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the number of functions to return in a summary (default: 10) or the maximum number of nodes in a flame graph (default: 100\\, max: 1000)"`
+}
+
+func (p QueryPyroscopeProfileParams) validate() error {
+	if p.ProfileType == "" {
+		return fmt.Errorf("profileType is required")
+	}
+	if p.Matchers != "" && (!strings.HasPrefix(p.Matchers, "{") || !strings.HasSuffix(p.Matchers, "}")) {
+		return fmt.Errorf("invalid matchers %q: must be a label selector such as '{service_name=\"api\"}'", p.Matchers)
+	}
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	return nil
+}
+
+// pyroscopeRenderResponse is the response from Pyroscope's /pyroscope/render
+// endpoint.
+type pyroscopeRenderResponse struct {
+	Flamebearer struct {
+		Names []string `json:"names"`
+		// Levels holds the nodes at each depth of the flame graph, each
+		// as four numbers: the offset from the end of the previous node,
+		// the total value, the self value and the index of its name.
+		Levels   [][]int64 `json:"levels"`
+		NumTicks int64     `json:"numTicks"`
+	} `json:"flamebearer"`
+	Metadata struct {
+		Units string `json:"units"`
+	} `json:"metadata"`
+}
+
+type flamegraphNode struct {
+	Name     string            `json:"name"`
+	Total    int64             `json:"total"`
+	Self     int64             `json:"self"`
+	Children []*flamegraphNode `json:"children,omitempty"`
+
+	offset int64
+}
+
+// decodeFlamebearer returns the root of the flame graph encoded in resp.
+func decodeFlamebearer(resp *pyroscopeRenderResponse) (*flamegraphNode, error) {
+	fb := resp.Flamebearer
+	var root *flamegraphNode
+	var parents []*flamegraphNode
+	for depth, level := range fb.Levels {
+		if len(level)%4 != 0 {
+			return nil, fmt.Errorf("invalid flame graph: level %d has %d values", depth, len(level))
+		}
+		nodes := make([]*flamegraphNode, 0, len(level)/4)
+		var offset int64
+		for i := 0; i < len(level); i += 4 {
+			nameIdx := level[i+3]
+			if nameIdx < 0 || nameIdx >= int64(len(fb.Names)) {
+				return nil, fmt.Errorf("invalid flame graph: name index %d out of range", nameIdx)
+			}
+			offset += level[i]
+			node := &flamegraphNode{
+				Name:   fb.Names[nameIdx],
+				Total:  level[i+1],
+				Self:   level[i+2],
+				offset: offset,
+			}
+			offset += node.Total
+			nodes = append(nodes, node)
+
+			if depth == 0 {
+				root = node
+				continue
+			}
+			// The parent is the last node on the level above which starts
+			// at or before this one.
+			j := sort.Search(len(parents), func(j int) bool { return parents[j].offset > node.offset }) - 1
+			if j < 0 {
+				return nil, fmt.Errorf("invalid flame graph: node %q at level %d has no parent", node.Name, depth)
+			}
+			parents[j].Children = append(parents[j].Children, node)
+		}
+		parents = nodes
+	}
+	if root == nil {
+		return &flamegraphNode{Name: "total"}, nil
+	}
+	return root, nil
+}
+
+type functionSummary struct {
+	Name string `json:"name"`
+	// Self is the value spent in the function itself, and Total the value
+	// spent in the function and the functions it calls.
+	Self         int64   `json:"self"`
+	Total        int64   `json:"total"`
+	SelfPercent  float64 `json:"selfPercent"`
+	TotalPercent float64 `json:"totalPercent"`
+}
+
+// topFunctions returns the n functions with the highest self value in the
+// flame graph rooted at root.
+func topFunctions(root *flamegraphNode, n int) []functionSummary {
+	byName := map[string]*functionSummary{}
+	var walk func(node *flamegraphNode, onStack map[string]bool)
+	walk = func(node *flamegraphNode, onStack map[string]bool) {
+		fn, ok := byName[node.Name]
+		if !ok {
+			fn = &functionSummary{Name: node.Name}
+			byName[node.Name] = fn
+		}
+		fn.Self += node.Self
+		// Don't count recursive calls towards the total twice.
+		if !onStack[node.Name] {
+			fn.Total += node.Total
+			onStack[node.Name] = true
+			defer delete(onStack, node.Name)
+		}
+		for _, child := range node.Children {
+			walk(child, onStack)
+		}
+	}
+	for _, child := range root.Children {
+		walk(child, map[string]bool{})
+	}
+
+	functions := make([]functionSummary, 0, len(byName))
+	for _, fn := range byName {
+		if root.Total > 0 {
+			fn.SelfPercent = roundPercent(fn.Self, root.Total)
+			fn.TotalPercent = roundPercent(fn.Total, root.Total)
+		}
+		functions = append(functions, *fn)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Self != functions[j].Self {
+			return functions[i].Self > functions[j].Self
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	if len(functions) > n {
+		functions = functions[:n]
+	}
+	return functions
+}
+
+func roundPercent(value, total int64) float64 {
+	return float64(value*10000/total) / 100
+}
+
+type queryPyroscopeProfileResult struct {
+	Units        string            `json:"units"`
+	Total        int64             `json:"total"`
+	TopFunctions []functionSummary `json:"topFunctions,omitempty"`
+	Flamegraph   *flamegraphNode   `json:"flamegraph,omitempty"`
+}
+
+func queryPyroscopeProfile(ctx context.Context, args QueryPyroscopeProfileParams) (*queryPyroscopeProfileResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("query Pyroscope profile: %w", err))
+	}
+	start, end, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+
+	flamegraph := args.Format == "flamegraph"
+	maxNodes := pyroscopeSummaryMaxNodes
+	if flamegraph {
+		maxNodes = DefaultPyroscopeMaxNodes
+		if args.Limit > 0 {
+			maxNodes = min(args.Limit, MaxPyroscopeMaxNodes)
+		}
+	}
+	matchers := args.Matchers
+	if matchers == "" {
+		matchers = "{}"
+	}
+
+	params := url.Values{}
+	params.Set("query", args.ProfileType+matchers)
+	params.Set("from", strconv.FormatInt(start.Unix(), 10))
+	params.Set("until", strconv.FormatInt(end.Unix(), 10))
+	params.Set("format", "json")
+	params.Set("max-nodes", strconv.Itoa(maxNodes))
+	var resp pyroscopeRenderResponse
+	if err := newProxyClient(ctx, args.DatasourceUID).getJSON(ctx, "/pyroscope/render", params, &resp); err != nil {
+		return nil, fmt.Errorf("query Pyroscope profile: %w", err)
+	}
+
+	root, err := decodeFlamebearer(&resp)
+	if err != nil {
+		return nil, fmt.Errorf("query Pyroscope profile: %w", err)
+	}
+	result := &queryPyroscopeProfileResult{
+		Units: resp.Metadata.Units,
+		Total: root.Total,
+	}
+	if flamegraph {
+		result.Flamegraph = root
+		return result, nil
+	}
+	limit := DefaultPyroscopeTopFunctions
+	if args.Limit > 0 {
+		limit = args.Limit
+	}
+	result.TopFunctions = topFunctions(root, limit)
+	return result, nil
+}
+
+var QueryPyroscopeProfile = mcpgrafana.MustTool(
+	"query_pyroscope_profile",
+	"Query a profile, such as CPU or memory allocations, from a Pyroscope datasource for an app and time range. By default, returns the functions using the most resources, with their self and total values and percentages of the whole profile. Set format to 'flamegraph' to get the call tree instead, with the smallest nodes merged together",
+	queryPyroscopeProfile,
+)
+
+// AddPyroscopeTools registers all Pyroscope tools with the MCP server
+func AddPyroscopeTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "pyroscope",
+		QueryPyroscopeProfile,
+	)
+}
@@ -0,0 +1,130 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// total -> main -> work -> work
+//
+//	-> other -> leaf
+const pyroscopeRenderJSON = `{
+  "flamebearer": {
+    "names": ["total", "main", "work", "other", "leaf"],
+    "levels": [
+      [0, 100, 0, 0],
+      [0, 100, 10, 1],
+      [0, 60, 20, 2, 0, 30, 25, 3],
+      [0, 40, 40, 2, 30, 5, 5, 4]
+    ],
+    "numTicks": 100,
+    "maxSelf": 40
+  },
+  "metadata": {"format": "single", "units": "samples"}
+}`
+
+func newTestPyroscopeServer(t *testing.T) context.Context {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/datasources/proxy/uid/pyroscope/pyroscope/render" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, `process_cpu:cpu:nanoseconds:cpu:nanoseconds{service_name="api"}`, r.URL.Query().Get("query"))
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.NotEmpty(t, r.URL.Query().Get("from"))
+		assert.NotEmpty(t, r.URL.Query().Get("until"))
+		_, _ = w.Write([]byte(pyroscopeRenderJSON))
+	}))
+	t.Cleanup(srv.Close)
+	return mcpgrafana.WithGrafanaURL(context.Background(), srv.URL)
+}
+
+func TestQueryPyroscopeProfile(t *testing.T) {
+	ctx := newTestPyroscopeServer(t)
+	params := QueryPyroscopeProfileParams{
+		DatasourceUID: "pyroscope",
+		ProfileType:   "process_cpu:cpu:nanoseconds:cpu:nanoseconds",
+		Matchers:      `{service_name="api"}`,
+	}
+
+	t.Run("summary", func(t *testing.T) {
+		result, err := queryPyroscopeProfile(ctx, params)
+		require.NoError(t, err)
+		assert.Equal(t, "samples", result.Units)
+		assert.Equal(t, int64(100), result.Total)
+		assert.Nil(t, result.Flamegraph)
+		assert.Equal(t, []functionSummary{
+			// Recursive calls to work aren't counted twice in its total.
+			{Name: "work", Self: 60, Total: 60, SelfPercent: 60, TotalPercent: 60},
+			{Name: "other", Self: 25, Total: 30, SelfPercent: 25, TotalPercent: 30},
+			{Name: "main", Self: 10, Total: 100, SelfPercent: 10, TotalPercent: 100},
+			{Name: "leaf", Self: 5, Total: 5, SelfPercent: 5, TotalPercent: 5},
+		}, result.TopFunctions)
+	})
+
+	t.Run("summary with limit", func(t *testing.T) {
+		p := params
+		p.Limit = 2
+		result, err := queryPyroscopeProfile(ctx, p)
+		require.NoError(t, err)
+		require.Len(t, result.TopFunctions, 2)
+		assert.Equal(t, "work", result.TopFunctions[0].Name)
+		assert.Equal(t, "other", result.TopFunctions[1].Name)
+	})
+
+	t.Run("flamegraph", func(t *testing.T) {
+		p := params
+		p.Format = "flamegraph"
+		result, err := queryPyroscopeProfile(ctx, p)
+		require.NoError(t, err)
+		assert.Nil(t, result.TopFunctions)
+		root := result.Flamegraph
+		require.NotNil(t, root)
+		assert.Equal(t, "total", root.Name)
+		require.Len(t, root.Children, 1)
+		main := root.Children[0]
+		assert.Equal(t, "main", main.Name)
+		require.Len(t, main.Children, 2)
+		assert.Equal(t, "work", main.Children[0].Name)
+		require.Len(t, main.Children[0].Children, 1)
+		assert.Equal(t, "work", main.Children[0].Children[0].Name)
+		assert.Equal(t, "other", main.Children[1].Name)
+		require.Len(t, main.Children[1].Children, 1)
+		assert.Equal(t, "leaf", main.Children[1].Children[0].Name)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		for _, p := range []QueryPyroscopeProfileParams{
+			{DatasourceUID: "pyroscope"},
+			{DatasourceUID: "pyroscope", ProfileType: "process_cpu:cpu:nanoseconds:cpu:nanoseconds", Matchers: `service_name="api"`},
+			{DatasourceUID: "pyroscope", ProfileType: "process_cpu:cpu:nanoseconds:cpu:nanoseconds", StartRFC3339: "yesterday"},
+			{DatasourceUID: "missing", ProfileType: "process_cpu:cpu:nanoseconds:cpu:nanoseconds"},
+		} {
+			_, err := queryPyroscopeProfile(ctx, p)
+			var toolErr *mcpgrafana.ToolError
+			assert.True(t, errors.As(err, &toolErr), "%+v", p)
+		}
+	})
+}
+
+func TestDecodeFlamebearerInvalid(t *testing.T) {
+	var resp pyroscopeRenderResponse
+	resp.Flamebearer.Names = []string{"total"}
+	resp.Flamebearer.Levels = [][]int64{{0, 1, 1}}
+	_, err := decodeFlamebearer(&resp)
+	assert.Error(t, err)
+
+	resp.Flamebearer.Levels = [][]int64{{0, 1, 1, 5}}
+	_, err = decodeFlamebearer(&resp)
+	assert.Error(t, err)
+}
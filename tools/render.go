@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+const (
+	// DefaultRenderWidth and DefaultRenderHeight are the default size in
+	// pixels of rendered images.
+	DefaultRenderWidth  = 1000
+	DefaultRenderHeight = 500
+
+	// maxRenderBytes is the largest image which is returned.
+	maxRenderBytes = 10 * 1024 * 1024
+)
+
+type RenderDashboardPanelParams struct {
+	DashboardUID string            `json:"dashboardUid" jsonschema:"required,description=The UID of the dashboard"`
+	PanelID      int               `json:"panelId,omitempty" jsonschema:"description=Optionally\\, the ID of the panel to render. Use get_dashboard_panel_queries to find it. Defaults to rendering the whole dashboard"`
+	StartRFC3339 string            `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise"`
+	EndRFC3339   string            `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end of the time range in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now"`
+	Width        int               `json:"width,omitempty" jsonschema:"description=Optionally\\, the width of the image in pixels. Defaults to 1000"`
+	Height       int               `json:"height,omitempty" jsonschema:"description=Optionally\\, the height of the image in pixels. Defaults to 500"`
+	Theme        string            `json:"theme,omitempty" jsonschema:"description=Optionally\\, the theme to render with. Defaults to Grafana's default theme,enum=light,enum=dark"`
+	Variables    map[string]string `json:"variables,omitempty" jsonschema:"description=Optionally\\, values for the dashboard's template variables\\, e.g. {\"namespace\": \"prod\"}"`
+}
+
+func (p RenderDashboardPanelParams) validate() error {
+	if p.PanelID < 0 {
+		return fmt.Errorf("invalid panelId: %d, must be greater than 0", p.PanelID)
+	}
+	if p.Width < 0 || p.Height < 0 {
+		return fmt.Errorf("invalid size %dx%d, must be greater than 0", p.Width, p.Height)
+	}
+	return nil
+}
+
+// renderURL returns the URL of Grafana's image renderer for a panel, or for
+// the whole dashboard if no panel is given. The slug in the path is ignored
+// by Grafana, so a placeholder is used.
+func renderURL(grafanaURL string, args RenderDashboardPanelParams, from, to int64, orgID int64) string {
+	path := "/render/d/" + url.PathEscape(args.DashboardUID) + "/_"
+	params := url.Values{}
+	if args.PanelID != 0 {
+		path = "/render/d-solo/" + url.PathEscape(args.DashboardUID) + "/_"
+		params.Set("panelId", strconv.Itoa(args.PanelID))
+	}
+	params.Set("from", strconv.FormatInt(from, 10))
+	params.Set("to", strconv.FormatInt(to, 10))
+	width, height := DefaultRenderWidth, DefaultRenderHeight
+	if args.Width > 0 {
+		width = args.Width
+	}
+	if args.Height > 0 {
+		height = args.Height
+	}
+	params.Set("width", strconv.Itoa(width))
+	params.Set("height", strconv.Itoa(height))
+	if args.Theme != "" {
+		params.Set("theme", args.Theme)
+	}
+	if orgID != 0 {
+		params.Set("orgId", strconv.FormatInt(orgID, 10))
+	}
+	for name, value := range args.Variables {
+		params.Set("var-"+name, value)
+	}
+	return strings.TrimRight(grafanaURL, "/") + path + "?" + params.Encode()
+}
+
+func renderDashboardPanel(ctx context.Context, args RenderDashboardPanelParams) (*mcp.ImageContent, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("render dashboard panel: %w", err))
+	}
+	start, end, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+
+	u := renderURL(mcpgrafana.GrafanaURLFromContext(ctx), args, start.UnixMilli(), end.UnixMilli(), mcpgrafana.GrafanaOrgIDFromContext(ctx))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("render dashboard panel: creating request: %w", err)
+	}
+	client := &http.Client{
+		Transport: newAuthRoundTripper(ctx, newTransport(ctx)),
+		Timeout:   clientTimeout(ctx),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("render dashboard panel: executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRenderBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("render dashboard panel: reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Grafana responds with a 500 if no image renderer is installed.
+		if strings.Contains(strings.ToLower(string(body)), "no image renderer") {
+			return nil, mcpgrafana.NewToolError(fmt.Errorf("render dashboard panel: Grafana has no image renderer; install the grafana-image-renderer plugin or remote rendering service to render images"))
+		}
+		return nil, proxyStatusError(resp.StatusCode, fmt.Errorf("render dashboard panel: Grafana returned status code %d: %s", resp.StatusCode, string(body)))
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/png") {
+		return nil, fmt.Errorf("render dashboard panel: expected a PNG image, got content type %q", contentType)
+	}
+	if len(body) > maxRenderBytes {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("render dashboard panel: the image is larger than %d bytes; use a smaller width and height", maxRenderBytes))
+	}
+	image := mcp.NewImageContent(base64.StdEncoding.EncodeToString(body), "image/png")
+	return &image, nil
+}
+
+var RenderDashboardPanel = mcpgrafana.MustTool(
+	"render_dashboard_panel",
+	"Render a dashboard panel, or a whole dashboard, to a PNG image over a time range, so you can see the graph as a user would. Requires the Grafana image renderer to be installed. Use get_dashboard_panel_data instead to get the underlying numbers",
+	renderDashboardPanel,
+)
@@ -0,0 +1,85 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+func TestRenderURL(t *testing.T) {
+	t.Run("panel", func(t *testing.T) {
+		u, err := url.Parse(renderURL("http://grafana/", RenderDashboardPanelParams{
+			DashboardUID: "abc",
+			PanelID:      2,
+			Theme:        "dark",
+			Variables:    map[string]string{"env": "prod"},
+		}, 1000, 2000, 3))
+		require.NoError(t, err)
+		assert.Equal(t, "/render/d-solo/abc/_", u.Path)
+		assert.Equal(t, url.Values{
+			"panelId": {"2"},
+			"from":    {"1000"},
+			"to":      {"2000"},
+			"width":   {"1000"},
+			"height":  {"500"},
+			"theme":   {"dark"},
+			"orgId":   {"3"},
+			"var-env": {"prod"},
+		}, u.Query())
+	})
+
+	t.Run("dashboard", func(t *testing.T) {
+		u, err := url.Parse(renderURL("http://grafana", RenderDashboardPanelParams{DashboardUID: "abc", Width: 200, Height: 100}, 1000, 2000, 0))
+		require.NoError(t, err)
+		assert.Equal(t, "/render/d/abc/_", u.Path)
+		assert.Equal(t, "200", u.Query().Get("width"))
+		assert.Equal(t, "100", u.Query().Get("height"))
+		assert.False(t, u.Query().Has("panelId"))
+	})
+}
+
+func TestRenderDashboardPanel(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+
+	t.Run("image", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/render/d-solo/abc/_", r.URL.Path)
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(png)
+		})
+		image, err := renderDashboardPanel(ctx, RenderDashboardPanelParams{DashboardUID: "abc", PanelID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "image", image.Type)
+		assert.Equal(t, "image/png", image.MIMEType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(png), image.Data)
+	})
+
+	t.Run("no image renderer", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"Rendering failed: No image renderer available/installed"}`, http.StatusInternalServerError)
+		})
+		_, err := renderDashboardPanel(ctx, RenderDashboardPanelParams{DashboardUID: "abc", PanelID: 1})
+		var toolErr *mcpgrafana.ToolError
+		require.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "no image renderer")
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		ctx := newTestGrafanaContext(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html>login</html>"))
+		})
+		_, err := renderDashboardPanel(ctx, RenderDashboardPanelParams{DashboardUID: "abc"})
+		assert.ErrorContains(t, err, "expected a PNG image")
+	})
+}
@@ -3,40 +3,170 @@ package tools
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
 	"github.com/grafana/grafana-openapi-client-go/client/search"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	mcpgrafana "github.com/grafana/mcp-grafana"
 )
 
-var dashboardTypeStr = "dash-db"
+var (
+	dashboardTypeStr = "dash-db"
+	folderTypeStr    = "dash-folder"
+)
 
 type SearchDashboardsParams struct {
-	Query string `json:"query" jsonschema:"description=The query to search for"`
+	Query      string   `json:"query" jsonschema:"description=The query to search for"`
+	FolderUIDs []string `json:"folderUids,omitempty" jsonschema:"description=Optionally\\, only return results in the folders with these UIDs"`
+	Tags       []string `json:"tags,omitempty" jsonschema:"description=Optionally\\, only return dashboards with all of these tags"`
+	Type       string   `json:"type,omitempty" jsonschema:"description=Optionally\\, the type of results to return: 'dash-db' for dashboards or 'dash-folder' for folders. Defaults to dashboards when a query is given"`
+	Limit      int      `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of results to return"`
+	Page       int      `json:"page,omitempty" jsonschema:"description=Optionally\\, the page number to return (1-based). The limit is used as the page size"`
+	Raw        bool     `json:"raw,omitempty" jsonschema:"description=Optionally\\, return the raw search hits from Grafana instead of a summary of each"`
+}
+
+func (p SearchDashboardsParams) validate() error {
+	if p.Type != "" && p.Type != dashboardTypeStr && p.Type != folderTypeStr {
+		return fmt.Errorf("invalid type: %s, must be '%s' or '%s'", p.Type, dashboardTypeStr, folderTypeStr)
+	}
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	if p.Page < 0 {
+		return fmt.Errorf("invalid page: %d, must be greater than 0", p.Page)
+	}
+	return nil
 }
 
 func searchDashboards(ctx context.Context, args SearchDashboardsParams) (models.HitList, error) {
-	c := mcpgrafana.GrafanaClientFromContext(ctx)
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("search dashboards: %w", err)
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("search dashboards: %w", err)
+	}
 	params := search.NewSearchParamsWithContext(ctx)
 	if args.Query != "" {
 		params.SetQuery(&args.Query)
 		params.SetType(&dashboardTypeStr)
 	}
+	if args.Type != "" {
+		params.SetType(&args.Type)
+	}
+	if len(args.FolderUIDs) > 0 {
+		params.SetFolderUIDs(args.FolderUIDs)
+	}
+	if len(args.Tags) > 0 {
+		params.SetTag(args.Tags)
+	}
+	if args.Limit > 0 {
+		limit := int64(args.Limit)
+		params.SetLimit(&limit)
+	}
+	if args.Page > 0 {
+		page := int64(args.Page)
+		params.SetPage(&page)
+	}
 	search, err := c.Search.Search(params)
 	if err != nil {
-		return nil, fmt.Errorf("search dashboards for %+v: %w", c, err)
+		return nil, fmt.Errorf("search dashboards for %+v: %w", c, grafanaAPIError(err))
 	}
 	return search.Payload, nil
 }
 
+type searchHitSummary struct {
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	Type        string   `json:"type"`
+	FolderTitle string   `json:"folderTitle,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	URL         string   `json:"url"`
+}
+
+// searchDashboardsSummary searches for dashboards and summarizes the hits,
+// unless raw output was requested.
+func searchDashboardsSummary(ctx context.Context, args SearchDashboardsParams) (any, error) {
+	hits, err := searchDashboards(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if args.Raw {
+		return hits, nil
+	}
+	return summarizeSearchHits(mcpgrafana.GrafanaURLFromContext(ctx), hits), nil
+}
+
+func summarizeSearchHits(grafanaURL string, hits models.HitList) []searchHitSummary {
+	// Hit URLs are relative to the Grafana host and already include any
+	// subpath Grafana is served from.
+	baseURL := ""
+	if u, err := url.Parse(grafanaURL); err == nil {
+		baseURL = (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+	}
+
+	result := make([]searchHitSummary, 0, len(hits))
+	for _, hit := range hits {
+		if hit == nil {
+			continue
+		}
+		result = append(result, searchHitSummary{
+			UID:         hit.UID,
+			Title:       hit.Title,
+			Type:        string(hit.Type),
+			FolderTitle: hit.FolderTitle,
+			Tags:        hit.Tags,
+			URL:         baseURL + hit.URL,
+		})
+	}
+	return result
+}
+
 var SearchDashboards = mcpgrafana.MustTool(
 	"search_dashboards",
-	"Search for dashboards",
-	searchDashboards,
+	"Search for dashboards and folders, optionally filtering by folder, tags and type. Returns the UID, title, folder, tags and URL of each result",
+	searchDashboardsSummary,
+)
+
+type ListDashboardTagsParams struct{}
+
+type dashboardTag struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+func listDashboardTags(ctx context.Context, args ListDashboardTagsParams) ([]dashboardTag, error) {
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list dashboard tags: %w", err)
+	}
+	response, err := c.Dashboards.GetDashboardTagsWithParams(dashboards.NewGetDashboardTagsParamsWithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list dashboard tags: %w", grafanaAPIError(err))
+	}
+	tags := make([]dashboardTag, 0, len(response.Payload))
+	for _, t := range response.Payload {
+		if t == nil {
+			continue
+		}
+		tags = append(tags, dashboardTag{Tag: t.Term, Count: t.Count})
+	}
+	return tags, nil
+}
+
+var ListDashboardTags = mcpgrafana.MustTool(
+	"list_dashboard_tags",
+	"List the tags used on dashboards, with the number of dashboards which have each. Use this to find valid tags to filter search_dashboards by",
+	listDashboardTags,
 )
 
-func AddSearchTools(mcp *server.MCPServer) {
-	SearchDashboards.Register(mcp)
+func AddSearchTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "search",
+		SearchDashboards,
+		ListDashboardTags,
+	)
 }
@@ -23,4 +23,90 @@ func TestSearchTools(t *testing.T) {
 		assert.Len(t, result, 1)
 		assert.Equal(t, models.HitType("dash-db"), result[0].Type)
 	})
+
+	t.Run("search dashboards by tag", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := searchDashboards(ctx, SearchDashboardsParams{
+			Tags: []string{"demo"},
+		})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "Demo", result[0].Title)
+
+		result, err = searchDashboards(ctx, SearchDashboardsParams{
+			Tags: []string{"non-existent-tag"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("search folders", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := searchDashboards(ctx, SearchDashboardsParams{
+			Type: "dash-folder",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, result)
+		for _, hit := range result {
+			assert.Equal(t, models.HitType("dash-folder"), hit.Type)
+		}
+	})
+
+	t.Run("search dashboards with limit", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := searchDashboards(ctx, SearchDashboardsParams{
+			Limit: 1,
+		})
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+
+	t.Run("search dashboards with invalid type", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := searchDashboards(ctx, SearchDashboardsParams{
+			Type: "dash-panel",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("search dashboards summary", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := searchDashboardsSummary(ctx, SearchDashboardsParams{
+			Query: "Demo",
+		})
+		require.NoError(t, err)
+		summaries, ok := result.([]searchHitSummary)
+		require.True(t, ok, "result should be a list of summaries")
+		require.Len(t, summaries, 1)
+		assert.Equal(t, "Demo", summaries[0].Title)
+		assert.Equal(t, "dash-db", summaries[0].Type)
+		assert.Equal(t, []string{"demo"}, summaries[0].Tags)
+		assert.Equal(t, "http://localhost:3000/d/"+summaries[0].UID+"/demo", summaries[0].URL)
+	})
+
+	t.Run("search dashboards raw", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := searchDashboardsSummary(ctx, SearchDashboardsParams{
+			Query: "Demo",
+			Raw:   true,
+		})
+		require.NoError(t, err)
+		hits, ok := result.(models.HitList)
+		require.True(t, ok, "result should be the raw hit list")
+		assert.Len(t, hits, 1)
+	})
+
+	t.Run("list dashboard tags", func(t *testing.T) {
+		ctx := newTestContext()
+		result, err := listDashboardTags(ctx, ListDashboardTagsParams{})
+		require.NoError(t, err)
+		var demo *dashboardTag
+		for i := range result {
+			if result[i].Tag == "demo" {
+				demo = &result[i]
+			}
+		}
+		require.NotNil(t, demo, "expected the demo tag in %v", result)
+		assert.Equal(t, int64(1), demo.Count)
+	})
 }
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-openapi-client-go/client/snapshots"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// snapshotSecretsNote is returned with each new snapshot, so that clients
+// don't pass on the delete key and URL along with the snapshot URL.
+const snapshotSecretsNote = "secretDeleteKey and secretDeleteUrl let anyone delete the snapshot without logging in. Keep them secret; share only url"
+
+type CreateDashboardSnapshotParams struct {
+	DashboardUID   string         `json:"dashboardUid,omitempty" jsonschema:"description=The UID of the dashboard to snapshot. Exactly one of dashboardUid or dashboard must be set"`
+	Dashboard      map[string]any `json:"dashboard,omitempty" jsonschema:"description=The dashboard JSON to snapshot. Exactly one of dashboardUid or dashboard must be set"`
+	Name           string         `json:"name,omitempty" jsonschema:"description=Optionally\\, the name of the snapshot. Defaults to the dashboard's title"`
+	ExpiresSeconds int64          `json:"expiresSeconds,omitempty" jsonschema:"description=Optionally\\, the number of seconds after which the snapshot is deleted. Defaults to never"`
+}
+
+func (p CreateDashboardSnapshotParams) validate() error {
+	if (p.DashboardUID == "") == (p.Dashboard == nil) {
+		return fmt.Errorf("exactly one of dashboardUid or dashboard must be set")
+	}
+	if p.ExpiresSeconds < 0 {
+		return fmt.Errorf("invalid expiresSeconds: %d, must be greater than 0", p.ExpiresSeconds)
+	}
+	return nil
+}
+
+type createDashboardSnapshotResult struct {
+	Key             string `json:"key"`
+	URL             string `json:"url"`
+	SecretDeleteKey string `json:"secretDeleteKey"`
+	SecretDeleteURL string `json:"secretDeleteUrl"`
+	Note            string `json:"note"`
+}
+
+func createDashboardSnapshot(ctx context.Context, args CreateDashboardSnapshotParams) (*createDashboardSnapshotResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("create dashboard snapshot: %w", err))
+	}
+
+	dashboard := args.Dashboard
+	if args.DashboardUID != "" {
+		d, err := getDashboardByUID(ctx, GetDashboardByUIDParams{UID: args.DashboardUID})
+		if err != nil {
+			return nil, fmt.Errorf("create dashboard snapshot: %w", err)
+		}
+		var ok bool
+		if dashboard, ok = d.Dashboard.(map[string]any); !ok {
+			return nil, fmt.Errorf("create dashboard snapshot: dashboard %s is not a JSON object", args.DashboardUID)
+		}
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create dashboard snapshot: %w", err)
+	}
+	response, err := c.Snapshots.CreateDashboardSnapshot(&models.CreateDashboardSnapshotCommand{
+		Dashboard: dashboard,
+		Name:      args.Name,
+		Expires:   args.ExpiresSeconds,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create dashboard snapshot: %w", err)
+	}
+	return &createDashboardSnapshotResult{
+		Key:             response.Payload.Key,
+		URL:             response.Payload.URL,
+		SecretDeleteKey: response.Payload.DeleteKey,
+		SecretDeleteURL: response.Payload.DeleteURL,
+		Note:            snapshotSecretsNote,
+	}, nil
+}
+
+var CreateDashboardSnapshot = mcpgrafana.MustTool(
+	"create_dashboard_snapshot",
+	"Create a snapshot of a dashboard, given its UID or JSON, to share a point-in-time view of it, optionally expiring after a number of seconds. The snapshot is public to anyone with its URL and contains the dashboard JSON as given: panels only show data that is embedded in it. Returns the snapshot's key and URL, and a secret delete key and URL which should not be shared",
+	createDashboardSnapshot,
+).AsMutating()
+
+type DeleteDashboardSnapshotParams struct {
+	Key string `json:"key" jsonschema:"required,description=The key of the snapshot to delete"`
+}
+
+func deleteDashboardSnapshot(ctx context.Context, args DeleteDashboardSnapshotParams) (string, error) {
+	if args.Key == "" {
+		return "", mcpgrafana.NewToolError(fmt.Errorf("delete dashboard snapshot: key is required"))
+	}
+
+	c, err := mcpgrafana.RequireGrafanaClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("delete dashboard snapshot: %w", err)
+	}
+	response, err := c.Snapshots.DeleteDashboardSnapshot(args.Key)
+	if err != nil {
+		var notFound *snapshots.DeleteDashboardSnapshotNotFound
+		if errors.As(err, &notFound) {
+			return "", mcpgrafana.NewToolError(fmt.Errorf("delete dashboard snapshot: snapshot with key %s not found", args.Key))
+		}
+		return "", fmt.Errorf("delete dashboard snapshot %s: %w", args.Key, err)
+	}
+	if response.Payload == nil || response.Payload.Message == "" {
+		return fmt.Sprintf("Snapshot %s deleted", args.Key), nil
+	}
+	return response.Payload.Message, nil
+}
+
+var DeleteDashboardSnapshot = mcpgrafana.MustTool(
+	"delete_dashboard_snapshot",
+	"Delete a dashboard snapshot by its key",
+	deleteDashboardSnapshot,
+).AsMutating()
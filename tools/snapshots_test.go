@@ -0,0 +1,57 @@
+// Requires a Grafana instance running on localhost:3000,
+// with the Demo dashboard provisioned.
+// Run with `go test -tags integration`.
+//go:build integration
+
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardSnapshotTools(t *testing.T) {
+	t.Run("create and delete a snapshot", func(t *testing.T) {
+		ctx := newTestContext()
+		searchResults, err := searchDashboards(ctx, SearchDashboardsParams{Query: "Demo"})
+		require.NoError(t, err)
+		require.Len(t, searchResults, 1)
+
+		created, err := createDashboardSnapshot(ctx, CreateDashboardSnapshotParams{
+			DashboardUID:   searchResults[0].UID,
+			Name:           "mcp-test-snapshot",
+			ExpiresSeconds: 3600,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.Key)
+		assert.Contains(t, created.URL, created.Key)
+		assert.NotEmpty(t, created.SecretDeleteKey)
+		assert.NotEmpty(t, created.Note)
+
+		_, err = deleteDashboardSnapshot(ctx, DeleteDashboardSnapshotParams{Key: created.Key})
+		require.NoError(t, err)
+	})
+
+	t.Run("create a snapshot from dashboard JSON", func(t *testing.T) {
+		ctx := newTestContext()
+		created, err := createDashboardSnapshot(ctx, CreateDashboardSnapshotParams{
+			Dashboard: map[string]any{"title": "mcp-test-snapshot-json", "panels": []any{}},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_, _ = deleteDashboardSnapshot(ctx, DeleteDashboardSnapshotParams{Key: created.Key})
+		})
+		assert.NotEmpty(t, created.URL)
+	})
+
+	t.Run("create a snapshot without a dashboard", func(t *testing.T) {
+		ctx := newTestContext()
+		_, err := createDashboardSnapshot(ctx, CreateDashboardSnapshotParams{})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
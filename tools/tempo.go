@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+const (
+	// DefaultTempoSearchLimit is the default number of traces returned by a
+	// TraceQL search.
+	DefaultTempoSearchLimit = 20
+
+	// MaxTempoSearchLimit is the maximum number of traces which can be
+	// requested from a TraceQL search.
+	MaxTempoSearchLimit = 100
+
+	// DefaultTempoMaxSpans is the default number of spans returned for a
+	// single trace.
+	DefaultTempoMaxSpans = 100
+)
+
+var traceIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{1,32}$`)
+
+type QueryTempoTracesParams struct {
+	DatasourceUID string `json:"datasourceUid" jsonschema:"required,description=The UID of the Tempo datasource to query"`
+	Query         string `json:"query,omitempty" jsonschema:"description=A TraceQL query to search for traces\\, e.g. '{ resource.service.name = \"api\" && status = error }'. Exactly one of query or traceId must be set"`
+	TraceID       string `json:"traceId,omitempty" jsonschema:"description=The ID of a trace to fetch all of the spans of. Exactly one of query or traceId must be set"`
+	StartRFC3339  string `json:"startRfc3339,omitempty" jsonschema:"description=Optionally\\, the start of the time range to search in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to 1 hour before the end time unless the server is configured otherwise. Ignored for traceId"`
+	EndRFC3339    string `json:"endRfc3339,omitempty" jsonschema:"description=Optionally\\, the end of the time range to search in RFC3339 format or relative to now\\, e.g. 'now-1h'. Defaults to now. Ignored for traceId"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"description=Optionally\\, the maximum number of traces to return for a query (default: 20\\, max: 100) or spans to return for a trace ID (default: 100)"`
+}
+
+func (p QueryTempoTracesParams) validate() error {
+	if (p.Query == "") == (p.TraceID == "") {
+		return fmt.Errorf("exactly one of query or traceId must be set")
+	}
+	if p.TraceID != "" && !traceIDPattern.MatchString(p.TraceID) {
+		return fmt.Errorf("invalid trace ID %q: must be up to 32 hexadecimal characters", p.TraceID)
+	}
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d, must be greater than 0", p.Limit)
+	}
+	return nil
+}
+
+// tempoSearchResponse is the response from Tempo's /api/search endpoint.
+type tempoSearchResponse struct {
+	Traces []struct {
+		TraceID           string `json:"traceID"`
+		RootServiceName   string `json:"rootServiceName"`
+		RootTraceName     string `json:"rootTraceName"`
+		StartTimeUnixNano string `json:"startTimeUnixNano"`
+		DurationMs        int64  `json:"durationMs"`
+		SpanSets          []struct {
+			Matched int `json:"matched"`
+		} `json:"spanSets"`
+	} `json:"traces"`
+}
+
+type traceSummary struct {
+	TraceID         string `json:"traceId"`
+	RootServiceName string `json:"rootServiceName"`
+	RootTraceName   string `json:"rootTraceName"`
+	StartTime       string `json:"startTime"`
+	DurationMs      int64  `json:"durationMs"`
+	// MatchedSpans is the number of spans in the trace which matched the
+	// query.
+	MatchedSpans int `json:"matchedSpans"`
+}
+
+// tempoAttribute is an OTLP attribute in Tempo's JSON trace format.
+type tempoAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string  `json:"stringValue"`
+		IntValue    *string  `json:"intValue"`
+		BoolValue   *bool    `json:"boolValue"`
+		DoubleValue *float64 `json:"doubleValue"`
+	} `json:"value"`
+}
+
+func (a tempoAttribute) value() any {
+	switch v := a.Value; {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		if n, err := strconv.ParseInt(*v.IntValue, 10, 64); err == nil {
+			return n
+		}
+		return *v.IntValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	}
+	return nil
+}
+
+type tempoSpan struct {
+	SpanID            string           `json:"spanId"`
+	ParentSpanID      string           `json:"parentSpanId"`
+	Name              string           `json:"name"`
+	Kind              string           `json:"kind"`
+	StartTimeUnixNano string           `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string           `json:"endTimeUnixNano"`
+	Attributes        []tempoAttribute `json:"attributes"`
+	Status            struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type tempoScopeSpans struct {
+	Spans []tempoSpan `json:"spans"`
+}
+
+// tempoTraceResponse is the response from Tempo's /api/traces/{id} endpoint,
+// in the OTLP JSON format. Older versions of Tempo use
+// instrumentationLibrarySpans rather than scopeSpans.
+type tempoTraceResponse struct {
+	Batches []struct {
+		Resource struct {
+			Attributes []tempoAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans                  []tempoScopeSpans `json:"scopeSpans"`
+		InstrumentationLibrarySpans []tempoScopeSpans `json:"instrumentationLibrarySpans"`
+	} `json:"batches"`
+}
+
+type spanSummary struct {
+	SpanID        string         `json:"spanId"`
+	ParentSpanID  string         `json:"parentSpanId,omitempty"`
+	ServiceName   string         `json:"serviceName"`
+	Name          string         `json:"name"`
+	Kind          string         `json:"kind,omitempty"`
+	StartTime     string         `json:"startTime"`
+	DurationMs    float64        `json:"durationMs"`
+	StatusCode    string         `json:"statusCode,omitempty"`
+	StatusMessage string         `json:"statusMessage,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+}
+
+type traceResult struct {
+	TraceID string        `json:"traceId"`
+	Spans   []spanSummary `json:"spans"`
+	// TotalSpans is the number of spans in the trace, which may be more
+	// than were returned.
+	TotalSpans int `json:"totalSpans"`
+}
+
+type queryTempoTracesResult struct {
+	Traces []traceSummary `json:"traces,omitempty"`
+	Trace  *traceResult   `json:"trace,omitempty"`
+}
+
+// formatUnixNano formats a timestamp in nanoseconds since the epoch, as a
+// decimal string, in RFC3339 format.
+func formatUnixNano(s string) string {
+	ns, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return s
+	}
+	return time.Unix(0, ns).UTC().Format(time.RFC3339Nano)
+}
+
+func queryTempoTraces(ctx context.Context, args QueryTempoTracesParams) (*queryTempoTracesResult, error) {
+	if err := args.validate(); err != nil {
+		return nil, mcpgrafana.NewToolError(fmt.Errorf("query Tempo traces: %w", err))
+	}
+	client := newProxyClient(ctx, args.DatasourceUID)
+	if args.TraceID != "" {
+		trace, err := getTempoTrace(ctx, client, args.TraceID, args.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return &queryTempoTracesResult{Trace: trace}, nil
+	}
+	traces, err := searchTempoTraces(ctx, client, args)
+	if err != nil {
+		return nil, err
+	}
+	return &queryTempoTracesResult{Traces: traces}, nil
+}
+
+func searchTempoTraces(ctx context.Context, client *proxyClient, args QueryTempoTracesParams) ([]traceSummary, error) {
+	start, end, err := parseTimeRange(ctx, args.StartRFC3339, args.EndRFC3339)
+	if err != nil {
+		return nil, err
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = DefaultTempoSearchLimit
+	}
+	limit = min(limit, MaxTempoSearchLimit)
+
+	params := url.Values{}
+	params.Set("q", args.Query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+	var resp tempoSearchResponse
+	if err := client.getJSON(ctx, "/api/search", params, &resp); err != nil {
+		return nil, fmt.Errorf("searching Tempo traces: %w", err)
+	}
+
+	traces := make([]traceSummary, 0, len(resp.Traces))
+	for _, t := range resp.Traces {
+		matched := 0
+		for _, s := range t.SpanSets {
+			matched += s.Matched
+		}
+		traces = append(traces, traceSummary{
+			TraceID:         t.TraceID,
+			RootServiceName: t.RootServiceName,
+			RootTraceName:   t.RootTraceName,
+			StartTime:       formatUnixNano(t.StartTimeUnixNano),
+			DurationMs:      t.DurationMs,
+			MatchedSpans:    matched,
+		})
+	}
+	return traces, nil
+}
+
+func getTempoTrace(ctx context.Context, client *proxyClient, traceID string, maxSpans int) (*traceResult, error) {
+	if maxSpans == 0 {
+		maxSpans = DefaultTempoMaxSpans
+	}
+	var resp tempoTraceResponse
+	if err := client.getJSON(ctx, "/api/traces/"+traceID, nil, &resp); err != nil {
+		return nil, fmt.Errorf("getting Tempo trace %s: %w", traceID, err)
+	}
+
+	result := &traceResult{TraceID: traceID, Spans: []spanSummary{}}
+	for _, batch := range resp.Batches {
+		serviceName := ""
+		for _, attr := range batch.Resource.Attributes {
+			if attr.Key == "service.name" {
+				serviceName, _ = attr.value().(string)
+			}
+		}
+		for _, scope := range append(batch.ScopeSpans, batch.InstrumentationLibrarySpans...) {
+			for _, span := range scope.Spans {
+				result.TotalSpans++
+				if len(result.Spans) >= maxSpans {
+					continue
+				}
+				result.Spans = append(result.Spans, summarizeSpan(serviceName, span))
+			}
+		}
+	}
+	return result, nil
+}
+
+func summarizeSpan(serviceName string, span tempoSpan) spanSummary {
+	summary := spanSummary{
+		SpanID:        span.SpanID,
+		ParentSpanID:  span.ParentSpanID,
+		ServiceName:   serviceName,
+		Name:          span.Name,
+		Kind:          span.Kind,
+		StartTime:     formatUnixNano(span.StartTimeUnixNano),
+		StatusCode:    span.Status.Code,
+		StatusMessage: span.Status.Message,
+	}
+	start, startErr := strconv.ParseInt(span.StartTimeUnixNano, 10, 64)
+	end, endErr := strconv.ParseInt(span.EndTimeUnixNano, 10, 64)
+	if startErr == nil && endErr == nil {
+		summary.DurationMs = float64(end-start) / float64(time.Millisecond)
+	}
+	if len(span.Attributes) > 0 {
+		summary.Attributes = make(map[string]any, len(span.Attributes))
+		for _, attr := range span.Attributes {
+			summary.Attributes[attr.Key] = attr.value()
+		}
+	}
+	return summary
+}
+
+var QueryTempoTraces = mcpgrafana.MustTool(
+	"query_tempo_traces",
+	"Query traces from a Tempo datasource. Either search for traces with a TraceQL query, returning a summary of each matching trace (ID, root service and operation, start time, duration and number of matching spans), or fetch a single trace by ID, returning its spans with their service, name, timing, status and attributes. Search first, then fetch the traces of interest by ID",
+	queryTempoTraces,
+)
+
+// AddTempoTools registers all Tempo tools with the MCP server
+func AddTempoTools(mcp *server.MCPServer, filter mcpgrafana.ToolFilter) {
+	mcpgrafana.RegisterTools(mcp, filter, "tempo",
+		QueryTempoTraces,
+	)
+}
@@ -0,0 +1,167 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tempoSearchJSON = `{
+  "traces": [
+    {
+      "traceID": "2f3e0cee77ae5dc9c17ade3689eb2e54",
+      "rootServiceName": "api",
+      "rootTraceName": "GET /users",
+      "startTimeUnixNano": "1700000000000000000",
+      "durationMs": 42,
+      "spanSets": [{"matched": 2}, {"matched": 1}]
+    }
+  ]
+}`
+
+const tempoTraceJSON = `{
+  "batches": [
+    {
+      "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "api"}}]},
+      "scopeSpans": [
+        {
+          "spans": [
+            {
+              "traceId": "Lz4M7neuXcnBet42iesuVA==",
+              "spanId": "a1",
+              "name": "GET /users",
+              "kind": "SPAN_KIND_SERVER",
+              "startTimeUnixNano": "1700000000000000000",
+              "endTimeUnixNano": "1700000000042000000",
+              "attributes": [
+                {"key": "http.status_code", "value": {"intValue": "500"}},
+                {"key": "http.method", "value": {"stringValue": "GET"}}
+              ],
+              "status": {"code": "STATUS_CODE_ERROR", "message": "boom"}
+            },
+            {
+              "spanId": "a2",
+              "parentSpanId": "a1",
+              "name": "SELECT users",
+              "startTimeUnixNano": "1700000000001000000",
+              "endTimeUnixNano": "1700000000011500000"
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func newTestTempoServer(t *testing.T) context.Context {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		switch r.URL.Path {
+		case "/api/datasources/proxy/uid/tempo/api/search":
+			assert.Equal(t, `{ status = error }`, r.URL.Query().Get("q"))
+			assert.Equal(t, "20", r.URL.Query().Get("limit"))
+			assert.NotEmpty(t, r.URL.Query().Get("start"))
+			assert.NotEmpty(t, r.URL.Query().Get("end"))
+			_, _ = w.Write([]byte(tempoSearchJSON))
+		case "/api/datasources/proxy/uid/tempo/api/traces/2f3e0cee77ae5dc9c17ade3689eb2e54":
+			_, _ = w.Write([]byte(tempoTraceJSON))
+		default:
+			http.Error(w, "trace not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return mcpgrafana.WithGrafanaURL(context.Background(), srv.URL)
+}
+
+func TestQueryTempoTraces(t *testing.T) {
+	ctx := newTestTempoServer(t)
+
+	t.Run("search", func(t *testing.T) {
+		result, err := queryTempoTraces(ctx, QueryTempoTracesParams{
+			DatasourceUID: "tempo",
+			Query:         `{ status = error }`,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, result.Trace)
+		assert.Equal(t, []traceSummary{{
+			TraceID:         "2f3e0cee77ae5dc9c17ade3689eb2e54",
+			RootServiceName: "api",
+			RootTraceName:   "GET /users",
+			StartTime:       "2023-11-14T22:13:20Z",
+			DurationMs:      42,
+			MatchedSpans:    3,
+		}}, result.Traces)
+	})
+
+	t.Run("trace by ID", func(t *testing.T) {
+		result, err := queryTempoTraces(ctx, QueryTempoTracesParams{
+			DatasourceUID: "tempo",
+			TraceID:       "2f3e0cee77ae5dc9c17ade3689eb2e54",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.Trace)
+		assert.Equal(t, 2, result.Trace.TotalSpans)
+		assert.Equal(t, []spanSummary{
+			{
+				SpanID:        "a1",
+				ServiceName:   "api",
+				Name:          "GET /users",
+				Kind:          "SPAN_KIND_SERVER",
+				StartTime:     "2023-11-14T22:13:20Z",
+				DurationMs:    42,
+				StatusCode:    "STATUS_CODE_ERROR",
+				StatusMessage: "boom",
+				Attributes:    map[string]any{"http.status_code": int64(500), "http.method": "GET"},
+			},
+			{
+				SpanID:       "a2",
+				ParentSpanID: "a1",
+				ServiceName:  "api",
+				Name:         "SELECT users",
+				StartTime:    "2023-11-14T22:13:20.001Z",
+				DurationMs:   10.5,
+			},
+		}, result.Trace.Spans)
+	})
+
+	t.Run("trace by ID with span limit", func(t *testing.T) {
+		result, err := queryTempoTraces(ctx, QueryTempoTracesParams{
+			DatasourceUID: "tempo",
+			TraceID:       "2f3e0cee77ae5dc9c17ade3689eb2e54",
+			Limit:         1,
+		})
+		require.NoError(t, err)
+		assert.Len(t, result.Trace.Spans, 1)
+		assert.Equal(t, 2, result.Trace.TotalSpans)
+	})
+
+	t.Run("trace not found", func(t *testing.T) {
+		_, err := queryTempoTraces(ctx, QueryTempoTracesParams{
+			DatasourceUID: "tempo",
+			TraceID:       "abc",
+		})
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		for _, params := range []QueryTempoTracesParams{
+			{DatasourceUID: "tempo"},
+			{DatasourceUID: "tempo", Query: "{}", TraceID: "abc"},
+			{DatasourceUID: "tempo", TraceID: "not-a-trace-id"},
+		} {
+			_, err := queryTempoTraces(ctx, params)
+			var toolErr *mcpgrafana.ToolError
+			assert.True(t, errors.As(err, &toolErr), "%+v", params)
+		}
+	})
+}
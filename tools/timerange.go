@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+)
+
+// relativeTimeOp matches one operation of a Grafana relative time expression,
+// either adding or subtracting an amount of a unit, e.g. '-15m', or rounding
+// to a unit, e.g. '/d'.
+var relativeTimeOp = regexp.MustCompile(`^(?:([+-])(\d*)|/)([smhdwMy])`)
+
+// parseTime parses a time which is either in RFC3339 format or a Grafana
+// relative time expression such as 'now', 'now-15m' or 'now-1d/d'. Relative
+// times are resolved against now. Rounding, e.g. '/d', rounds down to the
+// start of the unit, or up to its end if roundUp is set, as Grafana does for
+// the end of a time range. Rounding is done in UTC, and weeks start on
+// Monday.
+func parseTime(value string, now time.Time, roundUp bool) (time.Time, error) {
+	rest, ok := strings.CutPrefix(value, "now")
+	if !ok {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q: must be in RFC3339 format or relative to now, e.g. 'now-1h'", value)
+		}
+		return t, nil
+	}
+
+	t := now.UTC()
+	for rest != "" {
+		m := relativeTimeOp.FindStringSubmatch(rest)
+		if m == nil {
+			return time.Time{}, fmt.Errorf("invalid relative time %q: expected operations such as '-15m' or '/d' after 'now'", value)
+		}
+		rest = rest[len(m[0]):]
+		sign, amount, unit := m[1], m[2], m[3]
+		if sign == "" {
+			t = roundTime(t, unit, roundUp)
+			continue
+		}
+		n := 1
+		if amount != "" {
+			var err error
+			if n, err = strconv.Atoi(amount); err != nil {
+				return time.Time{}, fmt.Errorf("invalid relative time %q: %w", value, err)
+			}
+		}
+		if n > maxTimeUnits(unit) {
+			return time.Time{}, fmt.Errorf("invalid relative time %q: %s%s is more than %d years", value, amount, unit, maxRelativeTimeYears)
+		}
+		if sign == "-" {
+			n = -n
+		}
+		t = addTimeUnits(t, n, unit)
+	}
+	return t, nil
+}
+
+// maxRelativeTimeYears is the largest offset from now, in years, which a single
+// operation of a relative time can have. Much larger offsets would overflow
+// time.Duration.
+const maxRelativeTimeYears = 100
+
+const maxRelativeTimeOffset = maxRelativeTimeYears * 366 * 24 * time.Hour
+
+// maxTimeUnits returns the most of the given unit which fit in
+// maxRelativeTimeOffset, using the longest length of days, months and years.
+func maxTimeUnits(unit string) int {
+	length := map[string]time.Duration{
+		"s": time.Second,
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"M": 31 * 24 * time.Hour,
+		"y": 366 * 24 * time.Hour,
+	}[unit]
+	return int(maxRelativeTimeOffset / length)
+}
+
+// addTimeUnits adds n of the given unit to t, which must be at most
+// maxTimeUnits(unit).
+func addTimeUnits(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Add(time.Duration(n) * time.Second)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "M":
+		return t.AddDate(0, n, 0)
+	default: // "y"
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+// roundTime rounds t down to the start of the given unit, or up to the last
+// nanosecond of it if roundUp is set.
+func roundTime(t time.Time, unit string, roundUp bool) time.Time {
+	var start time.Time
+	switch unit {
+	case "s":
+		start = t.Truncate(time.Second)
+	case "m":
+		start = t.Truncate(time.Minute)
+	case "h":
+		start = t.Truncate(time.Hour)
+	case "d":
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case "w":
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		start = time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	case "M":
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default: // "y"
+		start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	if !roundUp {
+		return start
+	}
+	return addTimeUnits(start, 1, unit).Add(-time.Nanosecond)
+}
+
+// sinceTimeRange returns the time range covering the last sinceSeconds
+// seconds, as RFC3339 start and end times, if sinceSeconds is set and
+// neither the start nor the end of the range is given. Otherwise the start
+// and end are returned unchanged, so that explicit times take precedence.
+func sinceTimeRange(sinceSeconds int, startRFC3339, endRFC3339 string) (string, string, error) {
+	if sinceSeconds < 0 {
+		return "", "", mcpgrafana.NewToolError(fmt.Errorf("invalid sinceSeconds: %d, must be greater than 0", sinceSeconds))
+	}
+	if sinceSeconds == 0 || startRFC3339 != "" || endRFC3339 != "" {
+		return startRFC3339, endRFC3339, nil
+	}
+	end := time.Now()
+	start := end.Add(-time.Duration(sinceSeconds) * time.Second)
+	return start.Format(time.RFC3339), end.Format(time.RFC3339), nil
+}
+
+// getDefaultTimeRange fills in the start and end of a time range if they
+// aren't given, and resolves relative times, returning both in RFC3339
+// format. The end defaults to now, and the start to the configured default
+// time range before the end. Invalid times are returned unchanged, so that
+// the error is reported when they're parsed.
+func getDefaultTimeRange(ctx context.Context, startRFC3339, endRFC3339 string) (string, string) {
+	now := time.Now()
+	end := now
+	if endRFC3339 == "" {
+		endRFC3339 = end.Format(time.RFC3339)
+	} else if t, err := parseTime(endRFC3339, now, true); err == nil {
+		end = t
+		endRFC3339 = t.Format(time.RFC3339Nano)
+	}
+	if startRFC3339 == "" {
+		startRFC3339 = end.Add(-mcpgrafana.DefaultTimeRangeFromContext(ctx)).Format(time.RFC3339)
+	} else if t, err := parseTime(startRFC3339, now, false); err == nil {
+		startRFC3339 = t.Format(time.RFC3339Nano)
+	}
+	return startRFC3339, endRFC3339
+}
+
+// parseTimeRange parses the start and end of a time range, each in RFC3339
+// format or relative to now, filling them in with getDefaultTimeRange if they
+// aren't given.
+func parseTimeRange(ctx context.Context, startRFC3339, endRFC3339 string) (time.Time, time.Time, error) {
+	startRFC3339, endRFC3339 = getDefaultTimeRange(ctx, startRFC3339, endRFC3339)
+	start, err := parseTime(startRFC3339, time.Now(), false)
+	if err != nil {
+		return time.Time{}, time.Time{}, mcpgrafana.NewToolError(fmt.Errorf("parsing start time: %w", err))
+	}
+	end, err := parseTime(endRFC3339, time.Now(), true)
+	if err != nil {
+		return time.Time{}, time.Time{}, mcpgrafana.NewToolError(fmt.Errorf("parsing end time: %w", err))
+	}
+	return start, end, nil
+}
@@ -0,0 +1,139 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	ctx := mcpgrafana.WithDefaultTimeRange(context.Background(), 6*time.Hour)
+
+	t.Run("explicit", func(t *testing.T) {
+		start, end, err := parseTimeRange(ctx, "2024-01-01T00:00:00Z", "2024-01-01T01:00:00Z")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), start.UTC())
+		assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), end.UTC())
+	})
+
+	t.Run("start defaults to the default time range before the end", func(t *testing.T) {
+		start, end, err := parseTimeRange(ctx, "", "2024-01-01T12:00:00Z")
+		require.NoError(t, err)
+		assert.Equal(t, 6*time.Hour, end.Sub(start))
+	})
+
+	t.Run("end defaults to now", func(t *testing.T) {
+		before := time.Now().Truncate(time.Second)
+		start, end, err := parseTimeRange(ctx, "", "")
+		require.NoError(t, err)
+		assert.False(t, end.Before(before))
+		assert.Equal(t, 6*time.Hour, end.Sub(start))
+	})
+
+	t.Run("default time range without configuration", func(t *testing.T) {
+		start, end, err := parseTimeRange(context.Background(), "", "2024-01-01T12:00:00Z")
+		require.NoError(t, err)
+		assert.Equal(t, mcpgrafana.DefaultTimeRange, end.Sub(start))
+	})
+
+	t.Run("relative", func(t *testing.T) {
+		start, end, err := parseTimeRange(ctx, "now-6h/h", "now")
+		require.NoError(t, err)
+		assert.Equal(t, 0, start.Minute())
+		assert.True(t, start.Before(end))
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		_, _, err := parseTimeRange(ctx, "yesterday", "")
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+
+	t.Run("out of range offset", func(t *testing.T) {
+		_, _, err := parseTimeRange(ctx, "now-9999999999d", "")
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+		assert.Contains(t, err.Error(), "is more than")
+	})
+}
+
+func TestSinceTimeRange(t *testing.T) {
+	t.Run("since", func(t *testing.T) {
+		start, end, err := sinceTimeRange(900, "", "")
+		require.NoError(t, err)
+		startTime, err := time.Parse(time.RFC3339, start)
+		require.NoError(t, err)
+		endTime, err := time.Parse(time.RFC3339, end)
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, endTime.Sub(startTime))
+		assert.WithinDuration(t, time.Now(), endTime, 5*time.Second)
+	})
+
+	t.Run("explicit times take precedence", func(t *testing.T) {
+		start, end, err := sinceTimeRange(900, "now-1h", "")
+		require.NoError(t, err)
+		assert.Equal(t, "now-1h", start)
+		assert.Empty(t, end)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		start, end, err := sinceTimeRange(0, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, start)
+		assert.Empty(t, end)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, _, err := sinceTimeRange(-1, "", "")
+		var toolErr *mcpgrafana.ToolError
+		assert.True(t, errors.As(err, &toolErr))
+	})
+}
+
+func TestParseTime(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2024, 5, 15, 13, 45, 30, 0, time.UTC)
+	for _, tc := range []struct {
+		value    string
+		roundUp  bool
+		expected time.Time
+	}{
+		{"2024-01-01T00:00:00Z", false, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"now", false, now},
+		{"now-15m", false, now.Add(-15 * time.Minute)},
+		{"now+1h", false, now.Add(time.Hour)},
+		{"now-h", false, now.Add(-time.Hour)},
+		{"now-2d", false, time.Date(2024, 5, 13, 13, 45, 30, 0, time.UTC)},
+		{"now-1M", false, time.Date(2024, 4, 15, 13, 45, 30, 0, time.UTC)},
+		{"now-1y", false, time.Date(2023, 5, 15, 13, 45, 30, 0, time.UTC)},
+		{"now/d", false, time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"now/d", true, time.Date(2024, 5, 15, 23, 59, 59, 999999999, time.UTC)},
+		{"now-1d/d", false, time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)},
+		{"now/h", false, time.Date(2024, 5, 15, 13, 0, 0, 0, time.UTC)},
+		{"now/w", false, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{"now/M", true, time.Date(2024, 5, 31, 23, 59, 59, 999999999, time.UTC)},
+		{"now/y", false, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"now-100y", false, time.Date(1924, 5, 15, 13, 45, 30, 0, time.UTC)},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseTime(tc.value, now, tc.roundUp)
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(got), "expected %s, got %s", tc.expected, got)
+		})
+	}
+
+	for _, value := range []string{"", "yesterday", "now-", "now-1x", "now/", "nowish", "2024-01-01", "now-9999999999d", "now+3000000h", "now-101y", "now-99999999999999999999s"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			_, err := parseTime(value, now, false)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// mutatingPrefixes are the name prefixes of tools which change resources.
+var mutatingPrefixes = []string{"create_", "post_", "update_", "delete_", "add_", "assign_", "resolve_", "acknowledge_", "restore_"}
+
+func TestToolsMarkedMutating(t *testing.T) {
+	tools := map[string]mcpgrafana.Tool{}
+	filter := func(category string, tool mcpgrafana.Tool) bool {
+		tools[tool.Tool.Name] = tool
+		return true
+	}
+	s := server.NewMCPServer("test", "0.0.1")
+	AddSearchTools(s, filter)
+	AddDatasourceTools(s, filter)
+	AddIncidentTools(s, filter)
+	AddPrometheusTools(s, filter)
+	AddLokiTools(s, filter)
+	AddTempoTools(s, filter)
+	AddPyroscopeTools(s, filter)
+	AddAlertingTools(s, filter)
+	AddDashboardTools(s, filter)
+	AddFolderTools(s, filter)
+	AddOnCallTools(s, filter)
+	AddAdminTools(s, filter)
+	AddAnnotationTools(s, filter)
+	AddPlaylistTools(s, filter)
+
+	for name, tool := range tools {
+		for _, prefix := range mutatingPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				assert.True(t, tool.Mutating, "%s should be marked as mutating", name)
+			}
+		}
+	}
+	// The datasource proxy can send arbitrary requests, so it's mutating too.
+	assert.True(t, tools["query_datasource_proxy"].Mutating)
+	// Queries check the datasource type in read-only mode instead.
+	assert.False(t, tools["query_datasource"].Mutating)
+	assert.False(t, tools["get_dashboard_panel_data"].Mutating)
+	assert.False(t, tools["test_alert_rule"].Mutating)
+	assert.False(t, tools["query_prometheus"].Mutating)
+}
@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// clientTimeout returns the timeout to use for HTTP clients created for a
+// tool call. A deadline on the context takes precedence over the configured
+// timeout, so callers can shorten or extend it per call; in that case no
+// client timeout is returned.
+func clientTimeout(ctx context.Context) time.Duration {
+	if _, ok := ctx.Deadline(); ok {
+		return 0
+	}
+	return mcpgrafana.GrafanaTimeoutFromContext(ctx)
+}
+
+// tlsTransports caches the transports created for each TLS configuration so
+// that connections are reused across tool calls.
+var tlsTransports sync.Map // map[*tls.Config]*http.Transport
+
+// newTransport returns the base transport for requests to Grafana. It uses the
+// TLS configuration in the context if there is one, and
+// http.DefaultTransport otherwise.
+func newTransport(ctx context.Context) http.RoundTripper {
+	tlsConfig := mcpgrafana.GrafanaTLSConfigFromContext(ctx)
+	if tlsConfig == nil {
+		return http.DefaultTransport
+	}
+	if t, ok := tlsTransports.Load(tlsConfig); ok {
+		return t.(*http.Transport)
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tlsConfig
+	actual, _ := tlsTransports.LoadOrStore(tlsConfig, t)
+	return actual.(*http.Transport)
+}
+
+// proxyHeadersRoundTripper adds the extra headers configured for datasources
+// to each request.
+type proxyHeadersRoundTripper struct {
+	headers    map[string]string
+	underlying http.RoundTripper
+}
+
+func (rt *proxyHeadersRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	for name, value := range rt.headers {
+		req.Header.Set(name, value)
+	}
+	return rt.underlying.RoundTrip(req)
+}
+
+// newDatasourceTransport returns the transport for requests to datasources
+// through Grafana's datasource proxy. It authenticates like
+// newAuthRoundTripper and also sends the extra headers in the context, if
+// any. The extra headers can't replace the credentials, tenant ID or org ID,
+// which are set after them.
+func newDatasourceTransport(ctx context.Context) http.RoundTripper {
+	rt := http.RoundTripper(newAuthRoundTripper(ctx, newTransport(ctx)))
+	headers := mcpgrafana.ProxyHeadersFromContext(ctx)
+	if len(headers) == 0 {
+		return rt
+	}
+	return &proxyHeadersRoundTripper{headers: headers, underlying: rt}
+}
+
+// tenantIDRoundTripper sends the tenant ID as the X-Scope-OrgID header.
+type tenantIDRoundTripper struct {
+	tenantID   string
+	underlying http.RoundTripper
+}
+
+func (rt *tenantIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-Scope-OrgID", rt.tenantID)
+	return rt.underlying.RoundTrip(req)
+}
+
+// newDirectDatasourceTransport returns the transport for requests sent
+// straight to a datasource's own URL, bypassing Grafana. Grafana's
+// credentials and org ID are never sent, since they mean nothing to the
+// datasource and would leak to it; only the tenant ID and the extra headers
+// in the context, if any, are.
+func newDirectDatasourceTransport(ctx context.Context) http.RoundTripper {
+	rt := newTransport(ctx)
+	if tenantID := mcpgrafana.GrafanaTenantIDFromContext(ctx); tenantID != "" {
+		rt = &tenantIDRoundTripper{tenantID: tenantID, underlying: rt}
+	}
+	if headers := mcpgrafana.ProxyHeadersFromContext(ctx); len(headers) > 0 {
+		rt = &proxyHeadersRoundTripper{headers: headers, underlying: rt}
+	}
+	return rt
+}
+
+type authRoundTripper struct {
+	apiKey     string
+	basicAuth  *url.Userinfo
+	tenantID   string
+	orgID      int64
+	underlying http.RoundTripper
+}
+
+// newAuthRoundTripper creates an authRoundTripper using the credentials in the
+// context. The API key is used as a bearer token if set; otherwise the basic
+// auth credentials are used, if any. If the context has a tenant ID it is sent
+// as the X-Scope-OrgID header, and if it has an org ID it is sent as the
+// X-Grafana-Org-Id header.
+func newAuthRoundTripper(ctx context.Context, underlying http.RoundTripper) *authRoundTripper {
+	return &authRoundTripper{
+		apiKey:     mcpgrafana.GrafanaAPIKeyFromContext(ctx),
+		basicAuth:  mcpgrafana.GrafanaBasicAuthFromContext(ctx),
+		tenantID:   mcpgrafana.GrafanaTenantIDFromContext(ctx),
+		orgID:      mcpgrafana.GrafanaOrgIDFromContext(ctx),
+		underlying: underlying,
+	}
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+rt.apiKey)
+	} else if rt.basicAuth != nil {
+		password, _ := rt.basicAuth.Password()
+		req.SetBasicAuth(rt.basicAuth.Username(), password)
+	}
+	if rt.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", rt.tenantID)
+	}
+	if rt.orgID != 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(rt.orgID, 10))
+	}
+	// Propagate the trace context, if any, so that Grafana's traces can be
+	// correlated with ours.
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+
+	resp, err := rt.underlying.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
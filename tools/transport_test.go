@@ -0,0 +1,178 @@
+//go:build unit
+// +build unit
+
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mcpgrafana "github.com/grafana/mcp-grafana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// roundTripHeaders sends a request through an authRoundTripper created from
+// `ctx` and returns the headers received by the server.
+func roundTripHeaders(t *testing.T, ctx context.Context) http.Header {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newAuthRoundTripper(ctx, http.DefaultTransport)}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return headers
+}
+
+// roundTripAuthorization sends a request through an authRoundTripper created
+// from `ctx` and returns the Authorization header received by the server.
+func roundTripAuthorization(t *testing.T, ctx context.Context) string {
+	return roundTripHeaders(t, ctx).Get("Authorization")
+}
+
+func TestAuthRoundTripper(t *testing.T) {
+	t.Run("no credentials", func(t *testing.T) {
+		assert.Equal(t, "", roundTripAuthorization(t, context.Background()))
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaAPIKey(context.Background(), "my-api-key")
+		assert.Equal(t, "Bearer my-api-key", roundTripAuthorization(t, ctx))
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaBasicAuth(context.Background(), url.UserPassword("admin", "secret"))
+		// "admin:secret" base64-encoded.
+		assert.Equal(t, "Basic YWRtaW46c2VjcmV0", roundTripAuthorization(t, ctx))
+	})
+
+	t.Run("api key takes precedence over basic auth", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaAPIKey(context.Background(), "my-api-key")
+		ctx = mcpgrafana.WithGrafanaBasicAuth(ctx, url.UserPassword("admin", "secret"))
+		assert.Equal(t, "Bearer my-api-key", roundTripAuthorization(t, ctx))
+	})
+
+	t.Run("tenant id", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaTenantID(context.Background(), "tenant-1")
+		assert.Equal(t, "tenant-1", roundTripHeaders(t, ctx).Get("X-Scope-OrgID"))
+	})
+
+	t.Run("trace context", func(t *testing.T) {
+		previous := otel.GetTextMapPropagator()
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+		tp := sdktrace.NewTracerProvider()
+		ctx, span := tp.Tracer("test").Start(context.Background(), "test")
+		defer span.End()
+		traceparent := roundTripHeaders(t, ctx).Get("Traceparent")
+		assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+	})
+
+	t.Run("org id", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaOrgID(context.Background(), 2)
+		assert.Equal(t, "2", roundTripHeaders(t, ctx).Get("X-Grafana-Org-Id"))
+	})
+
+	t.Run("no org id", func(t *testing.T) {
+		_, ok := roundTripHeaders(t, context.Background())["X-Grafana-Org-Id"]
+		assert.False(t, ok)
+	})
+
+	t.Run("no tenant id", func(t *testing.T) {
+		_, ok := roundTripHeaders(t, context.Background())["X-Scope-Orgid"]
+		assert.False(t, ok)
+	})
+}
+
+func TestClientTimeout(t *testing.T) {
+	t.Run("default timeout", func(t *testing.T) {
+		assert.Equal(t, mcpgrafana.DefaultTimeout, clientTimeout(context.Background()))
+	})
+
+	t.Run("configured timeout", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaTimeout(context.Background(), 5*time.Second)
+		assert.Equal(t, 5*time.Second, clientTimeout(ctx))
+	})
+
+	t.Run("context deadline takes precedence", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		assert.Equal(t, time.Duration(0), clientTimeout(ctx))
+	})
+}
+
+func TestNewTransport(t *testing.T) {
+	t.Run("default transport", func(t *testing.T) {
+		assert.Equal(t, http.DefaultTransport, newTransport(context.Background()))
+	})
+
+	t.Run("tls config", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		ctx := mcpgrafana.WithGrafanaTLSConfig(context.Background(), &tls.Config{InsecureSkipVerify: true})
+		transport := newTransport(ctx)
+		assert.Same(t, transport, newTransport(ctx), "transports should be reused for the same TLS config")
+
+		client := &http.Client{Transport: transport}
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		// The server's certificate isn't trusted without the TLS config.
+		client = &http.Client{Transport: newTransport(context.Background())}
+		_, err = client.Get(server.URL)
+		require.Error(t, err)
+	})
+}
+
+func TestNewDatasourceTransport(t *testing.T) {
+	roundTrip := func(t *testing.T, ctx context.Context) http.Header {
+		var headers http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: newDatasourceTransport(ctx)}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return headers
+	}
+
+	t.Run("no extra headers", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaAPIKey(context.Background(), "my-api-key")
+		_, ok := newDatasourceTransport(ctx).(*authRoundTripper)
+		assert.True(t, ok)
+	})
+
+	t.Run("extra headers", func(t *testing.T) {
+		ctx := mcpgrafana.WithGrafanaAPIKey(context.Background(), "my-api-key")
+		ctx = mcpgrafana.WithProxyHeaders(ctx, map[string]string{
+			"X-Query-Tags":  "source=mcp",
+			"Authorization": "Bearer other",
+		})
+		headers := roundTrip(t, ctx)
+		assert.Equal(t, "source=mcp", headers.Get("X-Query-Tags"))
+		assert.Equal(t, "Bearer my-api-key", headers.Get("Authorization"), "extra headers must not replace the credentials")
+	})
+}
@@ -5,10 +5,14 @@ package mcpgrafana
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -148,7 +152,7 @@ func TestConvertTool(t *testing.T) {
 
 		_, err = handler(ctx, errorRequest)
 		assert.Error(t, err)
-		assert.Equal(t, "test error", err.Error())
+		assert.Regexp(t, `^test error \(request ID: [0-9a-f]{16}\)$`, err.Error())
 	})
 
 	t.Run("empty handler params", func(t *testing.T) {
@@ -257,7 +261,7 @@ func TestConvertTool(t *testing.T) {
 
 		_, err = handler(ctx, errorRequest)
 		assert.Error(t, err)
-		assert.Equal(t, "test error", err.Error())
+		assert.Regexp(t, `^test error \(request ID: [0-9a-f]{16}\)$`, err.Error())
 	})
 
 	t.Run("string pointer return type", func(t *testing.T) {
@@ -351,7 +355,7 @@ func TestConvertTool(t *testing.T) {
 
 		_, err = handler(ctx, errorRequest)
 		assert.Error(t, err)
-		assert.Equal(t, "test error", err.Error())
+		assert.Regexp(t, `^test error \(request ID: [0-9a-f]{16}\)$`, err.Error())
 	})
 
 	t.Run("struct return type", func(t *testing.T) {
@@ -404,7 +408,7 @@ func TestConvertTool(t *testing.T) {
 
 		_, err = handler(ctx, errorRequest)
 		assert.Error(t, err)
-		assert.Equal(t, "test error", err.Error())
+		assert.Regexp(t, `^test error \(request ID: [0-9a-f]{16}\)$`, err.Error())
 	})
 
 	t.Run("struct pointer return type", func(t *testing.T) {
@@ -478,17 +482,17 @@ func TestConvertTool(t *testing.T) {
 
 		_, err = handler(ctx, errorRequest)
 		assert.Error(t, err)
-		assert.Equal(t, "test error", err.Error())
+		assert.Regexp(t, `^test error \(request ID: [0-9a-f]{16}\)$`, err.Error())
 	})
 
 	t.Run("invalid handler types", func(t *testing.T) {
-		// Test wrong second argument type (not a struct)
+		// Test wrong second argument type (not a struct or slice)
 		wrongSecondArgFunc := func(ctx context.Context, s string) (*mcp.CallToolResult, error) {
 			return nil, nil
 		}
 		_, _, err := ConvertTool("invalid", "description", wrongSecondArgFunc)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "second argument must be a struct")
+		assert.Contains(t, err.Error(), "second argument must be a struct or a slice")
 	})
 
 	t.Run("handler execution with invalid arguments", func(t *testing.T) {
@@ -558,3 +562,351 @@ func TestCreateJSONSchemaFromHandler(t *testing.T) {
 	assert.Equal(t, "boolean", optionalProperty.Type)
 	assert.Equal(t, "An optional parameter", optionalProperty.Description)
 }
+
+type enumToolParams struct {
+	Mode      string `json:"mode,omitempty" jsonschema:"description=The mode to use,enum=fast,enum=slow"`
+	Direction string `json:"direction,omitempty" jsonschema:"description=Optionally\\, the direction,enum=up,enum=down"`
+}
+
+func enumToolHandler(ctx context.Context, params enumToolParams) (string, error) {
+	return params.Mode, nil
+}
+
+func TestConvertToolEnum(t *testing.T) {
+	tool, _, err := ConvertTool("enum_tool", "A tool with enum parameters", enumToolHandler)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(tool.InputSchema.Properties)
+	require.NoError(t, err)
+	var properties map[string]struct {
+		Description string   `json:"description"`
+		Enum        []string `json:"enum"`
+	}
+	require.NoError(t, json.Unmarshal(b, &properties))
+
+	assert.Equal(t, "The mode to use", properties["mode"].Description)
+	assert.Equal(t, []string{"fast", "slow"}, properties["mode"].Enum)
+	assert.Equal(t, "Optionally, the direction", properties["direction"].Description)
+	assert.Equal(t, []string{"up", "down"}, properties["direction"].Enum)
+}
+
+type nestedToolParams struct {
+	Filters []struct {
+		Name string `json:"name" jsonschema:"required,description=The name"`
+		Op   string `json:"op" jsonschema:"required,description=The operator,enum=eq,enum=neq"`
+	} `json:"filters" jsonschema:"description=The filters"`
+}
+
+func nestedToolHandler(ctx context.Context, params nestedToolParams) (string, error) {
+	return "ok", nil
+}
+
+func TestConvertToolValidatesArguments(t *testing.T) {
+	// call returns the message of the tool error result if the arguments are
+	// invalid.
+	call := func(t *testing.T, handler server.ToolHandlerFunc, args map[string]any) error {
+		var request mcp.CallToolRequest
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		if result == nil || !result.IsError {
+			return nil
+		}
+		return errors.New(result.Content[0].(mcp.TextContent).Text)
+	}
+
+	_, testHandler, err := ConvertTool("test_tool", "A test tool", testToolHandler)
+	require.NoError(t, err)
+	_, enumHandler, err := ConvertTool("enum_tool", "A tool with enum parameters", enumToolHandler)
+	require.NoError(t, err)
+	_, nestedHandler, err := ConvertTool("nested_tool", "A tool with nested parameters", nestedToolHandler)
+	require.NoError(t, err)
+
+	t.Run("missing required parameter", func(t *testing.T) {
+		err := call(t, testHandler, map[string]any{"name": "test"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing required parameter "value"`)
+	})
+
+	t.Run("null required parameter", func(t *testing.T) {
+		err := call(t, testHandler, map[string]any{"name": nil, "value": 1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing required parameter "name"`)
+	})
+
+	t.Run("invalid enum value", func(t *testing.T) {
+		err := call(t, enumHandler, map[string]any{"mode": "medium"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid value "medium" for parameter "mode", must be one of fast, slow`)
+	})
+
+	t.Run("valid enum value", func(t *testing.T) {
+		assert.NoError(t, call(t, enumHandler, map[string]any{"mode": "fast", "direction": "down"}))
+	})
+
+	t.Run("empty optional enum value", func(t *testing.T) {
+		assert.NoError(t, call(t, enumHandler, map[string]any{"mode": ""}))
+	})
+
+	t.Run("nested parameters", func(t *testing.T) {
+		err := call(t, nestedHandler, map[string]any{
+			"filters": []any{
+				map[string]any{"name": "a", "op": "eq"},
+				map[string]any{"name": "b"},
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `missing required parameter "filters[1].op"`)
+
+		err = call(t, nestedHandler, map[string]any{
+			"filters": []any{map[string]any{"name": "a", "op": "like"}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `parameter "filters[0].op"`)
+	})
+}
+
+func TestConvertToolContentResult(t *testing.T) {
+	image := mcp.NewImageContent("aW1hZ2U=", "image/png")
+	call := func(t *testing.T, handler server.ToolHandlerFunc) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("image", func(t *testing.T) {
+		_, handler, err := ConvertTool("image_tool", "Return an image", func(ctx context.Context, params emptyToolParams) (mcp.ImageContent, error) {
+			return image, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []mcp.Content{image}, call(t, handler).Content)
+	})
+
+	t.Run("image pointer", func(t *testing.T) {
+		_, handler, err := ConvertTool("image_tool", "Return an image", func(ctx context.Context, params emptyToolParams) (*mcp.ImageContent, error) {
+			return &image, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []mcp.Content{image}, call(t, handler).Content)
+	})
+
+	t.Run("content list", func(t *testing.T) {
+		contents := []mcp.Content{mcp.NewTextContent("a graph"), image}
+		_, handler, err := ConvertTool("contents_tool", "Return text and an image", func(ctx context.Context, params emptyToolParams) ([]mcp.Content, error) {
+			return contents, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, contents, call(t, handler).Content)
+	})
+
+	t.Run("nil image pointer", func(t *testing.T) {
+		_, handler, err := ConvertTool("image_tool", "Return an image", func(ctx context.Context, params emptyToolParams) (*mcp.ImageContent, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Nil(t, call(t, handler))
+	})
+}
+
+func TestConvertToolSliceArgument(t *testing.T) {
+	tool, handler, err := ConvertTool("join_tool", "Join strings", func(ctx context.Context, items []string) (string, error) {
+		return strings.Join(items, ","), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "object", tool.InputSchema.Type)
+	assert.Equal(t, []string{"items"}, tool.InputSchema.Required)
+	b, err := json.Marshal(tool.InputSchema.Properties["items"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"array","items":{"type":"string"},"description":"The items to process"}`, string(b))
+
+	call := func(args map[string]any) (*mcp.CallToolResult, error) {
+		var request mcp.CallToolRequest
+		request.Params.Arguments = args
+		return handler(context.Background(), request)
+	}
+
+	t.Run("items", func(t *testing.T) {
+		result, err := call(map[string]any{"items": []any{"a", "b"}})
+		require.NoError(t, err)
+		assert.Equal(t, "a,b", result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("missing items", func(t *testing.T) {
+		result, err := call(map[string]any{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `missing required parameter "items"`)
+	})
+
+	t.Run("wrong item type", func(t *testing.T) {
+		_, err := call(map[string]any{"items": []any{1}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unmarshal args")
+	})
+
+	t.Run("struct items", func(t *testing.T) {
+		_, handler, err := ConvertTool("struct_tool", "Take structs", func(ctx context.Context, items []testToolParams) (int, error) {
+			return len(items), nil
+		})
+		require.NoError(t, err)
+		var request mcp.CallToolRequest
+		request.Params.Arguments = map[string]any{"items": []any{map[string]any{"name": "a"}}}
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `missing required parameter "items[0].value"`)
+	})
+}
+
+func TestConvertToolError(t *testing.T) {
+	call := func(t *testing.T, err error) (*mcp.CallToolResult, error) {
+		_, handler, convErr := ConvertTool("test_tool", "A test tool", func(ctx context.Context, params emptyToolParams) (string, error) {
+			return "", err
+		})
+		require.NoError(t, convErr)
+		return handler(context.Background(), mcp.CallToolRequest{})
+	}
+
+	t.Run("tool error", func(t *testing.T) {
+		result, err := call(t, fmt.Errorf("querying: %w", NewToolError(errors.New("datasource not found"))))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.True(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		assert.Equal(t, "querying: datasource not found", textContent.Text)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		result, err := call(t, errors.New("connection refused"))
+		assert.Nil(t, result)
+		assert.Regexp(t, `^connection refused \(request ID: [0-9a-f]+\)$`, err.Error())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, NewToolError(nil))
+	})
+}
+
+func TestNewToolFilter(t *testing.T) {
+	query := MustTool("query_things", "Query things", emptyToolHandler)
+	create := MustTool("create_thing", "Create a thing", emptyToolHandler)
+
+	t.Run("no lists allows everything", func(t *testing.T) {
+		assert.Nil(t, NewToolFilter(nil, nil))
+	})
+
+	t.Run("enabled categories and tools", func(t *testing.T) {
+		filter := NewToolFilter([]string{"things", "other_tool"}, nil)
+		assert.True(t, filter("things", query))
+		assert.False(t, filter("other", query))
+		assert.True(t, filter("other", MustTool("other_tool", "Other", emptyToolHandler)))
+	})
+
+	t.Run("disabled categories and tools", func(t *testing.T) {
+		filter := NewToolFilter(nil, []string{"create_thing"})
+		assert.True(t, filter("things", query))
+		assert.False(t, filter("things", create))
+
+		filter = NewToolFilter(nil, []string{"things"})
+		assert.False(t, filter("things", query))
+		assert.True(t, filter("other", query))
+	})
+
+	t.Run("disabled takes precedence over enabled", func(t *testing.T) {
+		filter := NewToolFilter([]string{"things"}, []string{"create_thing"})
+		assert.True(t, filter("things", query))
+		assert.False(t, filter("things", create))
+	})
+}
+
+func TestRegisterTools(t *testing.T) {
+	listTools := func(t *testing.T, s *server.MCPServer) []string {
+		response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %v", response)
+		result, ok := resp.Result.(mcp.ListToolsResult)
+		require.True(t, ok, "unexpected result %v", resp.Result)
+		names := []string{}
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	query := MustTool("query_things", "Query things", emptyToolHandler)
+	create := MustTool("create_thing", "Create a thing", emptyToolHandler)
+
+	t.Run("nil filter", func(t *testing.T) {
+		s := server.NewMCPServer("test", "0.0.1")
+		RegisterTools(s, nil, "things", query, create)
+		assert.ElementsMatch(t, []string{"query_things", "create_thing"}, listTools(t, s))
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		s := server.NewMCPServer("test", "0.0.1")
+		RegisterTools(s, NewToolFilter(nil, []string{"create_thing"}), "things", query, create)
+		assert.Equal(t, []string{"query_things"}, listTools(t, s))
+	})
+}
+
+func TestToolFilterReadOnly(t *testing.T) {
+	query := MustTool("query_things", "Query things", emptyToolHandler)
+	create := MustTool("create_thing", "Create a thing", emptyToolHandler).AsMutating()
+	assert.False(t, query.Mutating)
+	assert.True(t, create.Mutating)
+
+	t.Run("nil filter", func(t *testing.T) {
+		filter := ToolFilter(nil).ReadOnly()
+		assert.True(t, filter("things", query))
+		assert.False(t, filter("things", create))
+	})
+
+	t.Run("wrapped filter", func(t *testing.T) {
+		filter := NewToolFilter(nil, []string{"query_things"}).ReadOnly()
+		assert.False(t, filter("things", query))
+		assert.False(t, filter("things", create))
+	})
+}
+
+func TestTruncateResponse(t *testing.T) {
+	t.Run("within budget", func(t *testing.T) {
+		ctx := WithMaxResponseBytes(context.Background(), 10)
+		assert.Equal(t, "0123456789", truncateResponse(ctx, "0123456789"))
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		ctx := WithMaxResponseBytes(context.Background(), 10)
+		result := truncateResponse(ctx, "0123456789abcdef")
+		assert.True(t, strings.HasPrefix(result, "0123456789...[truncated 6 bytes]"), result)
+		assert.Contains(t, result, "Narrow the query")
+	})
+
+	t.Run("multi-byte characters aren't split", func(t *testing.T) {
+		ctx := WithMaxResponseBytes(context.Background(), 2)
+		// "é" is two bytes, so the budget falls in the middle of the second one.
+		result := truncateResponse(ctx, "aéé")
+		assert.True(t, strings.HasPrefix(result, "a...[truncated 4 bytes]"), result)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx := WithMaxResponseBytes(context.Background(), 0)
+		long := strings.Repeat("a", DefaultMaxResponseBytes*2)
+		assert.Equal(t, long, truncateResponse(ctx, long))
+	})
+
+	t.Run("tool results are truncated", func(t *testing.T) {
+		_, handler, err := ConvertTool("test_tool", "A test tool", structToolHandler)
+		require.NoError(t, err)
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"name": strings.Repeat("a", 100), "value": 1}
+
+		ctx := WithMaxResponseBytes(context.Background(), 20)
+		result, err := handler(ctx, request)
+		require.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, "...[truncated ")
+	})
+}
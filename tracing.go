@@ -0,0 +1,84 @@
+package mcpgrafana
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	tracerName = "github.com/grafana/mcp-grafana"
+
+	otlpEndpointEnvVar = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+// InitTracing configures OpenTelemetry tracing if the
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variable is set, exporting spans
+// using OTLP over HTTP. The other standard OTEL_* environment variables are
+// also respected.
+//
+// If the endpoint isn't set tracing is left as a no-op. The returned function
+// flushes any pending spans and should be called before exiting.
+func InitTracing(ctx context.Context) (func(context.Context) error, error) {
+	if os.Getenv(otlpEndpointEnvVar) == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName("mcp-grafana")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	slog.Info("Tracing enabled", "endpoint", os.Getenv(otlpEndpointEnvVar))
+	return tp.Shutdown, nil
+}
+
+// tracedToolHandler wraps a tool handler so that each call creates a span
+// named after the tool. Errors returned by the handler, and results marked
+// as errors, set the span's status to error.
+func tracedToolHandler(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := otel.Tracer(tracerName).Start(ctx, name)
+		defer span.End()
+		span.SetAttributes(attribute.String("mcp.tool.name", name))
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			span.SetAttributes(attribute.String("mcp.request_id", requestID))
+		}
+
+		result, err := handler(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if result != nil && result.IsError {
+			span.SetStatus(codes.Error, "tool returned an error result")
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,61 @@
+//go:build unit
+// +build unit
+
+package mcpgrafana
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedToolHandler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, handler, err := ConvertTool("test_tool", "A test tool", testToolHandler)
+	require.NoError(t, err)
+
+	call := func(name string) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "test_tool"
+		request.Params.Arguments = map[string]any{"name": name, "value": 1}
+		_, _ = handler(context.Background(), request)
+	}
+
+	t.Run("successful call", func(t *testing.T) {
+		call("ok")
+		spans := recorder.Ended()
+		require.NotEmpty(t, spans)
+		span := spans[len(spans)-1]
+		assert.Equal(t, "test_tool", span.Name())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("failed call", func(t *testing.T) {
+		call("error")
+		spans := recorder.Ended()
+		require.NotEmpty(t, spans)
+		span := spans[len(spans)-1]
+		assert.Equal(t, "test_tool", span.Name())
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, "test error", span.Status().Description)
+	})
+}
+
+func TestInitTracingDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	shutdown, err := InitTracing(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}